@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -9,7 +11,10 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/makt28/wink/internal/backup"
+	"github.com/makt28/wink/internal/buildinfo"
 	"github.com/makt28/wink/internal/config"
+	"github.com/makt28/wink/internal/logging"
 	"github.com/makt28/wink/internal/monitor"
 	"github.com/makt28/wink/internal/notify"
 	"github.com/makt28/wink/internal/storage"
@@ -17,6 +22,17 @@ import (
 )
 
 func main() {
+	restore := flag.Int("restore", 0, "restore config.json, history.json and incidents.json from backup N (1 = newest) and exit")
+	validate := flag.String("validate", "", "check the config file at `path` (version, parsing and validation), print any errors and exit")
+	flag.Parse()
+
+	if *validate != "" {
+		os.Exit(validateConfig(*validate))
+	}
+	if *restore > 0 {
+		os.Exit(restoreBackups(*restore))
+	}
+
 	// --- 1. Load Config ---
 	storage.MigrateConfigFile("config.json")
 
@@ -28,8 +44,38 @@ func main() {
 	cfg := cfgMgr.Get()
 
 	// --- 2. Setup Logger ---
-	setupLogger(cfg.System.LogLevel)
-	slog.Info("starting Wink", "bind", cfg.System.BindAddress)
+	var logger logging.Logger
+	if err := logger.Apply(cfg.System.LogLevel, cfg.System.LogFormat, cfg.System.LogFile); err != nil {
+		slog.Error("failed to set up logger, logging to stderr", "error", err)
+		logger.Apply(cfg.System.LogLevel, cfg.System.LogFormat, "")
+	}
+	defer logger.Close()
+	slog.Info("starting Wink", "version", buildinfo.Version, "commit", buildinfo.Commit, "build_date", buildinfo.Date, "bind", cfg.System.BindAddress)
+
+	// Every probe would fail from an address this host doesn't own.
+	if ip := cfg.System.ProbeSourceIP; ip != "" {
+		if err := monitor.CheckSourceIP(ip); err != nil {
+			slog.Error("probe_source_ip is not bindable", "address", ip, "error", err)
+			os.Exit(1)
+		}
+	}
+	// A resolver that doesn't answer fails every probe by host name, but it may
+	// only be reachable later (e.g. a VPN still coming up), so don't refuse to start.
+	if addr := cfg.System.DNSResolver; addr != "" {
+		if err := monitor.CheckDNSResolver(addr); err != nil {
+			slog.Warn("dns_resolver did not answer a test query, probes by host name may fail",
+				"address", addr, "error", err)
+		}
+	}
+	for _, m := range cfg.Monitors {
+		if m.SourceIP == "" {
+			continue
+		}
+		if err := monitor.CheckSourceIP(m.SourceIP); err != nil {
+			slog.Warn("monitor source_ip is not bindable, its probes will fail",
+				"monitor_id", m.ID, "address", m.SourceIP, "error", err)
+		}
+	}
 
 	// --- 3. Load History ---
 	storage.MigrateHistoryFile("history.json")
@@ -39,6 +85,9 @@ func main() {
 		slog.Error("failed to load history", "error", err)
 		os.Exit(1)
 	}
+	histMgr.SetBackupCount(cfg.System.BackupCount)
+	histMgr.SetRetention(cfg.System.MaxHistoryPoints, cfg.System.HistoryRetention())
+	histMgr.SetIncidentMaxOpen(cfg.System.IncidentMaxOpen())
 
 	// --- 4. Init Notification Router ---
 	notifier := notify.NewRouter(cfgMgr)
@@ -53,7 +102,7 @@ func main() {
 	go periodicDump(histMgr, time.Duration(cfg.System.DumpInterval)*time.Second, stopCh)
 
 	// --- 7. HTTP Server ---
-	router := web.NewRouter(cfgMgr, histMgr, stopCh)
+	router := web.NewRouter(cfgMgr, histMgr, scheduler, stopCh)
 	currentAddr := cfg.System.BindAddress
 	srv := &http.Server{
 		Addr:    currentAddr,
@@ -68,7 +117,7 @@ func main() {
 		}
 	}()
 
-	// --- 8. Watch for bind address changes ---
+	// --- 8. Watch for bind address and logging changes ---
 	bindChange := cfgMgr.Subscribe()
 	go func() {
 		for {
@@ -77,6 +126,12 @@ func main() {
 				return
 			case <-bindChange:
 				newCfg := cfgMgr.Get()
+				histMgr.SetBackupCount(newCfg.System.BackupCount)
+				histMgr.SetRetention(newCfg.System.MaxHistoryPoints, newCfg.System.HistoryRetention())
+				histMgr.SetIncidentMaxOpen(newCfg.System.IncidentMaxOpen())
+				if err := logger.Apply(newCfg.System.LogLevel, newCfg.System.LogFormat, newCfg.System.LogFile); err != nil {
+					slog.Error("failed to apply logging settings", "error", err)
+				}
 				if newCfg.System.BindAddress != currentAddr {
 					slog.Info("bind address changed, restarting listener",
 						"old", currentAddr, "new", newCfg.System.BindAddress)
@@ -105,37 +160,114 @@ func main() {
 	sig := <-quit
 	slog.Info("received shutdown signal", "signal", sig)
 
+	// The whole sequence must fit in the grace period, or the supervisor kills
+	// the process mid-dump. Open requests get at most half of it; the HTTP
+	// server and the scheduler stop first so, normally, nothing is recorded
+	// during the dump.
+	grace := cfgMgr.Get().System.ShutdownTimeout()
+	deadline := time.Now().Add(grace)
+
 	close(stopCh)
-	scheduler.Stop()
+	srvCtx, srvCancel := context.WithTimeout(context.Background(), grace/2)
+	if err := srv.Shutdown(srvCtx); err != nil {
+		slog.Error("server forced shutdown", "error", err)
+		srv.Close()
+	}
+	srvCancel()
 
-	if err := histMgr.Dump(); err != nil {
-		slog.Error("failed to dump history on shutdown", "error", err)
+	// Probes still running and their notifications may outlast the grace
+	// period; the scheduler gets half of what is left so the dump always has
+	// time. Probes it didn't wait for may still record during the dump.
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), time.Until(deadline)/2)
+	if err := stopWithin(stopCtx, scheduler); err != nil {
+		slog.Error("scheduler did not stop in time, dumping history anyway", "error", err)
 	}
+	stopCancel()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	if err := srv.Shutdown(ctx); err != nil {
-		slog.Error("server forced shutdown", "error", err)
+	dumpCtx, dumpCancel := context.WithDeadline(context.Background(), deadline)
+	defer dumpCancel()
+	if err := dumpWithin(dumpCtx, histMgr); err == context.DeadlineExceeded {
+		slog.Error("history dump on shutdown timed out, exiting without it", "shutdown_timeout", grace)
+		return
+	} else if err != nil {
+		slog.Error("failed to dump history on shutdown", "error", err)
+		return
 	}
 
 	slog.Info("Wink stopped gracefully")
 }
 
-func setupLogger(level string) {
-	var logLevel slog.Level
-	switch level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
-	}
-
-	handler := slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})
-	slog.SetDefault(slog.New(handler))
+// stopWithin stops the scheduler and returns ctx.Err() if ctx ends first. The
+// scheduler keeps stopping in the background.
+func stopWithin(ctx context.Context, scheduler *monitor.Scheduler) error {
+	done := make(chan struct{})
+	go func() {
+		scheduler.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dumpWithin runs the final history dump and returns ctx.Err() if ctx ends
+// first. A dump cut short leaves the previous files intact thanks to the atomic
+// rename, but the state since the last periodic dump is lost.
+func dumpWithin(ctx context.Context, histMgr *storage.HistoryManager) error {
+	done := make(chan error, 1)
+	go func() { done <- histMgr.Dump() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// validateConfig checks a config file the way startup loads it, without
+// touching it or starting anything. Errors go to stderr, one per line; the exit
+// status is non-zero if the file would not load.
+func validateConfig(path string) int {
+	if _, err := storage.CheckConfigFile(path); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+	for _, w := range cfg.Warnings() {
+		fmt.Fprintf(os.Stderr, "%s: warning: %s\n", path, w)
+	}
+	fmt.Printf("%s: OK (%d monitors, %d notifiers)\n", path, len(cfg.Monitors), len(cfg.Notifiers))
+	return 0
+}
+
+// restoreBackups rolls the data files back to backup n. The config backup is
+// validated first; history files without a matching backup are left untouched.
+func restoreBackups(n int) int {
+	if err := config.RestoreBackup("config.json", n); err != nil {
+		slog.Error("failed to restore config", "backup", n, "error", err)
+		return 1
+	}
+	slog.Info("restored config", "from", backup.Name("config.json", n))
+
+	for _, p := range []string{"history.json", "incidents.json"} {
+		if err := backup.Restore(p, n); err != nil {
+			if os.IsNotExist(err) {
+				slog.Warn("no backup to restore", "path", backup.Name(p, n))
+				continue
+			}
+			slog.Error("failed to restore backup", "path", p, "error", err)
+			return 1
+		}
+		slog.Info("restored history file", "from", backup.Name(p, n))
+	}
+	return 0
 }
 
 func periodicDump(histMgr *storage.HistoryManager, interval time.Duration, stopCh <-chan struct{}) {
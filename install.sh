@@ -279,7 +279,7 @@ do_install() {
     info "Wink ${version} installed successfully!"
     echo ""
     echo "  Web UI:    http://$(hostname -I 2>/dev/null | awk '{print $1}' || echo 'localhost'):8080"
-    echo "  Login:     admin / 123456"
+    echo "  Login:     admin / 123456 (a new password is required on first login)"
     echo "  Data dir:  ${INSTALL_DIR}"
     echo ""
     echo "  Management commands:"
@@ -0,0 +1,76 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Name returns the path of the n-th backup of path (1 = most recent).
+func Name(path string, n int) string {
+	return fmt.Sprintf("%s.bak.%d", path, n)
+}
+
+// Rotate copies the current contents of path to path.bak.1, shifting older
+// backups up by one and dropping anything beyond keep. A missing source file
+// or keep <= 0 is a no-op.
+func Rotate(path string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(Name(path, keep)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := keep - 1; i >= 1; i-- {
+		if err := os.Rename(Name(path, i), Name(path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return writeFile(Name(path, 1), data)
+}
+
+// Restore replaces path with the contents of its n-th backup.
+func Restore(path string, n int) error {
+	data, err := os.ReadFile(Name(path, n))
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data)
+}
+
+// writeFile writes data to a temp file next to path and renames it into place.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp.*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	defer func() {
+		if tmp != nil {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	tmp = nil
+
+	return os.Rename(tmpName, path)
+}
@@ -0,0 +1,72 @@
+package buildinfo
+
+import "runtime/debug"
+
+// Build metadata, overridable at link time:
+//
+//	go build -ldflags "-X github.com/makt28/wink/internal/buildinfo.Version=0.1.5
+//	  -X github.com/makt28/wink/internal/buildinfo.Commit=abc1234
+//	  -X github.com/makt28/wink/internal/buildinfo.Date=2024-01-02T15:04:05Z"
+//
+// Without ldflags, Version is the last release and Commit/Date come from the
+// VCS stamp Go embeds when building inside a git checkout, if any.
+var (
+	Version = "0.1.4"
+	Commit  = ""
+	Date    = ""
+)
+
+// UserAgent is the default User-Agent sent by HTTP probes.
+var UserAgent string
+
+func init() {
+	if Commit == "" || Date == "" {
+		fillFromVCS()
+	}
+	UserAgent = "Wink/" + Version
+}
+
+// fillFromVCS fills Commit and Date from the embedded VCS settings.
+func fillFromVCS() {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	var revision, modified, vcsTime string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		case "vcs.time":
+			vcsTime = s.Value
+		}
+	}
+	if Commit == "" && revision != "" {
+		if len(revision) > 7 {
+			revision = revision[:7]
+		}
+		if modified == "true" {
+			revision += "-dirty"
+		}
+		Commit = revision
+	}
+	if Date == "" {
+		Date = vcsTime
+	}
+}
+
+// Summary describes the build for logs and tooltips, e.g. "0.1.4 (abc1234, 2024-01-02T15:04:05Z)".
+func Summary() string {
+	s := Version
+	switch {
+	case Commit != "" && Date != "":
+		s += " (" + Commit + ", " + Date + ")"
+	case Commit != "":
+		s += " (" + Commit + ")"
+	case Date != "":
+		s += " (" + Date + ")"
+	}
+	return s
+}
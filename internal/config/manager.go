@@ -2,18 +2,38 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
+
+	"github.com/makt28/wink/internal/backup"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Environment variables that override the config file at startup, for
+// deployments that provision instances without editing JSON.
+// Precedence is env > file > default.
+const (
+	EnvBind          = "WINK_BIND"
+	EnvLogLevel      = "WINK_LOG_LEVEL"
+	EnvAdminPassword = "WINK_ADMIN_PASSWORD"
 )
 
+// ErrUnchanged can be returned by an Update function to leave the config as
+// it is; Update then returns the current config and no error.
+var ErrUnchanged = errors.New("config unchanged")
+
 // Manager handles loading, saving and broadcasting config changes.
 type Manager struct {
 	mu       sync.RWMutex
-	cfg      Config
+	cfg      Config // file settings with env overrides applied
 	filePath string
+	env      []envOverride
+
+	saveMu sync.Mutex // serializes Save and Update
 
 	subMu sync.Mutex
 	subs  []chan struct{}
@@ -21,6 +41,7 @@ type Manager struct {
 
 // NewManager creates a Manager and loads config from the given file path.
 // If the file does not exist, a default config is used (but not persisted).
+// Environment overrides (see EnvBind) are applied on top of either.
 func NewManager(filePath string) (*Manager, error) {
 	m := &Manager{
 		filePath: filePath,
@@ -29,15 +50,95 @@ func NewManager(filePath string) (*Manager, error) {
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		slog.Warn("config file not found, using defaults", "path", filePath)
 		m.cfg = DefaultConfig()
-		return m, nil
+	} else if err := m.load(); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
 	}
 
-	if err := m.load(); err != nil {
-		return nil, fmt.Errorf("load config: %w", err)
+	env, err := applyEnv(&m.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("environment overrides: %w", err)
 	}
+	m.env = env
 	return m, nil
 }
 
+// envOverride is a setting overridden from the environment. It is applied in
+// memory only: saves write the config file's own value back, so the
+// environment never ends up in config.json and unsetting the variable restores
+// the file's value (env > file > default).
+type envOverride struct {
+	field func(*Config) *string
+	value string // from the environment; a bcrypt hash for the password
+	file  string // the config file's value
+}
+
+// envOverrides lists the settings the environment may override.
+var envOverrides = []struct {
+	name  string
+	field func(*Config) *string
+}{
+	{EnvBind, func(c *Config) *string { return &c.System.BindAddress }},
+	{EnvLogLevel, func(c *Config) *string { return &c.System.LogLevel }},
+	{EnvAdminPassword, func(c *Config) *string { return &c.Auth.PasswordHash }},
+}
+
+// applyEnv overrides bootstrap settings from the environment and returns the
+// overrides applied. WINK_ADMIN_PASSWORD must meet the same policy as a
+// password set in the UI. A password changed in the UI takes effect and is
+// saved, but is replaced again on restart while the variable is set.
+func applyEnv(cfg *Config) ([]envOverride, error) {
+	var applied []envOverride
+	var names []string
+	for _, e := range envOverrides {
+		v := os.Getenv(e.name)
+		if v == "" {
+			continue
+		}
+		field := e.field(cfg)
+		if e.name == EnvAdminPassword {
+			if err := CheckPassword(v, cfg.System.MinPasswordLength); err != nil {
+				if errors.Is(err, ErrPasswordTooShort) {
+					return nil, fmt.Errorf("%s must be at least %d characters", e.name, cfg.System.MinPasswordLength)
+				}
+				return nil, fmt.Errorf("%s: %w", e.name, err)
+			}
+			// Keep an existing matching hash so saves don't rewrite it.
+			if bcrypt.CompareHashAndPassword([]byte(*field), []byte(v)) == nil {
+				v = *field
+			} else {
+				hash, err := bcrypt.GenerateFromPassword([]byte(v), bcrypt.DefaultCost)
+				if err != nil {
+					return nil, fmt.Errorf("hash %s: %w", e.name, err)
+				}
+				v = string(hash)
+			}
+		}
+		applied = append(applied, envOverride{field: e.field, value: v, file: *field})
+		*field = v
+		names = append(names, e.name)
+	}
+	if len(applied) == 0 {
+		return nil, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	slog.Info("config overridden from environment", "vars", names)
+	return applied, nil
+}
+
+// fileConfig returns cfg as it is written to the config file: settings still
+// at their environment value get the file's value back. A setting changed
+// since is written as changed.
+func (m *Manager) fileConfig(cfg Config) Config {
+	for _, o := range m.env {
+		if field := o.field(&cfg); *field == o.value {
+			*field = o.file
+		}
+	}
+	return cfg
+}
+
 // Get returns a copy of the current config (safe for concurrent reads).
 func (m *Manager) Get() Config {
 	m.mu.RLock()
@@ -46,19 +147,59 @@ func (m *Manager) Get() Config {
 }
 
 // Save validates, atomically writes config to disk, and broadcasts a change event.
+// It replaces the whole config; to change part of it, use Update so concurrent
+// changes made from older copies aren't lost.
 func (m *Manager) Save(cfg Config) error {
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+	return m.save(cfg)
+}
+
+// Update applies fn to a deep copy of the current config and saves the result,
+// blocking other saves meanwhile, so concurrent changes are applied one after
+// the other instead of the last one overwriting the rest. If fn returns an
+// error nothing is saved and the error is returned unchanged. On success the
+// saved config is returned.
+func (m *Manager) Update(fn func(cfg *Config) error) (Config, error) {
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+
+	cfg, err := m.Get().clone()
+	if err != nil {
+		return Config{}, fmt.Errorf("copy config: %w", err)
+	}
+	if err := fn(&cfg); errors.Is(err, ErrUnchanged) {
+		return m.Get(), nil
+	} else if err != nil {
+		return Config{}, err
+	}
+	if err := m.save(cfg); err != nil {
+		return Config{}, err
+	}
+	return m.Get(), nil
+}
+
+func (m *Manager) save(cfg Config) error {
 	cfg.Version = CurrentConfigVersion
 	cfg.ApplyDefaults()
 	if err := cfg.Validate(); err != nil {
 		return err
 	}
+	logWarnings(cfg)
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if err := m.atomicWrite(cfg); err != nil {
+	if err := backup.Rotate(m.filePath, cfg.System.BackupCount); err != nil {
+		slog.Warn("config backup failed", "path", m.filePath, "error", err)
+	}
+	file := m.fileConfig(cfg)
+	if err := m.atomicWrite(file); err != nil {
 		return fmt.Errorf("atomic write config: %w", err)
 	}
+	for i := range m.env {
+		m.env[i].file = *m.env[i].field(&file)
+	}
 	m.cfg = cfg
 
 	// Broadcast to all subscribers
@@ -86,23 +227,71 @@ func (m *Manager) Subscribe() <-chan struct{} {
 }
 
 func (m *Manager) load() error {
-	data, err := os.ReadFile(m.filePath)
+	cfg, err := LoadFile(m.filePath)
 	if err != nil {
 		return err
 	}
+	logWarnings(cfg)
+
+	m.cfg = cfg
+	return nil
+}
+
+// LoadFile parses a config file, applies defaults and validates it, the way a
+// Manager loads it but without environment overrides. It is also what
+// `wink -validate` runs.
+func LoadFile(filePath string) (Config, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return Config{}, err
+	}
 
 	var cfg Config
 	if err := json.Unmarshal(data, &cfg); err != nil {
-		return fmt.Errorf("parse config JSON: %w", err)
+		return Config{}, fmt.Errorf("parse config JSON: %w", err)
 	}
 
 	cfg.ApplyDefaults()
 	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// RestoreBackup validates the n-th backup of the config file and copies it over
+// the live file. It is meant to be run before a Manager is created.
+func RestoreBackup(filePath string, n int) error {
+	data, err := os.ReadFile(backup.Name(filePath, n))
+	if err != nil {
 		return err
 	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("parse backup JSON: %w", err)
+	}
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("backup is invalid: %w", err)
+	}
+	return backup.Restore(filePath, n)
+}
 
-	m.cfg = cfg
-	return nil
+// clone returns a copy of c sharing no maps or slices with it, so changes to
+// the copy can't leak into the live config before it is validated.
+func (c Config) clone() (Config, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return Config{}, err
+	}
+	var cp Config
+	err = json.Unmarshal(data, &cp)
+	return cp, err
+}
+
+func logWarnings(cfg Config) {
+	for _, w := range cfg.Warnings() {
+		slog.Warn("config warning", "detail", w)
+	}
 }
 
 func (m *Manager) atomicWrite(cfg Config) error {
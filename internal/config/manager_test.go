@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvOverridesAreNotSaved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	m, err := NewManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Save(m.Get()); err != nil {
+		t.Fatal(err)
+	}
+	fileBind := m.Get().System.BindAddress
+
+	t.Setenv(EnvBind, "127.0.0.1:9999")
+	t.Setenv(EnvLogLevel, "debug")
+	m, err = NewManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Get().System.BindAddress; got != "127.0.0.1:9999" {
+		t.Fatalf("bind = %q, want the environment value", got)
+	}
+
+	// A save of unrelated changes keeps the file's own values.
+	if _, err := m.Update(func(c *Config) error {
+		c.System.Region = "eu-west"
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.System.BindAddress != fileBind || saved.System.LogLevel == "debug" {
+		t.Errorf("saved bind %q, log level %q: environment leaked into the file", saved.System.BindAddress, saved.System.LogLevel)
+	}
+	if saved.System.Region != "eu-west" {
+		t.Errorf("saved region = %q, want the change", saved.System.Region)
+	}
+	if got := m.Get().System.BindAddress; got != "127.0.0.1:9999" {
+		t.Errorf("bind after save = %q, want the environment value", got)
+	}
+
+	// A setting changed away from the environment value is saved.
+	if _, err := m.Update(func(c *Config) error {
+		c.System.LogLevel = "warn"
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if saved, _ := LoadFile(path); saved.System.LogLevel != "warn" {
+		t.Errorf("saved log level = %q, want warn", saved.System.LogLevel)
+	}
+}
+
+func TestEnvAdminPasswordPolicy(t *testing.T) {
+	dir := t.TempDir()
+	for _, pw := range []string{"short1", "onlyletters", "1234567890", "123456"} {
+		t.Setenv(EnvAdminPassword, pw)
+		if _, err := NewManager(filepath.Join(dir, "config.json")); err == nil {
+			t.Errorf("%s=%q accepted", EnvAdminPassword, pw)
+		}
+	}
+
+	t.Setenv(EnvAdminPassword, "correct-horse-1")
+	m, err := NewManager(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg := m.Get(); cfg.Auth.UsesDefaultPassword() {
+		t.Error("password from the environment not applied")
+	}
+	if err := m.Save(m.Get()); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "config.json"))
+	if !strings.Contains(string(data), DefaultPasswordHash) {
+		t.Error("hash of the environment password was written to the file")
+	}
+}
@@ -2,16 +2,53 @@ package config
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
+
+	"github.com/makt28/wink/internal/jsonpath"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const CurrentConfigVersion = 1
 
+// MaxHeartbeatPoints caps how many heartbeats the API returns per monitor.
+const MaxHeartbeatPoints = 200
+
+// MaxResponseBytes bounds min_bytes and max_bytes, and so how much of a
+// response body a size assertion may read.
+const MaxResponseBytes = 10 << 20
+
+// MaxFailureWindow bounds window_size: the probes it covers are read from the
+// retained history on every failure.
+const MaxFailureWindow = 100
+
+// MaxHistoryRetentionHours bounds system.history_retention_hours at the 30-day
+// window, the longest the uptime figures and charts look back.
+const MaxHistoryRetentionHours = 30 * 24
+
+// MaxPingCount bounds ping_count; every packet after the first adds to the
+// check's duration, which must stay within the monitor's timeout.
+const MaxPingCount = 10
+
+// MaxRetryAfter caps the maintenance window a Retry-After header can start
+// (honor_retry_after), so a bogus date can't silence a monitor for good.
+const MaxRetryAfter = 24 * time.Hour
+
+// maxRegionLength bounds system.region, which is repeated in every alert.
+const maxRegionLength = 64
+
 // Config is the root configuration structure persisted in config.json.
 type Config struct {
 	Version       int                     `json:"version"`
@@ -19,6 +56,7 @@ type Config struct {
 	Auth          AuthConfig              `json:"auth"`
 	ContactGroups map[string]ContactGroup `json:"contact_groups"`
 	GroupOrder    []string                `json:"group_order,omitempty"`
+	MonitorOrder  []string                `json:"monitor_order,omitempty"`
 	Notifiers     []NotifierConfig        `json:"notifiers"`
 	Monitors      []Monitor               `json:"monitors"`
 }
@@ -30,8 +68,99 @@ type SystemConfig struct {
 	DumpInterval     int    `json:"dump_interval"`
 	SessionTTL       int    `json:"session_ttl"`
 	LogLevel         string `json:"log_level"`
+	LogFormat        string `json:"log_format"`         // "json" or "text"
+	LogFile          string `json:"log_file,omitempty"` // append logs here instead of stderr
 	MaxMonitors      int    `json:"max_monitors"`
 	Timezone         string `json:"timezone,omitempty"`
+	Region           string `json:"region,omitempty"`          // label of this instance (e.g. "eu-west"), attached to alerts and API output
+	ProbeSourceIP    string `json:"probe_source_ip,omitempty"` // local address probes connect from (empty = OS default)
+	DNSResolver      string `json:"dns_resolver,omitempty"`    // "ip:port" of the DNS server probes resolve through (empty = host resolver)
+	CABundlePath     string `json:"ca_bundle_path,omitempty"`  // PEM file of extra CAs TLS probes trust besides the system store
+
+	BasePath       string `json:"base_path,omitempty"`       // URL prefix when served from a proxy subpath, e.g. "/wink"; applied on restart
+	CookieSameSite string `json:"cookie_samesite,omitempty"` // SameSite of the session cookie: "strict" (default), "lax" or "none"
+	CookieSecure   bool   `json:"cookie_secure,omitempty"`   // mark cookies Secure (HTTPS only); required with "none"
+	CookieDomain   string `json:"cookie_domain,omitempty"`   // Domain attribute of the cookies (empty = host only)
+
+	MinPasswordLength int `json:"min_password_length"`
+	BackupCount       int `json:"backup_count"`        // rotated .bak.N copies kept per data file; negative disables
+	DefaultTimeout    int `json:"default_timeout"`     // probe timeout for new monitors when none is given
+	MaxTimeout        int `json:"max_timeout"`         // upper bound for any monitor's timeout
+	MinInterval       int `json:"min_interval"`        // floor for monitor interval and retry_interval
+	InitialBackoffMax int `json:"initial_backoff_max"` // cap in seconds for the failure backoff of never-successful monitors (0 = off)
+
+	RestartStalledMonitors bool `json:"restart_stalled_monitors,omitempty"` // restart a monitor whose probes stopped landing instead of only reporting it
+
+	ProbeCoalesceWindow int `json:"probe_coalesce_window,omitempty"` // seconds a probe result is shared by monitors with identical probe settings (0 = off)
+
+	HistoryRetentionHours int `json:"history_retention_hours,omitempty"` // keep probe points this many hours instead of max_history_points (0 = point-based)
+	IncidentMaxOpenHours  int `json:"incident_max_open_hours,omitempty"` // close incidents still open after this many hours (0 = never)
+
+	ReasonRules []ReasonRule `json:"reason_rules,omitempty"` // custom incident categories, tried before the built-in ones
+
+	NotifyTimeoutSeconds   int    `json:"notify_timeout"`              // per-send deadline for notifications, including test sends
+	ShutdownTimeoutSeconds int    `json:"shutdown_timeout"`            // grace period for stopping on SIGTERM, including the final history dump
+	TelegramTemplate       string `json:"telegram_template,omitempty"` // default Go text/template for Telegram messages (empty = built-in format)
+
+	DefaultHeartbeatPoints  int `json:"default_heartbeat_points"` // heartbeats returned by the API when ?points is absent
+	DashboardRefreshSeconds int `json:"dashboard_refresh"`        // dashboard polling interval
+
+	MetricsBuckets []float64 `json:"metrics_buckets,omitempty"` // upper bounds in seconds of the /metrics latency histogram (empty = DefaultMetricsBuckets)
+
+	ProbeEvents     bool   `json:"probe_events,omitempty"`      // publish every probe result of every monitor, see Monitor.ProbeEvents
+	ProbeWebhookURL string `json:"probe_webhook_url,omitempty"` // POST published probe results here in batches (empty = off)
+
+	MonitoringEnabled *bool `json:"monitoring_enabled,omitempty"` // global kill switch for all probing (nil = on)
+}
+
+// DefaultMetricsBuckets are the latency histogram buckets of /metrics when
+// system.metrics_buckets is not set, in seconds.
+var DefaultMetricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// maxMetricsBuckets bounds system.metrics_buckets; every bucket is a series
+// per monitor.
+const maxMetricsBuckets = 30
+
+// LatencyBuckets returns the upper bounds of the /metrics latency histogram.
+func (s SystemConfig) LatencyBuckets() []float64 {
+	if len(s.MetricsBuckets) == 0 {
+		return DefaultMetricsBuckets
+	}
+	return s.MetricsBuckets
+}
+
+// ReasonRule assigns Category to incidents whose probe error matches the
+// regular expression Match.
+type ReasonRule struct {
+	Match    string `json:"match"`
+	Category string `json:"category"`
+}
+
+// NotifyTimeout returns the per-send notification deadline.
+func (s SystemConfig) NotifyTimeout() time.Duration {
+	return time.Duration(s.NotifyTimeoutSeconds) * time.Second
+}
+
+// ShutdownTimeout returns the grace period for a graceful shutdown.
+func (s SystemConfig) ShutdownTimeout() time.Duration {
+	return time.Duration(s.ShutdownTimeoutSeconds) * time.Second
+}
+
+// HistoryRetention returns the age limit of probe history, or 0 when history
+// is limited by max_history_points instead.
+func (s SystemConfig) HistoryRetention() time.Duration {
+	return time.Duration(s.HistoryRetentionHours) * time.Hour
+}
+
+// IncidentMaxOpen returns how long an incident may stay open before it is
+// auto-closed, or 0 if never.
+func (s SystemConfig) IncidentMaxOpen() time.Duration {
+	return time.Duration(s.IncidentMaxOpenHours) * time.Hour
+}
+
+// IsMonitoringEnabled returns whether probing is globally enabled (defaults to true).
+func (s SystemConfig) IsMonitoringEnabled() bool {
+	return s.MonitoringEnabled == nil || *s.MonitoringEnabled
 }
 
 type AuthConfig struct {
@@ -40,42 +169,280 @@ type AuthConfig struct {
 	MaxLoginAttempts int       `json:"max_login_attempts"`
 	LockoutDuration  int       `json:"lockout_duration"`
 	SSO              SSOConfig `json:"sso"`
+
+	ShareLinks []ShareLink `json:"share_links,omitempty"` // read-only dashboard links
+
+	MetricsToken string `json:"metrics_token,omitempty"` // bearer token for /metrics; empty disables the endpoint
 }
 
 type SSOConfig struct {
 	Enabled bool `json:"enabled"`
 }
 
+// ShareLink grants read-only access to the dashboard to whoever holds its
+// token. Only the token's hash is stored; deleting the link revokes it.
+type ShareLink struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	TokenHash string `json:"token_hash"` // hex SHA-256 of the token
+	CreatedAt int64  `json:"created_at"`
+	ExpiresAt int64  `json:"expires_at,omitempty"` // Unix time; 0 = never
+}
+
+// Expired reports whether the link has expired at now.
+func (l *ShareLink) Expired(now int64) bool {
+	return l.ExpiresAt > 0 && now >= l.ExpiresAt
+}
+
+// HashShareToken returns the form in which a share link token is stored.
+func HashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ActiveShareLink returns the share link for token, or nil if there is none
+// or it has expired at now.
+func (a *AuthConfig) ActiveShareLink(token string, now int64) *ShareLink {
+	hash := HashShareToken(token)
+	for i := range a.ShareLinks {
+		l := &a.ShareLinks[i]
+		if subtle.ConstantTimeCompare([]byte(l.TokenHash), []byte(hash)) == 1 && !l.Expired(now) {
+			return l
+		}
+	}
+	return nil
+}
+
+// DefaultPasswordHash is the bcrypt hash of the shipped admin password, "123456".
+const DefaultPasswordHash = "$2a$10$8.FeSs3eopZT0s/fCTdMWuE8U4f/Dv.ERy10fqrb9QnpHNknp8i/q"
+
+// Password policy violations returned by CheckPassword.
+var (
+	ErrPasswordTooShort  = errors.New("password is too short")
+	ErrPasswordTooSimple = errors.New("password must mix letters with digits or symbols")
+	ErrPasswordDefault   = errors.New("password is the shipped default")
+)
+
+// CheckPassword enforces the admin password policy: at least minLen
+// characters, letters mixed with digits or symbols, and not the shipped
+// default password.
+func CheckPassword(password string, minLen int) error {
+	if len([]rune(password)) < minLen {
+		return ErrPasswordTooShort
+	}
+	var hasLetter, hasOther bool
+	for _, c := range password {
+		if unicode.IsLetter(c) {
+			hasLetter = true
+		} else {
+			hasOther = true
+		}
+	}
+	if !hasLetter || !hasOther {
+		return ErrPasswordTooSimple
+	}
+	if bcrypt.CompareHashAndPassword([]byte(DefaultPasswordHash), []byte(password)) == nil {
+		return ErrPasswordDefault
+	}
+	return nil
+}
+
+// UsesDefaultPassword reports whether the admin password is still the shipped
+// default, in which case the web UI allows nothing but changing it.
+func (a *AuthConfig) UsesDefaultPassword() bool {
+	return a.PasswordHash == DefaultPasswordHash
+}
+
 type ContactGroup struct {
 	ID        string           `json:"id"`
 	Name      string           `json:"name"`
+	Muted     bool             `json:"muted,omitempty"`     // suppress notifications for the group's monitors; probes and incidents are still recorded
 	Notifiers []NotifierConfig `json:"notifiers,omitempty"` // deprecated: migrated to top-level Notifiers
 }
 
 type NotifierConfig struct {
-	ID       string `json:"id"`
-	Type     string `json:"type"`
-	Remark   string `json:"remark,omitempty"`
-	BotToken string `json:"bot_token,omitempty"`
-	ChatID   string `json:"chat_id,omitempty"`
-	URL      string `json:"url,omitempty"`
-	Method   string `json:"method,omitempty"`
+	ID       string   `json:"id"`
+	Type     string   `json:"type"`
+	Remark   string   `json:"remark,omitempty"`
+	BotToken string   `json:"bot_token,omitempty"`
+	ChatID   string   `json:"chat_id,omitempty"`
+	URL      string   `json:"url,omitempty"` // webhook URL(s), comma- or newline-separated; Bark server (empty = public server); Google Chat or Mattermost incoming webhook; Apprise API server
+	Method   string   `json:"method,omitempty"`
+	Delivery string   `json:"delivery,omitempty"` // webhook with several URLs: "any" (default) or "all" must succeed
+	Encoding string   `json:"encoding,omitempty"` // webhook field placement: "json" body (default), "form" body or "query" string
+	Events   []string `json:"events,omitempty"`   // event types to deliver ("down", "up", "anomaly", "slow"); empty means all but "slow"
+
+	DeviceKey string `json:"device_key,omitempty"` // bark
+	Token     string `json:"token,omitempty"`      // pushover application token
+	UserKey   string `json:"user_key,omitempty"`   // pushover user or group key
+	Priority  *int   `json:"priority,omitempty"`   // pushover priority for down alerts, -2..1 (nil = 1, high)
+	Sound     string `json:"sound,omitempty"`      // bark, pushover
+	APIKey    string `json:"api_key,omitempty"`    // opsgenie API integration key
+	Region    string `json:"region,omitempty"`     // opsgenie account region: "us" (default) or "eu"
+
+	AccountSID string `json:"account_sid,omitempty"` // twilio
+	AuthToken  string `json:"auth_token,omitempty"`  // twilio
+	From       string `json:"from,omitempty"`        // twilio sender: E.164 number or alphanumeric sender ID
+	To         string `json:"to,omitempty"`          // twilio recipient numbers, comma- or newline-separated
+
+	ConfigKey   string `json:"config_key,omitempty"`   // apprise: key of a configuration stored on the server
+	AppriseURLs string `json:"apprise_urls,omitempty"` // apprise: service URLs, comma- or newline-separated (instead of config_key)
+
+	MessageTemplate string `json:"message_template,omitempty"` // telegram: Go text/template for the message (empty = system.telegram_template)
+
+	// MinIntervalSeconds throttles the notifier: messages within this many
+	// seconds of the previous one are dropped, except initial down alerts (0 = off).
+	MinIntervalSeconds int `json:"min_interval_seconds,omitempty"`
+}
+
+// WebhookURLs splits URL into the individual webhook endpoints.
+func (n *NotifierConfig) WebhookURLs() []string {
+	return splitList(n.URL)
+}
+
+// Recipients splits To into the individual Twilio recipient numbers.
+func (n *NotifierConfig) Recipients() []string {
+	return splitList(n.To)
+}
+
+// splitList splits a comma- or newline-separated list, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// DownPriority returns the Pushover priority used for down alerts (default high).
+func (n *NotifierConfig) DownPriority() int {
+	if n.Priority == nil {
+		return 1
+	}
+	return *n.Priority
+}
+
+// validEventTypes lists the alert event types a notifier can filter on.
+var validEventTypes = map[string]bool{"down": true, "up": true, "anomaly": true, "slow": true}
+
+// DefaultEventTypes are the events a notifier without an events filter gets.
+// "slow" is opt-in: threshold alerts are only wanted on some channels.
+var DefaultEventTypes = []string{"down", "up", "anomaly"}
+
+// WantsEvent reports whether the notifier should receive events of the given
+// type. "fast" (back under the slow threshold) goes wherever "slow" does.
+func (n *NotifierConfig) WantsEvent(eventType string) bool {
+	if eventType == "fast" {
+		eventType = "slow"
+	}
+	if len(n.Events) == 0 {
+		return eventType != "slow"
+	}
+	for _, e := range n.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
 }
 
 type Monitor struct {
-	ID               string   `json:"id"`
-	Name             string   `json:"name"`
-	Type             string   `json:"type"`
-	Target           string   `json:"target"`
-	GroupID          string   `json:"group_id"`
-	Interval         int      `json:"interval"`
-	Timeout          int      `json:"timeout"`
-	MaxRetries       int      `json:"max_retries"`
-	RetryInterval    int      `json:"retry_interval"`
-	ReminderInterval int      `json:"reminder_interval"`
-	IgnoreTLS        bool     `json:"ignore_tls"`
-	Enabled          *bool    `json:"enabled,omitempty"`
-	NotifierIDs      []string `json:"notifier_ids,omitempty"`
+	ID                string   `json:"id"`
+	Name              string   `json:"name"`
+	Type              string   `json:"type"`
+	Target            string   `json:"target"`
+	GroupID           string   `json:"group_id"`
+	Interval          int      `json:"interval"`
+	Timeout           int      `json:"timeout"`
+	MaxRetries        int      `json:"max_retries"`
+	RetryInterval     int      `json:"retry_interval"`
+	ReminderInterval  int      `json:"reminder_interval"`
+	RetryHold         int      `json:"retry_hold,omitempty"`         // successful probes kept at retry_interval after a failure (0 = none)
+	RecoveryThreshold int      `json:"recovery_threshold,omitempty"` // consecutive successes before a DOWN monitor is UP again (0 = 1)
+	IgnoreTLS         bool     `json:"ignore_tls"`
+	Public            bool     `json:"public,omitempty"`    // listed on the unauthenticated status page (name and status only)
+	SourceIP          string   `json:"source_ip,omitempty"` // overrides system.probe_source_ip
+	UserAgent         string   `json:"user_agent,omitempty"`
+	HostHeader        string   `json:"host_header,omitempty"`
+	Method            string   `json:"method,omitempty"`              // http: request method (empty = GET)
+	JSONPath          string   `json:"json_path,omitempty"`           // http: dot/bracket path into a JSON response body
+	JSONExpected      string   `json:"json_expected,omitempty"`       // http: value required at json_path
+	MinBytes          int      `json:"min_bytes,omitempty"`           // http: smallest acceptable response body (0 = no minimum)
+	MaxBytes          int      `json:"max_bytes,omitempty"`           // http: largest acceptable response body (0 = no maximum)
+	ExpectContentType string   `json:"expect_content_type,omitempty"` // http: required prefix of the response media type, e.g. "application/json"
+	Timezone          string   `json:"timezone,omitempty"`            // overrides system.timezone in notifications
+	SendData          string   `json:"send_data,omitempty"`           // tcp: payload written after connect (Go escapes allowed)
+	ExpectData        string   `json:"expect_data,omitempty"`         // tcp: substring (or regex) required in the response
+	ExpectRegex       bool     `json:"expect_regex,omitempty"`
+	SMTPStartTLS      bool     `json:"smtp_starttls,omitempty"`       // smtp: require a successful STARTTLS upgrade
+	WSPing            bool     `json:"ws_ping,omitempty"`             // ws: require a pong after the handshake
+	PingCount         int      `json:"ping_count,omitempty"`          // ping: echo requests per check (0 = 1)
+	PingLossThreshold int      `json:"ping_loss_threshold,omitempty"` // ping: packet loss % at which the check fails (0 = only when every packet is lost)
+	AnomalyK          float64  `json:"anomaly_k,omitempty"`           // alert when latency > mean + k·stddev of recent probes (0 = off)
+	AnomalyCount      int      `json:"anomaly_count,omitempty"`       // consecutive anomalous probes before alerting (0 = 3)
+	SlowThresholdMs   int      `json:"slow_threshold_ms,omitempty"`   // send a "slow" event when latency exceeds this (0 = off)
+	SlowCount         int      `json:"slow_count,omitempty"`          // consecutive probes over (or back under) the threshold before notifying (0 = 3)
+	Enabled           *bool    `json:"enabled,omitempty"`
+	NotifierIDs       []string `json:"notifier_ids,omitempty"`
+	CreatedAt         int64    `json:"created_at,omitempty"` // unix seconds; backfilled on load for older configs
+	UpdatedAt         int64    `json:"updated_at,omitempty"` // unix seconds of the last edit
+
+	// http: a response slower than slow_threshold_ms fails the check, feeding the
+	// usual retries and incidents, instead of sending "slow" events.
+	LatencyCountsAsDown bool `json:"latency_counts_as_down,omitempty"`
+	// http: a 503 with a Retry-After header starts a maintenance window until the
+	// time it names (capped at MaxRetryAfter), during which failures don't alert.
+	HonorRetryAfter bool `json:"honor_retry_after,omitempty"`
+	// Latency recorded in the history is clamped to this many ms so one probe near
+	// the timeout doesn't dwarf the rest; the measured value is kept as the point's
+	// "raw" (0 = the monitor's timeout, negative = record measured latency as is).
+	LatencyCapMs int `json:"latency_cap_ms,omitempty"`
+	// http: the oldest TLS version the server may negotiate, one of TLSVersions
+	// (empty = any). Not checked with ignore_tls.
+	MinTLSVersion string `json:"min_tls_version,omitempty"`
+	// http: the response must come over HTTP/2.
+	RequireHTTP2 bool `json:"require_http2,omitempty"`
+	// A DOWN monitor must keep succeeding for this many seconds, on top of
+	// recovery_threshold, before it is UP again and the recovery alert is sent.
+	RecoveryGraceSeconds int `json:"recovery_grace_seconds,omitempty"`
+	// FailureMode decides when the monitor goes DOWN: "consecutive" (default)
+	// after max_retries failures in a row, "window" once more than
+	// window_threshold_pct percent of the last window_size probes failed.
+	FailureMode        string `json:"failure_mode,omitempty"`
+	WindowSize         int    `json:"window_size,omitempty"`
+	WindowThresholdPct int    `json:"window_threshold_pct,omitempty"`
+	// Monthly uptime percentage the SLA report checks against (0 = none).
+	SLATarget float64 `json:"sla_target,omitempty"`
+	// Record a DNS/connect/TLS/time-to-first-byte breakdown of each HTTP probe.
+	TraceTiming bool `json:"trace_timing,omitempty"`
+	// Publish every probe result to /api/events and system.probe_webhook_url,
+	// not only state changes. Also on for all monitors with system.probe_events.
+	ProbeEvents bool `json:"probe_events,omitempty"`
+	// Targets probes several endpoints as one monitor, instead of Target.
+	// UpPolicy decides whether "all" (default) or "any" of them must be up.
+	Targets  []string `json:"targets,omitempty"`
+	UpPolicy string   `json:"up_policy,omitempty"`
+}
+
+// ProbeTargets returns the targets the monitor probes: Targets, or Target alone.
+func (m *Monitor) ProbeTargets() []string {
+	if len(m.Targets) > 0 {
+		return m.Targets
+	}
+	return []string{m.Target}
+}
+
+// TargetLabel returns the monitor's targets for display and alerts, with
+// credentials masked by RedactTarget.
+func (m *Monitor) TargetLabel() string {
+	targets := m.ProbeTargets()
+	labels := make([]string, len(targets))
+	for i, t := range targets {
+		labels[i] = RedactTarget(t)
+	}
+	return strings.Join(labels, ", ")
 }
 
 // IsEnabled returns whether the monitor is enabled (defaults to true).
@@ -83,6 +450,47 @@ func (m *Monitor) IsEnabled() bool {
 	return m.Enabled == nil || *m.Enabled
 }
 
+// LatencyCap returns the highest latency in ms recorded in the monitor's history,
+// or 0 if latency is recorded unclamped.
+func (m *Monitor) LatencyCap() int {
+	switch {
+	case m.LatencyCapMs > 0:
+		return m.LatencyCapMs
+	case m.LatencyCapMs == 0:
+		return m.Timeout * 1000
+	}
+	return 0
+}
+
+// allHTTPS reports whether every target is an https:// URL.
+func allHTTPS(targets []string) bool {
+	for _, t := range targets {
+		if !strings.HasPrefix(strings.ToLower(t), "https://") {
+			return false
+		}
+	}
+	return true
+}
+
+// TLSVersions maps the accepted min_tls_version values to their protocol versions.
+var TLSVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// HTTPMethods lists the request methods an http monitor may use.
+var HTTPMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "OPTIONS": true,
+}
+
+// NeedsBody reports whether any assertion of the monitor reads the response
+// body, which a HEAD request never has.
+func (m *Monitor) NeedsBody() bool {
+	return m.JSONPath != "" || m.MinBytes > 0 || m.MaxBytes > 0
+}
+
 // DefaultConfig returns a config with sensible defaults.
 func DefaultConfig() Config {
 	return Config{
@@ -94,12 +502,25 @@ func DefaultConfig() Config {
 			DumpInterval:     300,
 			SessionTTL:       86400,
 			LogLevel:         "info",
+			LogFormat:        "json",
 			MaxMonitors:      500,
 			Timezone:         detectTimezone(),
+
+			MinPasswordLength: 8,
+			BackupCount:       3,
+			DefaultTimeout:    5,
+			MaxTimeout:        120,
+			MinInterval:       5,
+
+			NotifyTimeoutSeconds:   10,
+			ShutdownTimeoutSeconds: 8,
+
+			DefaultHeartbeatPoints:  90,
+			DashboardRefreshSeconds: 10,
 		},
 		Auth: AuthConfig{
 			Username:         "admin",
-			PasswordHash:     "$2a$10$8.FeSs3eopZT0s/fCTdMWuE8U4f/Dv.ERy10fqrb9QnpHNknp8i/q", // 123456
+			PasswordHash:     DefaultPasswordHash,
 			MaxLoginAttempts: 5,
 			LockoutDuration:  900,
 		},
@@ -130,12 +551,42 @@ func (c *Config) ApplyDefaults() {
 	if c.System.LogLevel == "" {
 		c.System.LogLevel = d.System.LogLevel
 	}
+	if c.System.LogFormat == "" {
+		c.System.LogFormat = d.System.LogFormat
+	}
 	if c.System.MaxMonitors <= 0 {
 		c.System.MaxMonitors = d.System.MaxMonitors
 	}
 	if c.System.Timezone == "" {
 		c.System.Timezone = detectTimezone()
 	}
+	if c.System.MinPasswordLength <= 0 {
+		c.System.MinPasswordLength = d.System.MinPasswordLength
+	}
+	if c.System.DefaultTimeout <= 0 {
+		c.System.DefaultTimeout = d.System.DefaultTimeout
+	}
+	if c.System.MaxTimeout <= 0 {
+		c.System.MaxTimeout = d.System.MaxTimeout
+	}
+	if c.System.MinInterval <= 0 {
+		c.System.MinInterval = d.System.MinInterval
+	}
+	if c.System.NotifyTimeoutSeconds <= 0 {
+		c.System.NotifyTimeoutSeconds = d.System.NotifyTimeoutSeconds
+	}
+	if c.System.ShutdownTimeoutSeconds <= 0 {
+		c.System.ShutdownTimeoutSeconds = d.System.ShutdownTimeoutSeconds
+	}
+	if c.System.DefaultHeartbeatPoints <= 0 {
+		c.System.DefaultHeartbeatPoints = d.System.DefaultHeartbeatPoints
+	}
+	if c.System.DashboardRefreshSeconds <= 0 {
+		c.System.DashboardRefreshSeconds = d.System.DashboardRefreshSeconds
+	}
+	if c.System.BackupCount == 0 {
+		c.System.BackupCount = d.System.BackupCount
+	}
 	if c.Auth.MaxLoginAttempts <= 0 {
 		c.Auth.MaxLoginAttempts = d.Auth.MaxLoginAttempts
 	}
@@ -161,40 +612,196 @@ func (c *Config) ApplyDefaults() {
 	}
 	// Remove _default group (was only used for flat notifier storage)
 	delete(c.ContactGroups, "_default")
+	now := time.Now().Unix()
+	for i := range c.Monitors {
+		c.Monitors[i].Target = NormalizeTarget(c.Monitors[i].Type, c.Monitors[i].Target)
+		for j, t := range c.Monitors[i].Targets {
+			c.Monitors[i].Targets[j] = NormalizeTarget(c.Monitors[i].Type, t)
+		}
+		c.Monitors[i].Method = strings.ToUpper(strings.TrimSpace(c.Monitors[i].Method))
+		// Monitors from before timestamps were recorded (or imported without
+		// them) count as created now.
+		if c.Monitors[i].CreatedAt == 0 {
+			c.Monitors[i].CreatedAt = now
+		}
+		if c.Monitors[i].UpdatedAt == 0 {
+			c.Monitors[i].UpdatedAt = c.Monitors[i].CreatedAt
+		}
+	}
+	c.System.BasePath = strings.TrimRight(strings.TrimSpace(c.System.BasePath), "/")
+	c.System.CookieSameSite = strings.ToLower(strings.TrimSpace(c.System.CookieSameSite))
 	// Ensure all notifiers have IDs
 	for i := range c.Notifiers {
 		if c.Notifiers[i].ID == "" {
 			c.Notifiers[i].ID = generateID()
 		}
 	}
-	// Reconcile GroupOrder: remove stale IDs, append missing IDs
-	if c.GroupOrder == nil {
-		c.GroupOrder = make([]string, 0, len(c.ContactGroups))
-		for id := range c.ContactGroups {
-			c.GroupOrder = append(c.GroupOrder, id)
-		}
-	} else {
-		existing := make(map[string]bool, len(c.ContactGroups))
-		for id := range c.ContactGroups {
-			existing[id] = true
-		}
-		// Remove stale IDs
-		clean := make([]string, 0, len(c.GroupOrder))
-		seen := make(map[string]bool, len(c.GroupOrder))
-		for _, id := range c.GroupOrder {
-			if existing[id] && !seen[id] {
-				clean = append(clean, id)
-				seen[id] = true
-			}
+	// Reconcile GroupOrder and MonitorOrder: remove stale IDs, append missing IDs
+	groupIDs := make([]string, 0, len(c.ContactGroups))
+	for id := range c.ContactGroups {
+		groupIDs = append(groupIDs, id)
+	}
+	c.GroupOrder = reconcileOrder(c.GroupOrder, groupIDs)
+
+	monitorIDs := make([]string, 0, len(c.Monitors))
+	for _, m := range c.Monitors {
+		monitorIDs = append(monitorIDs, m.ID)
+	}
+	c.MonitorOrder = reconcileOrder(c.MonitorOrder, monitorIDs)
+}
+
+// hostnameRe matches DNS names; a leading "-" is excluded so a target can never be
+// mistaken for a ping option.
+var hostnameRe = regexp.MustCompile(`^[A-Za-z0-9_]([A-Za-z0-9_.-]*[A-Za-z0-9_.])?$`)
+
+// NormalizeTarget fixes common mistakes in tcp and ping targets: surrounding
+// whitespace, a URL scheme ("tcp://host:port", "https://host/"), a trailing path,
+// and for ping a bracketed IPv6 address. Other types are only trimmed.
+func NormalizeTarget(monitorType, target string) string {
+	target = strings.TrimSpace(target)
+	if monitorType != "tcp" && monitorType != "ping" {
+		return target
+	}
+	if i := strings.Index(target, "://"); i >= 0 {
+		target = target[i+3:]
+	}
+	if i := strings.IndexAny(target, "/?#"); i >= 0 {
+		target = target[:i]
+	}
+	if monitorType == "ping" && strings.HasPrefix(target, "[") && strings.HasSuffix(target, "]") {
+		target = target[1 : len(target)-1]
+	}
+	return target
+}
+
+// Redacted is the mask that replaces secret values in targets, probe errors
+// and API responses.
+const Redacted = "xxxxx"
+
+// secretParams are query parameter names whose values RedactTarget masks.
+var secretParams = regexp.MustCompile(`(?i)(token|key|secret|passw(or)?d|pwd|auth|signature|sig)`)
+
+// RedactTarget masks credentials in a URL target for display and alerts: the
+// userinfo password and the values of secret-looking query parameters. Targets
+// that are not URLs are returned unchanged.
+func RedactTarget(target string) string {
+	if !strings.Contains(target, "://") {
+		return target
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	changed := false
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), Redacted)
+			changed = true
 		}
-		// Append missing IDs
-		for id := range c.ContactGroups {
-			if !seen[id] {
-				clean = append(clean, id)
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			if secretParams.MatchString(k) {
+				q[k] = []string{Redacted}
+				changed = true
 			}
 		}
-		c.GroupOrder = clean
+		if changed {
+			u.RawQuery = q.Encode()
+		}
+	}
+	if !changed {
+		return target
+	}
+	return u.String()
+}
+
+// isIP reports whether s is an IPv4 or IPv6 address. An IPv6 address may carry
+// a zone, e.g. fe80::1%eth0.
+func isIP(s string) bool {
+	ip, _, _ := strings.Cut(s, "%")
+	return net.ParseIP(ip) != nil
+}
+
+// ValidateTarget checks that a tcp target is host:port with a numeric port and
+// that a ping target is a bare hostname or IP address. Other types are not
+// checked here.
+func ValidateTarget(monitorType, target string) error {
+	switch monitorType {
+	case "tcp":
+		if isIP(target) {
+			return errors.New("must be host:port; write an IPv6 address in brackets, e.g. [2001:db8::1]:443")
+		}
+		host, port, err := net.SplitHostPort(target)
+		if err != nil || host == "" {
+			return errors.New("must be host:port")
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return errors.New("must have a numeric port between 1 and 65535")
+		}
+		if !isIP(host) && !hostnameRe.MatchString(host) {
+			return errors.New("has an invalid host")
+		}
+	case "ping":
+		if isIP(target) {
+			return nil
+		}
+		if strings.Contains(target, ":") {
+			return errors.New("must be a hostname or IP address without a port")
+		}
+		if !hostnameRe.MatchString(target) {
+			return errors.New("must be a hostname or IP address")
+		}
+	}
+	return nil
+}
+
+// reconcileOrder returns order with unknown and duplicate IDs removed and any
+// IDs from existing that are missing appended in their original sequence.
+func reconcileOrder(order []string, existing []string) []string {
+	known := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		known[id] = true
+	}
+	clean := make([]string, 0, len(existing))
+	seen := make(map[string]bool, len(order))
+	for _, id := range order {
+		if known[id] && !seen[id] {
+			clean = append(clean, id)
+			seen[id] = true
+		}
+	}
+	for _, id := range existing {
+		if !seen[id] {
+			clean = append(clean, id)
+			seen[id] = true
+		}
+	}
+	return clean
+}
+
+// OrderedMonitors returns the monitors sorted by MonitorOrder.
+// Monitors missing from MonitorOrder keep their relative slice order at the end.
+func (c *Config) OrderedMonitors() []Monitor {
+	pos := make(map[string]int, len(c.MonitorOrder))
+	for i, id := range c.MonitorOrder {
+		pos[id] = i
 	}
+	result := make([]Monitor, len(c.Monitors))
+	copy(result, c.Monitors)
+	sort.SliceStable(result, func(i, j int) bool {
+		pi, ok := pos[result[i].ID]
+		if !ok {
+			pi = len(pos)
+		}
+		pj, ok := pos[result[j].ID]
+		if !ok {
+			pj = len(pos)
+		}
+		return pi < pj
+	})
+	return result
 }
 
 // detectTimezone returns the system's IANA timezone name, falling back to "UTC".
@@ -219,19 +826,135 @@ func (c *Config) Validate() error {
 	if c.System.CheckInterval < 5 {
 		errs = append(errs, "system.check_interval must be >= 5 seconds")
 	}
+	if c.System.CheckInterval < c.System.MinInterval {
+		errs = append(errs, fmt.Sprintf("system.check_interval (%d) must be >= min_interval (%d)", c.System.CheckInterval, c.System.MinInterval))
+	}
+	if c.System.InitialBackoffMax < 0 {
+		errs = append(errs, "system.initial_backoff_max must be >= 0")
+	}
+	if c.System.ProbeCoalesceWindow < 0 {
+		errs = append(errs, "system.probe_coalesce_window must be >= 0")
+	} else if c.System.ProbeCoalesceWindow > 0 && c.System.ProbeCoalesceWindow >= c.System.MinInterval {
+		// A monitor must never pick up its own previous result.
+		errs = append(errs, fmt.Sprintf("system.probe_coalesce_window (%d) must be < min_interval (%d)", c.System.ProbeCoalesceWindow, c.System.MinInterval))
+	}
+	for i, rule := range c.System.ReasonRules {
+		if strings.TrimSpace(rule.Category) == "" {
+			errs = append(errs, fmt.Sprintf("system.reason_rules[%d].category is required", i))
+		}
+		if _, err := regexp.Compile(rule.Match); err != nil || rule.Match == "" {
+			errs = append(errs, fmt.Sprintf("system.reason_rules[%d].match must be a valid regular expression", i))
+		}
+	}
+	if c.System.DefaultTimeout > c.System.MaxTimeout {
+		errs = append(errs, fmt.Sprintf("system.default_timeout (%d) must be <= max_timeout (%d)", c.System.DefaultTimeout, c.System.MaxTimeout))
+	}
+	if c.System.NotifyTimeoutSeconds > 300 {
+		errs = append(errs, "system.notify_timeout must be <= 300 seconds")
+	}
+	if h := c.System.HistoryRetentionHours; h < 0 || h > MaxHistoryRetentionHours {
+		errs = append(errs, fmt.Sprintf("system.history_retention_hours must be between 0 and %d (got %d)", MaxHistoryRetentionHours, h))
+	}
+	if c.System.IncidentMaxOpenHours < 0 {
+		errs = append(errs, "system.incident_max_open_hours must be >= 0")
+	}
+	if c.System.ShutdownTimeoutSeconds > 300 {
+		errs = append(errs, "system.shutdown_timeout must be <= 300 seconds")
+	}
+	if c.System.DefaultHeartbeatPoints > MaxHeartbeatPoints {
+		errs = append(errs, fmt.Sprintf("system.default_heartbeat_points must be between 1 and %d", MaxHeartbeatPoints))
+	}
+	if c.System.DashboardRefreshSeconds < 2 || c.System.DashboardRefreshSeconds > 3600 {
+		errs = append(errs, "system.dashboard_refresh must be between 2 and 3600 seconds")
+	}
+	if len(c.System.MetricsBuckets) > maxMetricsBuckets {
+		errs = append(errs, fmt.Sprintf("system.metrics_buckets must have at most %d entries", maxMetricsBuckets))
+	}
+	for i, b := range c.System.MetricsBuckets {
+		if b <= 0 || (i > 0 && b <= c.System.MetricsBuckets[i-1]) {
+			errs = append(errs, "system.metrics_buckets must be positive and strictly increasing")
+			break
+		}
+	}
+	if u := c.System.ProbeWebhookURL; u != "" {
+		if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errs = append(errs, fmt.Sprintf("system.probe_webhook_url must be an http(s) URL (got %q)", u))
+		}
+	}
 	if c.Auth.Username == "" {
 		errs = append(errs, "auth.username is required")
 	}
+	shareIDs := make(map[string]bool, len(c.Auth.ShareLinks))
+	for i, l := range c.Auth.ShareLinks {
+		if l.ID == "" || shareIDs[l.ID] {
+			errs = append(errs, fmt.Sprintf("auth.share_links[%d].id must be set and unique", i))
+		}
+		shareIDs[l.ID] = true
+		if b, err := hex.DecodeString(l.TokenHash); err != nil || len(b) != sha256.Size {
+			errs = append(errs, fmt.Sprintf("auth.share_links[%d].token_hash must be a hex SHA-256 digest", i))
+		}
+	}
 
 	validLogLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
 	if !validLogLevels[c.System.LogLevel] {
 		errs = append(errs, fmt.Sprintf("system.log_level must be one of: debug, info, warn, error (got %q)", c.System.LogLevel))
 	}
+	if c.System.LogFormat != "json" && c.System.LogFormat != "text" {
+		errs = append(errs, fmt.Sprintf("system.log_format must be json or text (got %q)", c.System.LogFormat))
+	}
+	if r := c.System.DNSResolver; r != "" {
+		if host, port, err := net.SplitHostPort(r); err != nil || net.ParseIP(host) == nil || port == "" {
+			errs = append(errs, fmt.Sprintf("system.dns_resolver must be an IP address and port, e.g. \"10.0.0.53:53\" (got %q)", r))
+		}
+	}
+	if p := c.System.BasePath; p != "" && (!strings.HasPrefix(p, "/") || strings.ContainsAny(p, "?#%\\ \t") || strings.Contains(p, "//")) {
+		errs = append(errs, fmt.Sprintf("system.base_path must be a URL path such as \"/wink\" (got %q)", p))
+	}
+	switch c.System.CookieSameSite {
+	case "", "strict", "lax":
+	case "none":
+		if !c.System.CookieSecure {
+			errs = append(errs, "system.cookie_samesite \"none\" requires cookie_secure, browsers reject it otherwise")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("system.cookie_samesite must be strict, lax or none (got %q)", c.System.CookieSameSite))
+	}
+	if strings.ContainsAny(c.System.CookieDomain, "/:; \t") {
+		errs = append(errs, fmt.Sprintf("system.cookie_domain must be a host name (got %q)", c.System.CookieDomain))
+	}
+	if c.System.ProbeSourceIP != "" && net.ParseIP(c.System.ProbeSourceIP) == nil {
+		errs = append(errs, fmt.Sprintf("system.probe_source_ip is not a valid IP address (got %q)", c.System.ProbeSourceIP))
+	}
+	if len(c.System.Region) > maxRegionLength {
+		errs = append(errs, fmt.Sprintf("system.region must be at most %d characters", maxRegionLength))
+	}
 
 	if len(c.Monitors) > c.System.MaxMonitors {
 		errs = append(errs, fmt.Sprintf("monitors count (%d) exceeds max_monitors (%d)", len(c.Monitors), c.System.MaxMonitors))
 	}
 
+	notifierIDs := make(map[string]bool, len(c.Notifiers))
+	for i, n := range c.Notifiers {
+		notifierIDs[n.ID] = true
+		for _, e := range n.Events {
+			if !validEventTypes[e] {
+				errs = append(errs, fmt.Sprintf("notifiers[%d].events must contain only down, up, anomaly or slow (got %q)", i, e))
+			}
+		}
+		if n.Delivery != "" && n.Delivery != "any" && n.Delivery != "all" {
+			errs = append(errs, fmt.Sprintf("notifiers[%d].delivery must be any or all (got %q)", i, n.Delivery))
+		}
+		if n.Encoding != "" && n.Encoding != "json" && n.Encoding != "form" && n.Encoding != "query" {
+			errs = append(errs, fmt.Sprintf("notifiers[%d].encoding must be json, form or query (got %q)", i, n.Encoding))
+		}
+		if n.MinIntervalSeconds < 0 {
+			errs = append(errs, fmt.Sprintf("notifiers[%d].min_interval_seconds must be >= 0", i))
+		}
+		if n.Priority != nil && (*n.Priority < -2 || *n.Priority > 1) {
+			errs = append(errs, fmt.Sprintf("notifiers[%d].priority must be between -2 and 1", i))
+		}
+	}
+
 	seen := make(map[string]bool)
 	for i, m := range c.Monitors {
 		prefix := fmt.Sprintf("monitors[%d]", i)
@@ -247,18 +970,36 @@ func (c *Config) Validate() error {
 			errs = append(errs, prefix+".name is required")
 		}
 
-		validTypes := map[string]bool{"http": true, "tcp": true, "ping": true}
+		validTypes := map[string]bool{"http": true, "tcp": true, "ping": true, "smtp": true, "ws": true}
 		if !validTypes[m.Type] {
-			errs = append(errs, fmt.Sprintf("%s.type must be http, tcp, or ping (got %q)", prefix, m.Type))
+			errs = append(errs, fmt.Sprintf("%s.type must be http, tcp, ping, smtp, or ws (got %q)", prefix, m.Type))
 		}
 
-		if m.Target == "" {
-			errs = append(errs, prefix+".target is required")
-		} else if m.Type == "http" {
-			if u, err := url.Parse(m.Target); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
-				errs = append(errs, prefix+".target must be a valid http(s) URL")
+		if m.Target != "" && len(m.Targets) > 0 {
+			errs = append(errs, prefix+".target and targets are mutually exclusive")
+		}
+		for j, target := range m.ProbeTargets() {
+			field := prefix + ".target"
+			if len(m.Targets) > 0 {
+				field = fmt.Sprintf("%s.targets[%d]", prefix, j)
+			}
+			if target == "" {
+				errs = append(errs, field+" is required")
+			} else if m.Type == "http" {
+				if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+					errs = append(errs, field+" must be a valid http(s) URL")
+				}
+			} else if m.Type == "ws" {
+				if u, err := url.Parse(target); err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+					errs = append(errs, field+" must be a valid ws(s) URL")
+				}
+			} else if err := ValidateTarget(m.Type, target); err != nil {
+				errs = append(errs, fmt.Sprintf("%s %v (got %q)", field, err, target))
 			}
 		}
+		if m.UpPolicy != "" && m.UpPolicy != "all" && m.UpPolicy != "any" {
+			errs = append(errs, fmt.Sprintf("%s.up_policy must be all or any (got %q)", prefix, m.UpPolicy))
+		}
 
 		if m.GroupID != "" {
 			if _, ok := c.ContactGroups[m.GroupID]; !ok {
@@ -266,12 +1007,96 @@ func (c *Config) Validate() error {
 			}
 		}
 
+		if m.ExpectRegex && m.ExpectData != "" {
+			if _, err := regexp.Compile(m.ExpectData); err != nil {
+				errs = append(errs, fmt.Sprintf("%s.expect_data is not a valid regex: %v", prefix, err))
+			}
+		}
+
+		if m.JSONPath != "" {
+			if m.Type != "http" {
+				errs = append(errs, prefix+".json_path is only supported for http monitors")
+			} else if _, err := jsonpath.Parse(m.JSONPath); err != nil {
+				errs = append(errs, fmt.Sprintf("%s.json_path is invalid: %v", prefix, err))
+			}
+		} else if m.JSONExpected != "" {
+			errs = append(errs, prefix+".json_expected requires json_path")
+		}
+
+		if m.Method != "" {
+			switch {
+			case m.Type != "http":
+				errs = append(errs, prefix+".method is only supported for http monitors")
+			case !HTTPMethods[m.Method]:
+				errs = append(errs, fmt.Sprintf("%s.method %q is not supported", prefix, m.Method))
+			case m.Method == "HEAD" && m.NeedsBody():
+				errs = append(errs, prefix+".json_path, min_bytes and max_bytes need a response body and cannot be used with method HEAD")
+			}
+		}
+
+		if m.ExpectContentType != "" {
+			switch {
+			case m.Type != "http":
+				errs = append(errs, prefix+".expect_content_type is only supported for http monitors")
+			case strings.ContainsAny(m.ExpectContentType, ";, \t"):
+				errs = append(errs, fmt.Sprintf("%s.expect_content_type must be a media type without parameters, e.g. \"application/json\" (got %q)", prefix, m.ExpectContentType))
+			}
+		}
+
+		if m.MinTLSVersion != "" || m.RequireHTTP2 {
+			switch {
+			case m.Type != "http":
+				errs = append(errs, prefix+".min_tls_version and require_http2 are only supported for http monitors")
+			case m.MinTLSVersion != "" && TLSVersions[m.MinTLSVersion] == 0:
+				errs = append(errs, fmt.Sprintf("%s.min_tls_version must be one of 1.0, 1.1, 1.2 or 1.3 (got %q)", prefix, m.MinTLSVersion))
+			case !allHTTPS(m.ProbeTargets()):
+				// Plain HTTP has no TLS, and probes never speak HTTP/2 without it.
+				errs = append(errs, prefix+".min_tls_version and require_http2 need an https:// target")
+			}
+		}
+
+		if m.PingCount != 0 || m.PingLossThreshold != 0 {
+			switch {
+			case m.Type != "ping":
+				errs = append(errs, prefix+".ping_count and ping_loss_threshold are only supported for ping monitors")
+			case m.PingCount < 0 || m.PingCount > MaxPingCount:
+				errs = append(errs, fmt.Sprintf("%s.ping_count must be between 0 and %d (got %d)", prefix, MaxPingCount, m.PingCount))
+			case m.PingLossThreshold < 0 || m.PingLossThreshold > 100:
+				errs = append(errs, fmt.Sprintf("%s.ping_loss_threshold must be between 0 and 100 (got %d)", prefix, m.PingLossThreshold))
+			}
+		}
+
+		if m.SourceIP != "" && net.ParseIP(m.SourceIP) == nil {
+			errs = append(errs, fmt.Sprintf("%s.source_ip is not a valid IP address (got %q)", prefix, m.SourceIP))
+		}
+
+		if m.MinBytes != 0 || m.MaxBytes != 0 {
+			switch {
+			case m.Type != "http":
+				errs = append(errs, prefix+".min_bytes and max_bytes are only supported for http monitors")
+			case m.MinBytes < 0 || m.MaxBytes < 0 || m.MinBytes > MaxResponseBytes || m.MaxBytes > MaxResponseBytes:
+				errs = append(errs, fmt.Sprintf("%s.min_bytes and max_bytes must be between 0 and %d", prefix, MaxResponseBytes))
+			case m.MaxBytes > 0 && m.MinBytes > m.MaxBytes:
+				errs = append(errs, prefix+".min_bytes must not exceed max_bytes")
+			}
+		}
+
+		for _, nid := range m.NotifierIDs {
+			if !notifierIDs[nid] {
+				errs = append(errs, fmt.Sprintf("%s.notifier_ids references unknown notifier %q", prefix, nid))
+			}
+		}
+
 		interval := m.Interval
 		if interval <= 0 {
 			interval = c.System.CheckInterval
+		} else if interval < c.System.MinInterval {
+			errs = append(errs, fmt.Sprintf("%s.interval (%d) must be >= system.min_interval (%d)", prefix, interval, c.System.MinInterval))
 		}
 		if m.Timeout <= 0 {
 			errs = append(errs, prefix+".timeout must be > 0")
+		} else if m.Timeout > c.System.MaxTimeout {
+			errs = append(errs, fmt.Sprintf("%s.timeout (%d) must be <= system.max_timeout (%d)", prefix, m.Timeout, c.System.MaxTimeout))
 		} else if m.Timeout >= interval {
 			errs = append(errs, fmt.Sprintf("%s.timeout (%d) must be < interval (%d)", prefix, m.Timeout, interval))
 		}
@@ -281,10 +1106,72 @@ func (c *Config) Validate() error {
 		}
 		if m.RetryInterval < 0 {
 			errs = append(errs, prefix+".retry_interval must be >= 0")
+		} else if m.RetryInterval > 0 && m.RetryInterval < c.System.MinInterval {
+			errs = append(errs, fmt.Sprintf("%s.retry_interval (%d) must be 0 or >= system.min_interval (%d)", prefix, m.RetryInterval, c.System.MinInterval))
+		} else if m.RetryInterval > interval {
+			errs = append(errs, fmt.Sprintf("%s.retry_interval (%d) must be <= interval (%d)", prefix, m.RetryInterval, interval))
+		}
+		switch m.FailureMode {
+		case "", "consecutive":
+			if m.WindowSize != 0 || m.WindowThresholdPct != 0 {
+				errs = append(errs, prefix+".window_size and window_threshold_pct need failure_mode window")
+			}
+		case "window":
+			if m.WindowSize < 2 || m.WindowSize > MaxFailureWindow {
+				errs = append(errs, fmt.Sprintf("%s.window_size must be between 2 and %d", prefix, MaxFailureWindow))
+			} else if c.System.HistoryRetentionHours == 0 && m.WindowSize > c.System.MaxHistoryPoints {
+				errs = append(errs, fmt.Sprintf("%s.window_size (%d) must be <= system.max_history_points (%d)", prefix, m.WindowSize, c.System.MaxHistoryPoints))
+			}
+			if m.WindowThresholdPct < 1 || m.WindowThresholdPct > 99 {
+				errs = append(errs, prefix+".window_threshold_pct must be between 1 and 99")
+			}
+		default:
+			errs = append(errs, fmt.Sprintf("%s.failure_mode must be consecutive or window (got %q)", prefix, m.FailureMode))
 		}
 		if m.ReminderInterval < 0 {
 			errs = append(errs, prefix+".reminder_interval must be >= 0")
 		}
+		if m.RecoveryThreshold < 0 {
+			errs = append(errs, prefix+".recovery_threshold must be >= 0")
+		}
+		if m.RecoveryGraceSeconds < 0 {
+			errs = append(errs, prefix+".recovery_grace_seconds must be >= 0")
+		}
+		if m.SLATarget < 0 || m.SLATarget > 100 {
+			errs = append(errs, prefix+".sla_target must be between 0 and 100")
+		}
+		if m.RetryHold < 0 {
+			errs = append(errs, prefix+".retry_hold must be >= 0")
+		}
+		if m.AnomalyK < 0 {
+			errs = append(errs, prefix+".anomaly_k must be >= 0")
+		}
+		if m.AnomalyCount < 0 {
+			errs = append(errs, prefix+".anomaly_count must be >= 0")
+		}
+		if m.SlowThresholdMs < 0 {
+			errs = append(errs, prefix+".slow_threshold_ms must be >= 0")
+		}
+		if m.SlowCount < 0 {
+			errs = append(errs, prefix+".slow_count must be >= 0")
+		}
+		if m.LatencyCountsAsDown {
+			switch {
+			case m.Type != "http":
+				errs = append(errs, prefix+".latency_counts_as_down is only supported for http monitors")
+			case m.SlowThresholdMs == 0:
+				errs = append(errs, prefix+".latency_counts_as_down requires slow_threshold_ms")
+			case m.SlowCount != 0:
+				// slow_count only tunes the "slow" events, which this mode replaces.
+				errs = append(errs, prefix+".slow_count cannot be set together with latency_counts_as_down")
+			}
+		}
+		if m.HonorRetryAfter && m.Type != "http" {
+			errs = append(errs, prefix+".honor_retry_after is only supported for http monitors")
+		}
+		if m.TraceTiming && m.Type != "http" {
+			errs = append(errs, prefix+".trace_timing is only supported for http monitors")
+		}
 	}
 
 	if len(errs) > 0 {
@@ -292,3 +1179,14 @@ func (c *Config) Validate() error {
 	}
 	return nil
 }
+
+// Warnings returns non-fatal configuration issues worth surfacing to the operator.
+func (c *Config) Warnings() []string {
+	var warns []string
+	for _, m := range c.Monitors {
+		if m.IsEnabled() && len(m.NotifierIDs) == 0 {
+			warns = append(warns, fmt.Sprintf("monitor %q (%s) has no notifiers and will not send alerts", m.Name, m.ID))
+		}
+	}
+	return warns
+}
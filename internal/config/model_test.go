@@ -0,0 +1,60 @@
+package config
+
+import "testing"
+
+func TestValidateTarget(t *testing.T) {
+	tests := []struct {
+		typ, target string
+		ok          bool
+	}{
+		{"tcp", "192.0.2.1:443", true},
+		{"tcp", "[2001:db8::1]:443", true},
+		{"tcp", "[fe80::1%eth0]:22", true},
+		{"tcp", "db.example.com:5432", true},
+		{"tcp", "2001:db8::1", false},     // bare IPv6 has no port
+		{"tcp", "2001:db8::1:443", false}, // ambiguous without brackets
+		{"tcp", "192.0.2.1", false},
+		{"tcp", "db.example.com", false},
+		{"tcp", "db.example.com:0", false},
+		{"tcp", "db.example.com:http", false},
+		{"tcp", "-bad.example.com:80", false},
+
+		{"ping", "192.0.2.1", true},
+		{"ping", "2001:db8::1", true},
+		{"ping", "fe80::1%eth0", true},
+		{"ping", "host.example.com", true},
+		{"ping", "192.0.2.1:80", false},
+		{"ping", "[2001:db8::1]:443", false},
+		{"ping", "host.example.com:80", false},
+		{"ping", "-c", false},
+
+		// Other types are not checked here.
+		{"http", "2001:db8::1", true},
+	}
+	for _, tt := range tests {
+		err := ValidateTarget(tt.typ, tt.target)
+		if (err == nil) != tt.ok {
+			t.Errorf("ValidateTarget(%q, %q) = %v, want ok=%v", tt.typ, tt.target, err, tt.ok)
+		}
+	}
+}
+
+func TestNormalizeTarget(t *testing.T) {
+	tests := []struct {
+		typ, target, want string
+	}{
+		{"tcp", " tcp://192.0.2.1:443/ ", "192.0.2.1:443"},
+		{"tcp", "tcp://[2001:db8::1]:443/path", "[2001:db8::1]:443"},
+		{"tcp", "db.example.com:5432", "db.example.com:5432"},
+		{"ping", "[2001:db8::1]", "2001:db8::1"},
+		{"ping", "2001:db8::1", "2001:db8::1"},
+		{"ping", "https://host.example.com/", "host.example.com"},
+		{"ping", "192.0.2.1", "192.0.2.1"},
+		{"http", " https://[2001:db8::1]/health ", "https://[2001:db8::1]/health"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeTarget(tt.typ, tt.target); got != tt.want {
+			t.Errorf("NormalizeTarget(%q, %q) = %q, want %q", tt.typ, tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/makt28/wink/internal/config"
+)
+
+// BulkMonitor is one entry of a bulk monitor file. The monitor has no ID or
+// timestamps yet; the caller assigns an ID before saving.
+type BulkMonitor struct {
+	Monitor config.Monitor
+	Err     error // why the entry could not be decoded; nil if it was
+}
+
+// ParseMonitors reads a bulk monitor file: a JSON array of monitors in the
+// config.json format, or an object with a "monitors" array such as a
+// config.json itself. Fields left out get the defaults of the monitor form
+// (system check interval and default timeout, 3 retries). An entry that does
+// not decode is returned with Err set instead of failing the whole file.
+func ParseMonitors(raw []byte, sys config.SystemConfig) ([]BulkMonitor, error) {
+	raw = bytes.TrimSpace(raw)
+	var items []json.RawMessage
+	if len(raw) > 0 && raw[0] == '{' {
+		var wrapper struct {
+			Monitors []json.RawMessage `json:"monitors"`
+		}
+		if err := json.Unmarshal(raw, &wrapper); err != nil {
+			return nil, err
+		}
+		items = wrapper.Monitors
+	} else if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, errors.New("no monitors found")
+	}
+
+	result := make([]BulkMonitor, len(items))
+	for i, item := range items {
+		m := config.Monitor{
+			Interval:   sys.CheckInterval,
+			Timeout:    sys.DefaultTimeout,
+			MaxRetries: 3,
+		}
+		if err := json.Unmarshal(item, &m); err != nil {
+			result[i].Err = fmt.Errorf("invalid monitor: %w", err)
+			continue
+		}
+		m.ID = ""
+		m.CreatedAt, m.UpdatedAt = 0, 0
+		m.Target = config.NormalizeTarget(m.Type, m.Target)
+		m.Method = strings.ToUpper(strings.TrimSpace(m.Method))
+		result[i].Monitor = m
+	}
+	return result, nil
+}
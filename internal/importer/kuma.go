@@ -0,0 +1,220 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/makt28/wink/internal/config"
+	"github.com/makt28/wink/internal/storage"
+)
+
+// kumaExport is the subset of an Uptime Kuma JSON backup that Wink understands.
+// heartbeatList has the same shape Kuma emits over its socket API:
+// monitor ID -> list of beats.
+type kumaExport struct {
+	MonitorList   []kumaMonitor              `json:"monitorList"`
+	HeartbeatList map[string][]kumaHeartbeat `json:"heartbeatList"`
+}
+
+type kumaMonitor struct {
+	ID            int      `json:"id"`
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	URL           string   `json:"url"`
+	Hostname      string   `json:"hostname"`
+	Port          int      `json:"port"`
+	Interval      int      `json:"interval"`
+	RetryInterval int      `json:"retryInterval"`
+	MaxRetries    int      `json:"maxretries"`
+	Timeout       float64  `json:"timeout"`
+	Active        flexBool `json:"active"`
+	IgnoreTLS     flexBool `json:"ignoreTls"`
+}
+
+type kumaHeartbeat struct {
+	Status int      `json:"status"` // 0 down, 1 up, 2 pending, 3 maintenance
+	Time   string   `json:"time"`
+	Ping   *float64 `json:"ping"`
+}
+
+// Kuma heartbeat statuses Wink cares about.
+const (
+	kumaUp          = 1
+	kumaMaintenance = 3
+)
+
+// flexBool accepts both JSON booleans and the 0/1 integers older Kuma exports use.
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	switch strings.TrimSpace(string(data)) {
+	case "true", "1":
+		*b = true
+	case "false", "0", "null":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
+
+// KumaMonitor is a converted monitor together with its imported history.
+// The monitor has no ID yet; the caller assigns one before saving.
+type KumaMonitor struct {
+	Monitor config.Monitor
+	History []storage.LatencyPoint
+}
+
+// Skipped describes a Kuma monitor that could not be imported.
+type Skipped struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// KumaResult is the outcome of converting an Uptime Kuma export.
+type KumaResult struct {
+	Monitors []KumaMonitor
+	Skipped  []Skipped
+}
+
+// heartbeatTimeLayouts are the time formats seen in Kuma exports (stored in UTC).
+var heartbeatTimeLayouts = []string{
+	"2006-01-02 15:04:05.000",
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+}
+
+// ParseKuma converts an Uptime Kuma JSON backup into Wink monitors and latency
+// history. http, port (tcp) and ping monitors are supported; everything else is
+// reported in Skipped. defaultInterval is used when a monitor has none.
+func ParseKuma(data []byte, defaultInterval int) (*KumaResult, error) {
+	var exp kumaExport
+	if err := json.Unmarshal(data, &exp); err != nil {
+		return nil, fmt.Errorf("parse Uptime Kuma export: %w", err)
+	}
+	if exp.MonitorList == nil {
+		return nil, fmt.Errorf("parse Uptime Kuma export: no monitorList found")
+	}
+
+	res := &KumaResult{}
+	for _, km := range exp.MonitorList {
+		m, reason := convertKumaMonitor(km, defaultInterval)
+		if reason != "" {
+			res.Skipped = append(res.Skipped, Skipped{Name: km.Name, Type: km.Type, Reason: reason})
+			continue
+		}
+		res.Monitors = append(res.Monitors, KumaMonitor{
+			Monitor: m,
+			History: convertHeartbeats(exp.HeartbeatList[strconv.Itoa(km.ID)]),
+		})
+	}
+	return res, nil
+}
+
+// convertKumaMonitor maps a Kuma monitor onto config.Monitor. A non-empty
+// reason means the monitor cannot be imported.
+func convertKumaMonitor(km kumaMonitor, defaultInterval int) (config.Monitor, string) {
+	if km.Name == "" {
+		return config.Monitor{}, "missing name"
+	}
+
+	m := config.Monitor{
+		Name:          km.Name,
+		Interval:      km.Interval,
+		MaxRetries:    km.MaxRetries,
+		RetryInterval: km.RetryInterval,
+		IgnoreTLS:     bool(km.IgnoreTLS),
+	}
+
+	switch km.Type {
+	case "http":
+		if u, err := url.Parse(km.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return config.Monitor{}, "invalid URL"
+		}
+		m.Type = "http"
+		m.Target = km.URL
+	case "port":
+		if km.Port <= 0 {
+			return config.Monitor{}, "missing port"
+		}
+		m.Type = "tcp"
+		m.Target = net.JoinHostPort(km.Hostname, strconv.Itoa(km.Port))
+		m.IgnoreTLS = false
+	case "ping":
+		m.Type = "ping"
+		m.Target = km.Hostname
+		m.IgnoreTLS = false
+	default:
+		return config.Monitor{}, "unsupported type"
+	}
+	if m.Target == "" || (m.Type != "http" && km.Hostname == "") {
+		return config.Monitor{}, "missing target"
+	}
+
+	if m.Interval <= 0 {
+		m.Interval = defaultInterval
+	}
+	if m.MaxRetries < 0 {
+		m.MaxRetries = 0
+	}
+	if m.RetryInterval < 0 {
+		m.RetryInterval = 0
+	}
+	// Kuma allows fractional timeouts and defaults to 80% of the interval;
+	// Wink needs a whole number of seconds strictly below the interval.
+	m.Timeout = int(km.Timeout)
+	if m.Timeout <= 0 {
+		m.Timeout = m.Interval * 8 / 10
+	}
+	if m.Timeout >= m.Interval {
+		m.Timeout = m.Interval - 1
+	}
+	if m.Timeout <= 0 {
+		m.Timeout = 1
+	}
+
+	if !km.Active {
+		disabled := false
+		m.Enabled = &disabled
+	}
+	return m, ""
+}
+
+// convertHeartbeats turns Kuma heartbeats into latency points sorted by time.
+// Maintenance beats and beats with unparseable times are dropped; pending beats
+// count as failed probes.
+func convertHeartbeats(beats []kumaHeartbeat) []storage.LatencyPoint {
+	points := make([]storage.LatencyPoint, 0, len(beats))
+	for _, b := range beats {
+		if b.Status == kumaMaintenance {
+			continue
+		}
+		t, ok := parseHeartbeatTime(b.Time)
+		if !ok {
+			continue
+		}
+		p := storage.LatencyPoint{Time: t.Unix(), Up: b.Status == kumaUp}
+		if b.Ping != nil && *b.Ping > 0 {
+			p.Latency = int(*b.Ping)
+		}
+		points = append(points, p)
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time < points[j].Time })
+	return points
+}
+
+func parseHeartbeatTime(s string) (time.Time, bool) {
+	for _, layout := range heartbeatTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
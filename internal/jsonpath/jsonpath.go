@@ -0,0 +1,121 @@
+// Package jsonpath evaluates the small subset of JSONPath used by HTTP monitor
+// assertions: dotted keys and bracketed indexes or quoted keys, e.g.
+// "status", "$.checks[0].ok" or `data["build-id"]`.
+package jsonpath
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is one step of a path: an object key or an array index.
+type segment struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+// Path is a parsed JSON path.
+type Path []segment
+
+// Parse parses a dot/bracket path. A leading "$" is optional.
+func Parse(s string) (Path, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "$")
+	s = strings.TrimPrefix(s, ".")
+	if s == "" {
+		return nil, errors.New("empty path")
+	}
+
+	var p Path
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '.':
+			i++
+			if i >= len(s) || s[i] == '.' || s[i] == '[' {
+				return nil, fmt.Errorf("empty key at offset %d", i)
+			}
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed bracket at offset %d", i)
+			}
+			inner := s[i+1 : i+end]
+			if n := len(inner); n >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[n-1] == inner[0] {
+				p = append(p, segment{key: inner[1 : n-1]})
+			} else if idx, err := strconv.Atoi(inner); err == nil && idx >= 0 {
+				p = append(p, segment{index: idx, isIdx: true})
+			} else {
+				return nil, fmt.Errorf("invalid bracket %q", "["+inner+"]")
+			}
+			i += end + 1
+		default:
+			j := i
+			for j < len(s) && s[j] != '.' && s[j] != '[' {
+				j++
+			}
+			p = append(p, segment{key: s[i:j]})
+			i = j
+		}
+	}
+	return p, nil
+}
+
+// Lookup walks v (as produced by a json.Decoder with UseNumber) along the path.
+func (p Path) Lookup(v interface{}) (interface{}, bool) {
+	for _, seg := range p {
+		if seg.isIdx {
+			arr, ok := v.([]interface{})
+			if !ok || seg.index >= len(arr) {
+				return nil, false
+			}
+			v = arr[seg.index]
+			continue
+		}
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[seg.key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// Match reports whether a looked-up value equals expected. Strings compare
+// verbatim, booleans and numbers by value ("1.0" matches 1), and "null" matches null.
+func Match(v interface{}, expected string) bool {
+	switch val := v.(type) {
+	case string:
+		return val == expected
+	case bool:
+		b, err := strconv.ParseBool(expected)
+		return err == nil && b == val
+	case json.Number:
+		got, err1 := val.Float64()
+		want, err2 := strconv.ParseFloat(strings.TrimSpace(expected), 64)
+		return err1 == nil && err2 == nil && got == want
+	case nil:
+		return expected == "null"
+	default:
+		// Objects and arrays compare by their compact JSON encoding.
+		b, err := json.Marshal(val)
+		return err == nil && string(b) == expected
+	}
+}
+
+// Format renders a looked-up value for error messages.
+func Format(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
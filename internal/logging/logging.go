@@ -0,0 +1,90 @@
+// Package logging builds Wink's slog handlers and swaps them when the logging
+// settings change at runtime.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// ParseLevel maps a config log level to a slog level; unknown values mean info.
+func ParseLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// NewHandler returns a text or JSON handler writing to w. Any format other than
+// "text" produces JSON.
+func NewHandler(w io.Writer, format string, level slog.Leveler) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == "text" {
+		return slog.NewTextHandler(w, opts)
+	}
+	return slog.NewJSONHandler(w, opts)
+}
+
+// Logger owns the process-wide default logger. Level changes take effect in
+// place; format and file changes install a new handler.
+type Logger struct {
+	mu     sync.Mutex
+	level  slog.LevelVar
+	format string
+	path   string
+	file   *os.File
+	ready  bool
+}
+
+// Apply installs the given settings as the default logger. An empty path logs
+// to stderr; otherwise logs are appended to the file at path. On error the
+// previous logger stays in place.
+func (l *Logger) Apply(level, format, path string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.level.Set(ParseLevel(level))
+	if l.ready && format == l.format && path == l.path {
+		return nil
+	}
+
+	var w io.Writer = os.Stderr
+	var f *os.File
+	if path != "" {
+		var err error
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("open log file: %w", err)
+		}
+		w = f
+	}
+
+	slog.SetDefault(slog.New(NewHandler(w, format, &l.level)))
+	if l.file != nil {
+		l.file.Close()
+	}
+	l.file, l.format, l.path, l.ready = f, format, path, true
+	return nil
+}
+
+// Close closes the log file, if any. Logging after Close falls back to stderr.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	slog.SetDefault(slog.New(NewHandler(os.Stderr, l.format, &l.level)))
+	err := l.file.Close()
+	l.file, l.path = nil, ""
+	return err
+}
@@ -1,10 +1,13 @@
 package monitor
 
 import (
+	"fmt"
 	"log/slog"
+	"math"
 	"sync"
 	"time"
 
+	"github.com/makt28/wink/internal/config"
 	"github.com/makt28/wink/internal/notify"
 	"github.com/makt28/wink/internal/storage"
 )
@@ -13,12 +16,32 @@ import (
 type monitorState struct {
 	isUp          bool
 	failCount     int
-	reminderCount int // failures since last alert (used after DOWN)
+	successCount  int       // consecutive successes (used to confirm recovery)
+	successSince  time.Time // first success of the current streak while DOWN (recovery_grace_seconds)
+	reminderCount int       // failures since last alert (used after DOWN)
+
+	anomalyStreak int  // consecutive probes above the latency baseline
+	anomalous     bool // an anomaly alert has been sent and not yet cleared
+
+	slowStreak int  // consecutive successful probes on the other side of slow_threshold_ms
+	slow       bool // a "slow" event has been sent and no "fast" since
+
+	maintenanceUntil time.Time // end of the maintenance window announced by the target (honor_retry_after)
 }
 
+// Latency baseline parameters: the baseline is built from up to anomalyWindow
+// recent successful probes and is only trusted once anomalyMinSamples exist.
+const (
+	anomalyWindow       = 30
+	anomalyMinSamples   = 10
+	defaultAnomalyCount = 3
+	defaultSlowCount    = 3
+)
+
 // AnalyzeResult is returned to the scheduler to allow dynamic interval switching.
 type AnalyzeResult struct {
 	IsFailing bool // true if probe failed (regardless of UP/DOWN state)
+	Skipped   bool // no probe ran or its result was not recorded; keep the current interval
 }
 
 // Analyzer processes probe results, implements flapping control, and triggers notifications.
@@ -27,6 +50,7 @@ type Analyzer struct {
 	states   map[string]*monitorState
 	histMgr  *storage.HistoryManager
 	notifier *notify.Router
+	probes   probeHub // every recorded probe of monitors with probe_events
 }
 
 // NewAnalyzer creates a new Analyzer.
@@ -38,29 +62,94 @@ func NewAnalyzer(histMgr *storage.HistoryManager, notifier *notify.Router) *Anal
 	}
 }
 
-// Process handles a probe result with flapping control and reminder alerts.
-func (a *Analyzer) Process(monitorID, monitorName, target string, maxRetries, reminderInterval int, result ProbeResult) AnalyzeResult {
+// Process handles a probe result with flapping control, reminder alerts and
+// optional latency anomaly detection.
+func (a *Analyzer) Process(m config.Monitor, result ProbeResult) AnalyzeResult {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	monitorID, monitorName, target := m.ID, m.Name, m.TargetLabel()
+	maxRetries, reminderInterval := m.MaxRetries, m.ReminderInterval
+	recoveryThreshold := m.RecoveryThreshold
+	if recoveryThreshold <= 0 {
+		recoveryThreshold = 1
+	}
+	recoveryGrace := time.Duration(m.RecoveryGraceSeconds) * time.Second
+
 	state := a.ensureState(monitorID)
 	latencyMs := int(result.Latency.Milliseconds())
 
-	a.histMgr.RecordProbe(monitorID, latencyMs, result.Up)
+	// Failures inside a maintenance window the target announced are not outages:
+	// they neither count towards max_retries nor alert. The latest announcement wins.
+	if m.HonorRetryAfter && !result.Up {
+		if !result.MaintenanceUntil.IsZero() {
+			if !time.Now().Before(state.maintenanceUntil) {
+				slog.Info("monitor in maintenance", "id", monitorID, "name", monitorName, "until", result.MaintenanceUntil)
+			}
+			state.maintenanceUntil = result.MaintenanceUntil
+		}
+		if time.Now().Before(state.maintenanceUntil) {
+			state.failCount = 0
+			a.histMgr.RecordMaintenance(monitorID, state.maintenanceUntil.Unix())
+			return AnalyzeResult{IsFailing: false}
+		}
+	}
+	state.maintenanceUntil = time.Time{}
+
+	// The baseline must be taken before this probe is recorded.
+	var mean, stddev float64
+	var haveBaseline bool
+	if m.AnomalyK > 0 && result.Up {
+		mean, stddev, haveBaseline = a.latencyBaseline(monitorID, state.anomalyStreak)
+	}
+
+	a.histMgr.RecordProbe(monitorID, latencyMs, m.LatencyCap(), result.Up, result.Error)
+	if m.ProbeEvents {
+		a.probes.publish(ProbeEvent{
+			MonitorID: monitorID,
+			Up:        result.Up,
+			LatencyMs: latencyMs,
+			Error:     result.Error,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+	if t := result.Timing; t != nil {
+		a.histMgr.RecordTiming(monitorID, storage.ProbeTiming{
+			Time:      time.Now().Unix(),
+			DNSMs:     int(t.DNS.Milliseconds()),
+			ConnectMs: int(t.Connect.Milliseconds()),
+			TLSMs:     int(t.TLS.Milliseconds()),
+			TTFBMs:    int(t.TTFB.Milliseconds()),
+		})
+	}
 
 	if result.Up {
 		// --- Success path ---
-		prevDown := !state.isUp
 		state.failCount = 0
-		state.reminderCount = 0
+		state.successCount++
+		if !state.isUp && state.successCount == 1 {
+			state.successSince = time.Now()
+		}
 
-		if prevDown {
+		if !state.isUp && (state.successCount < recoveryThreshold || time.Since(state.successSince) < recoveryGrace ||
+			(m.FailureMode == "window" && a.windowExceeded(m))) {
+			slog.Debug("probe succeeded, awaiting recovery",
+				"id", monitorID,
+				"name", monitorName,
+				"success_count", state.successCount,
+				"recovery_threshold", recoveryThreshold,
+				"succeeding_for", time.Since(state.successSince).Round(time.Second),
+				"recovery_grace", recoveryGrace,
+			)
+		} else if !state.isUp {
+			// Transition: DOWN -> UP
 			state.isUp = true
-			a.histMgr.RecordUp(monitorID)
+			state.reminderCount = 0
+			duration := a.histMgr.RecordUp(monitorID)
 
 			slog.Info("monitor recovered", "id", monitorID, "name", monitorName)
-			if err := a.histMgr.Dump(); err != nil {
-				slog.Error("failed to dump history on recovery", "error", err)
+			if err := a.histMgr.DumpIncidents(); err != nil {
+				slog.Error("failed to dump incidents on recovery", "error", err)
 			}
 
 			a.notifier.Notify(notify.AlertEvent{
@@ -69,13 +158,26 @@ func (a *Analyzer) Process(monitorID, monitorName, target string, maxRetries, re
 				Type:        "up",
 				Target:      target,
 				Timestamp:   time.Now().Unix(),
+
+				ResponseTimeMs:   latencyMs,
+				Uptime24h:        a.uptime24h(monitorID),
+				IncidentDuration: duration,
 			})
 		}
+		if haveBaseline {
+			a.checkAnomaly(m, state, latencyMs, mean, stddev)
+		}
+		if m.SlowThresholdMs > 0 && !m.LatencyCountsAsDown {
+			a.checkSlow(m, state, latencyMs)
+		}
 		return AnalyzeResult{IsFailing: false}
 	}
 
 	// --- Failure path ---
 	state.failCount++
+	state.successCount = 0
+	state.anomalyStreak = 0
+	state.anomalous = false
 
 	slog.Debug("probe failed",
 		"id", monitorID,
@@ -85,15 +187,15 @@ func (a *Analyzer) Process(monitorID, monitorName, target string, maxRetries, re
 		"error", result.Error,
 	)
 
-	if state.isUp && state.failCount >= maxRetries {
+	if state.isUp && a.shouldGoDown(m, state) {
 		// Transition: UP -> DOWN (initial alert)
 		state.isUp = false
 		state.reminderCount = 0
-		a.histMgr.RecordDown(monitorID, result.Error)
+		a.histMgr.RecordDown(monitorID, result.Error, result.Category, string(result.Kind))
 
 		slog.Warn("monitor is DOWN", "id", monitorID, "name", monitorName, "reason", result.Error)
-		if err := a.histMgr.Dump(); err != nil {
-			slog.Error("failed to dump history on down", "error", err)
+		if err := a.histMgr.DumpIncidents(); err != nil {
+			slog.Error("failed to dump incidents on down", "error", err)
 		}
 
 		a.notifier.Notify(notify.AlertEvent{
@@ -102,7 +204,11 @@ func (a *Analyzer) Process(monitorID, monitorName, target string, maxRetries, re
 			Type:        "down",
 			Target:      target,
 			Reason:      result.Error,
+			ErrorKind:   string(result.Kind),
 			Timestamp:   time.Now().Unix(),
+
+			ResponseTimeMs: latencyMs,
+			Uptime24h:      a.uptime24h(monitorID),
 		})
 	} else if !state.isUp && reminderInterval > 0 {
 		// Already DOWN: check if we should resend alert
@@ -117,7 +223,12 @@ func (a *Analyzer) Process(monitorID, monitorName, target string, maxRetries, re
 				Type:        "down",
 				Target:      target,
 				Reason:      result.Error,
+				ErrorKind:   string(result.Kind),
 				Timestamp:   time.Now().Unix(),
+				Reminder:    true,
+
+				ResponseTimeMs: latencyMs,
+				Uptime24h:      a.uptime24h(monitorID),
 			})
 		}
 	}
@@ -125,6 +236,163 @@ func (a *Analyzer) Process(monitorID, monitorName, target string, maxRetries, re
 	return AnalyzeResult{IsFailing: true}
 }
 
+// shouldGoDown reports whether a failing UP monitor is now DOWN under its
+// failure_mode.
+func (a *Analyzer) shouldGoDown(m config.Monitor, state *monitorState) bool {
+	if m.FailureMode == "window" {
+		return a.windowExceeded(m)
+	}
+	return state.failCount >= m.MaxRetries
+}
+
+// windowExceeded reports whether more than window_threshold_pct percent of the
+// monitor's last window_size recorded probes failed. Probes it doesn't have
+// yet count as successes, so a new monitor needs as many failures as a full
+// window would. A DOWN monitor stays down until the share is back under it.
+func (a *Analyzer) windowExceeded(m config.Monitor) bool {
+	h := a.histMgr.GetMonitor(m.ID)
+	if h == nil {
+		return false
+	}
+	points := h.LatencyHistory
+	failed := 0
+	for i := len(points) - 1; i >= 0 && i >= len(points)-m.WindowSize; i-- {
+		if !points[i].Up {
+			failed++
+		}
+	}
+	return failed*100 > m.WindowThresholdPct*m.WindowSize
+}
+
+// checkAnomaly flags a latency anomaly once latency has stayed above
+// mean + k·stddev for the configured number of consecutive probes. One alert is
+// sent per anomaly; the streak resets as soon as latency is back in range.
+func (a *Analyzer) checkAnomaly(m config.Monitor, state *monitorState, latencyMs int, mean, stddev float64) {
+	// A perfectly flat baseline would make any jitter anomalous.
+	if stddev < 1 {
+		stddev = 1
+	}
+	threshold := mean + m.AnomalyK*stddev
+	if float64(latencyMs) <= threshold {
+		state.anomalyStreak = 0
+		state.anomalous = false
+		return
+	}
+
+	state.anomalyStreak++
+	count := m.AnomalyCount
+	if count <= 0 {
+		count = defaultAnomalyCount
+	}
+	if state.anomalyStreak < count || state.anomalous {
+		return
+	}
+	state.anomalous = true
+
+	reason := fmt.Sprintf("latency %dms above baseline %.0f±%.0fms (k=%g) for %d checks",
+		latencyMs, mean, stddev, m.AnomalyK, state.anomalyStreak)
+	slog.Warn("latency anomaly", "id", m.ID, "name", m.Name, "latency_ms", latencyMs, "threshold_ms", math.Round(threshold))
+	a.notifier.Notify(notify.AlertEvent{
+		MonitorID:   m.ID,
+		MonitorName: m.Name,
+		Type:        "anomaly",
+		Target:      m.TargetLabel(),
+		Reason:      reason,
+		Timestamp:   time.Now().Unix(),
+
+		ResponseTimeMs: latencyMs,
+		Uptime24h:      a.uptime24h(m.ID),
+	})
+}
+
+// checkSlow sends a "slow" event once latency has exceeded slow_threshold_ms
+// for slow_count consecutive successful probes, and a "fast" event once it has
+// been back under it for as many. Failed probes have no latency and are not
+// counted, so this never interferes with the down/up path.
+func (a *Analyzer) checkSlow(m config.Monitor, state *monitorState, latencyMs int) {
+	if (latencyMs > m.SlowThresholdMs) == state.slow {
+		state.slowStreak = 0
+		return
+	}
+
+	state.slowStreak++
+	count := m.SlowCount
+	if count <= 0 {
+		count = defaultSlowCount
+	}
+	if state.slowStreak < count {
+		return
+	}
+	state.slowStreak = 0
+	state.slow = !state.slow
+
+	eventType := "fast"
+	reason := fmt.Sprintf("latency %dms back under %dms for %d checks", latencyMs, m.SlowThresholdMs, count)
+	if state.slow {
+		eventType = "slow"
+		reason = fmt.Sprintf("latency %dms over %dms for %d checks", latencyMs, m.SlowThresholdMs, count)
+	}
+	slog.Warn("latency threshold crossed", "id", m.ID, "name", m.Name, "event", eventType, "latency_ms", latencyMs, "threshold_ms", m.SlowThresholdMs)
+	a.notifier.Notify(notify.AlertEvent{
+		MonitorID:   m.ID,
+		MonitorName: m.Name,
+		Type:        eventType,
+		Target:      m.TargetLabel(),
+		Reason:      reason,
+		Timestamp:   time.Now().Unix(),
+
+		ResponseTimeMs: latencyMs,
+		Uptime24h:      a.uptime24h(m.ID),
+	})
+}
+
+// latencyBaseline returns the mean and standard deviation of recent successful
+// probe latencies, skipping the newest skip points so an ongoing anomaly does not
+// inflate its own baseline. ok is false until enough samples exist.
+func (a *Analyzer) latencyBaseline(monitorID string, skip int) (mean, stddev float64, ok bool) {
+	h := a.histMgr.GetMonitor(monitorID)
+	if h == nil {
+		return 0, 0, false
+	}
+	samples := make([]float64, 0, anomalyWindow)
+	for i := len(h.LatencyHistory) - 1; i >= 0 && len(samples) < anomalyWindow; i-- {
+		if p := h.LatencyHistory[i]; p.Up {
+			if skip > 0 {
+				skip--
+				continue
+			}
+			samples = append(samples, float64(p.Latency))
+		}
+	}
+	if len(samples) < anomalyMinSamples {
+		return 0, 0, false
+	}
+	for _, v := range samples {
+		mean += v
+	}
+	mean /= float64(len(samples))
+	for _, v := range samples {
+		stddev += (v - mean) * (v - mean)
+	}
+	stddev = math.Sqrt(stddev / float64(len(samples)))
+	return mean, stddev, true
+}
+
+// HasSucceeded reports whether the monitor's retained history contains at least
+// one successful probe.
+func (a *Analyzer) HasSucceeded(monitorID string) bool {
+	h := a.histMgr.GetMonitor(monitorID)
+	if h == nil {
+		return false
+	}
+	for i := len(h.LatencyHistory) - 1; i >= 0; i-- {
+		if h.LatencyHistory[i].Up {
+			return true
+		}
+	}
+	return false
+}
+
 // RemoveState cleans up state for a removed monitor.
 func (a *Analyzer) RemoveState(monitorID string) {
 	a.mu.Lock()
@@ -132,21 +400,69 @@ func (a *Analyzer) RemoveState(monitorID string) {
 	delete(a.states, monitorID)
 }
 
+// RemoveMonitor drops a deleted monitor's state together with its history and
+// incidents. Holding a.mu orders it after any Process call already recording.
+func (a *Analyzer) RemoveMonitor(monitorID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.states, monitorID)
+	a.histMgr.RemoveMonitor(monitorID)
+}
+
+// Flush writes each monitor's confirmed up/down state to history, so the final
+// dump on shutdown restores the state flapping control settled on rather than
+// the last raw probe.
+func (a *Analyzer) Flush() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for id, st := range a.states {
+		a.histMgr.SetIsUp(id, st.isUp)
+	}
+}
+
+// MonitorState is a snapshot of a monitor's flapping-control state.
+type MonitorState struct {
+	IsUp      bool
+	FailCount int
+}
+
+// State returns the current state of a monitor, or false if it has none yet.
+func (a *Analyzer) State(monitorID string) (MonitorState, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	st, ok := a.states[monitorID]
+	if !ok {
+		return MonitorState{}, false
+	}
+	return MonitorState{IsUp: st.isUp, FailCount: st.failCount}, true
+}
+
+// uptime24h returns the current 24h uptime for a monitor (100 if no history yet).
+func (a *Analyzer) uptime24h(monitorID string) float64 {
+	if h := a.histMgr.GetMonitor(monitorID); h != nil {
+		return math.Round(h.Uptime24h*100) / 100
+	}
+	return 100.0
+}
+
 func (a *Analyzer) ensureState(id string) *monitorState {
 	s, ok := a.states[id]
 	if !ok {
-		isUp := true
+		s = &monitorState{isUp: true}
 		// Restore state from persisted incidents: if there is an unresolved
-		// incident, the monitor was DOWN before the process restarted.
+		// incident, the monitor was DOWN before the process restarted. A
+		// maintenance window carries over too.
 		if h := a.histMgr.GetMonitor(id); h != nil {
 			for _, inc := range h.Incidents {
 				if inc.ResolvedAt == nil {
-					isUp = false
+					s.isUp = false
 					break
 				}
 			}
+			if h.MaintenanceUntil > 0 {
+				s.maintenanceUntil = time.Unix(h.MaintenanceUntil, 0)
+			}
 		}
-		s = &monitorState{isUp: isUp}
 		a.states[id] = s
 	}
 	return s
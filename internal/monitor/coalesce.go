@@ -0,0 +1,169 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/makt28/wink/internal/config"
+)
+
+// coalescedProbe is one shared probe run. done is closed once result is set.
+type coalescedProbe struct {
+	done   chan struct{}
+	result ProbeResult
+	ok     bool // false when the leading probe was aborted; waiters probe on their own
+	at     time.Time
+}
+
+// probeCoalescer lets monitors with identical probe settings share one probe:
+// a probe that starts while another is in flight, or within the window after
+// it finished, reuses that result instead of opening its own connection.
+type probeCoalescer struct {
+	mu      sync.Mutex
+	entries map[string]*coalescedProbe
+}
+
+func newProbeCoalescer() *probeCoalescer {
+	return &probeCoalescer{entries: make(map[string]*coalescedProbe)}
+}
+
+// probeKeyFields are the monitor settings that decide what a probe does on the
+// wire and how its result comes out: the request itself and the assertions
+// NewProber and probeTargets apply to the response. It is an allowlist, so a
+// new setting that only affects alerting or scheduling doesn't stop identical
+// probes from being shared; one that changes the probe must be added here.
+type probeKeyFields struct {
+	Type     string   `json:"type"`
+	Target   string   `json:"target"`
+	Targets  []string `json:"targets,omitempty"`
+	UpPolicy string   `json:"up_policy,omitempty"`
+	Timeout  int      `json:"timeout"`
+	SourceIP string   `json:"source_ip,omitempty"`
+
+	Method        string `json:"method,omitempty"`
+	UserAgent     string `json:"user_agent,omitempty"`
+	HostHeader    string `json:"host_header,omitempty"`
+	IgnoreTLS     bool   `json:"ignore_tls,omitempty"`
+	MinTLSVersion string `json:"min_tls_version,omitempty"`
+	RequireHTTP2  bool   `json:"require_http2,omitempty"`
+	TraceTiming   bool   `json:"trace_timing,omitempty"`
+
+	JSONPath          string `json:"json_path,omitempty"`
+	JSONExpected      string `json:"json_expected,omitempty"`
+	MinBytes          int    `json:"min_bytes,omitempty"`
+	MaxBytes          int    `json:"max_bytes,omitempty"`
+	ExpectContentType string `json:"expect_content_type,omitempty"`
+	HonorRetryAfter   bool   `json:"honor_retry_after,omitempty"`
+	MaxLatencyMs      int    `json:"max_latency_ms,omitempty"` // slow_threshold_ms in latency_counts_as_down mode
+
+	SendData    string `json:"send_data,omitempty"`
+	ExpectData  string `json:"expect_data,omitempty"`
+	ExpectRegex bool   `json:"expect_regex,omitempty"`
+
+	SMTPStartTLS      bool `json:"smtp_starttls,omitempty"`
+	WSPing            bool `json:"ws_ping,omitempty"`
+	PingCount         int  `json:"ping_count,omitempty"`
+	PingLossThreshold int  `json:"ping_loss_threshold,omitempty"`
+}
+
+// probeKey identifies what a probe of m actually does. Two monitors share a
+// result only if all of their probeKeyFields match.
+func probeKey(m config.Monitor) string {
+	k := probeKeyFields{
+		Type:     m.Type,
+		Target:   m.Target,
+		Targets:  m.Targets,
+		UpPolicy: m.UpPolicy,
+		Timeout:  m.Timeout,
+		SourceIP: m.SourceIP,
+
+		Method:        m.Method,
+		UserAgent:     m.UserAgent,
+		HostHeader:    m.HostHeader,
+		IgnoreTLS:     m.IgnoreTLS,
+		MinTLSVersion: m.MinTLSVersion,
+		RequireHTTP2:  m.RequireHTTP2,
+		TraceTiming:   m.TraceTiming,
+
+		JSONPath:          m.JSONPath,
+		JSONExpected:      m.JSONExpected,
+		MinBytes:          m.MinBytes,
+		MaxBytes:          m.MaxBytes,
+		ExpectContentType: m.ExpectContentType,
+		HonorRetryAfter:   m.HonorRetryAfter,
+
+		SendData:    m.SendData,
+		ExpectData:  m.ExpectData,
+		ExpectRegex: m.ExpectRegex,
+
+		SMTPStartTLS:      m.SMTPStartTLS,
+		WSPing:            m.WSPing,
+		PingCount:         m.PingCount,
+		PingLossThreshold: m.PingLossThreshold,
+	}
+	if m.LatencyCountsAsDown {
+		k.MaxLatencyMs = m.SlowThresholdMs
+	}
+	b, _ := json.Marshal(k)
+	return string(b)
+}
+
+// do returns a result for key, running probe only when no shared result is in
+// flight or younger than window. ctx is the probe context: waiting is bounded by
+// its deadline, and a leading probe cancelled before it finished (the monitor
+// was stopped) is not shared. The bool reports whether the result was reused.
+func (c *probeCoalescer) do(ctx context.Context, key string, window time.Duration, probe func() ProbeResult) (ProbeResult, bool) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		select {
+		case <-e.done:
+			if e.ok && time.Since(e.at) < window {
+				c.mu.Unlock()
+				return e.result, true
+			}
+		default:
+			c.mu.Unlock()
+			select {
+			case <-e.done:
+				if e.ok {
+					return e.result, true
+				}
+			case <-ctx.Done():
+				return ProbeResult{Up: false, Kind: classifyError(ctx.Err()), Error: ctx.Err().Error()}, false
+			}
+			return probe(), false
+		}
+	}
+	e := &coalescedProbe{done: make(chan struct{})}
+	c.entries[key] = e
+	c.pruneLocked(window)
+	c.mu.Unlock()
+
+	result := probe()
+
+	c.mu.Lock()
+	e.result, e.at = result, time.Now()
+	e.ok = !errors.Is(ctx.Err(), context.Canceled)
+	if !e.ok && c.entries[key] == e {
+		delete(c.entries, key)
+	}
+	close(e.done)
+	c.mu.Unlock()
+	return result, false
+}
+
+// pruneLocked drops finished entries older than window. c.mu must be held.
+func (c *probeCoalescer) pruneLocked(window time.Duration) {
+	for k, e := range c.entries {
+		select {
+		case <-e.done:
+			if time.Since(e.at) >= window {
+				delete(c.entries, k)
+			}
+		default:
+		}
+	}
+}
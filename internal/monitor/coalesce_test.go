@@ -0,0 +1,73 @@
+package monitor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/makt28/wink/internal/config"
+)
+
+func TestProbeKeyIgnoresAlertingSettings(t *testing.T) {
+	base := config.Monitor{ID: "a", Name: "api", Type: "http", Target: "https://example.com/health", Timeout: 10}
+
+	same := base
+	same.ID, same.Name, same.Interval, same.MaxRetries = "b", "api copy", 30, 5
+	same.FailureMode, same.WindowSize, same.WindowThresholdPct = "window", 20, 50
+	same.ProbeEvents, same.SLATarget, same.RecoveryGraceSeconds = true, 99.9, 60
+	same.SlowThresholdMs = 500 // only a "slow" event without latency_counts_as_down
+	if probeKey(base) != probeKey(same) {
+		t.Error("monitors differing only in alerting settings got different keys")
+	}
+
+	for name, change := range map[string]func(*config.Monitor){
+		"target":      func(m *config.Monitor) { m.Target = "https://example.com/other" },
+		"timeout":     func(m *config.Monitor) { m.Timeout = 5 },
+		"method":      func(m *config.Monitor) { m.Method = "HEAD" },
+		"host header": func(m *config.Monitor) { m.HostHeader = "api.example.com" },
+		"ignore tls":  func(m *config.Monitor) { m.IgnoreTLS = true },
+		"source ip":   func(m *config.Monitor) { m.SourceIP = "192.0.2.10" },
+		"max latency": func(m *config.Monitor) { m.LatencyCountsAsDown, m.SlowThresholdMs = true, 500 },
+		"json path":   func(m *config.Monitor) { m.JSONPath = "status" },
+	} {
+		m := base
+		change(&m)
+		if probeKey(base) == probeKey(m) {
+			t.Errorf("changing the %s kept the same key", name)
+		}
+	}
+}
+
+// notProbeSettings are the Monitor fields probeKey leaves out because they
+// don't change what a probe does or how its result comes out.
+var notProbeSettings = map[string]bool{
+	"id": true, "name": true, "group_id": true, "public": true, "timezone": true,
+	"interval": true, "max_retries": true, "retry_interval": true, "reminder_interval": true,
+	"retry_hold": true, "recovery_threshold": true, "recovery_grace_seconds": true,
+	"anomaly_k": true, "anomaly_count": true, "slow_count": true, "latency_cap_ms": true,
+	"failure_mode": true, "window_size": true, "window_threshold_pct": true,
+	"sla_target": true, "probe_events": true, "enabled": true, "notifier_ids": true,
+	"created_at": true, "updated_at": true,
+	// Folded into max_latency_ms.
+	"slow_threshold_ms": true, "latency_counts_as_down": true,
+}
+
+func TestProbeKeyCoversMonitorFields(t *testing.T) {
+	keyed := make(map[string]bool)
+	kt := reflect.TypeOf(probeKeyFields{})
+	for i := 0; i < kt.NumField(); i++ {
+		keyed[jsonName(kt.Field(i))] = true
+	}
+	mt := reflect.TypeOf(config.Monitor{})
+	for i := 0; i < mt.NumField(); i++ {
+		name := jsonName(mt.Field(i))
+		if !keyed[name] && !notProbeSettings[name] {
+			t.Errorf("monitor field %q is neither in probeKeyFields nor listed as not a probe setting", name)
+		}
+	}
+}
+
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return name
+}
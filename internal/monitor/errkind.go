@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/textproto"
+	"os/exec"
+	"syscall"
+)
+
+// ErrorKind is the machine-readable cause of a failed probe, set by the prober
+// next to the human-readable ProbeResult.Error. Unlike incident categories it
+// is not derived from the error text, so it can be filtered on reliably.
+type ErrorKind string
+
+const (
+	KindTimeout     ErrorKind = "timeout"      // no answer in time, or slower than the latency threshold
+	KindDNS         ErrorKind = "dns"          // the host name did not resolve
+	KindConnRefused ErrorKind = "conn_refused" // nothing listening on the port
+	KindTLS         ErrorKind = "tls"          // handshake or certificate failure
+	KindHTTPStatus  ErrorKind = "http_status"  // an HTTP error status, or the wrong one for the protocol
+	KindProtocol    ErrorKind = "protocol"     // the peer answered, but not as expected (assertions, SMTP, WebSocket)
+	KindUnknown     ErrorKind = "unknown"
+)
+
+// classifyError maps a network error to its kind. TLS failures win over
+// timeouts, as they do for incident categories.
+func classifyError(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+	var (
+		dnsErr     *net.DNSError
+		verifyErr  *tls.CertificateVerificationError
+		recordErr  tls.RecordHeaderError
+		alertErr   tls.AlertError
+		authErr    x509.UnknownAuthorityError
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+		protoErr   *textproto.Error
+		netErr     net.Error
+	)
+	switch {
+	case errors.As(err, &dnsErr):
+		return KindDNS
+	case errors.As(err, &verifyErr), errors.As(err, &recordErr), errors.As(err, &alertErr),
+		errors.As(err, &authErr), errors.As(err, &hostErr), errors.As(err, &invalidErr):
+		return KindTLS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return KindConnRefused
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return KindTimeout
+	case errors.As(err, &protoErr):
+		return KindProtocol
+	}
+	return KindUnknown
+}
+
+// classifyExchangeError is classifyError for failures in the middle of a
+// protocol exchange, where anything that is not a network error means the peer
+// did not speak the protocol.
+func classifyExchangeError(err error) ErrorKind {
+	if kind := classifyError(err); kind != KindUnknown {
+		return kind
+	}
+	return KindProtocol
+}
+
+// classifyPingError maps a failed ping command to its kind. ping exits with
+// status 1 when no reply came back in time; other failures are reported as is.
+func classifyPingError(err error) ErrorKind {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return KindTimeout
+	}
+	return classifyError(err)
+}
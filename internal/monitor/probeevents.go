@@ -0,0 +1,173 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/makt28/wink/internal/buildinfo"
+)
+
+// ProbeEvent is the result of a single probe of a monitor with probe_events on
+// (or system.probe_events), as published to subscribers.
+type ProbeEvent struct {
+	MonitorID string `json:"monitor_id"`
+	Up        bool   `json:"up"`
+	LatencyMs int    `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+	Timestamp int64  `json:"timestamp"` // unix seconds
+}
+
+// probeEventBuffer is how many events a subscriber may fall behind by before
+// further ones are dropped for it.
+const probeEventBuffer = 256
+
+// Probe webhook batching: published events are collected and posted at most
+// every probeWebhookFlush, or sooner once probeWebhookBatch are pending.
+const (
+	probeWebhookFlush = time.Second
+	probeWebhookBatch = 500
+)
+
+// ProbeSubscription receives published probe events until it is closed. A
+// subscriber that doesn't keep up loses events rather than slowing probes down.
+type ProbeSubscription struct {
+	C <-chan ProbeEvent
+
+	ch      chan ProbeEvent
+	dropped atomic.Int64
+	hub     *probeHub
+}
+
+// Dropped returns how many events were lost since the previous call because
+// the subscriber fell behind.
+func (ps *ProbeSubscription) Dropped() int64 {
+	return ps.dropped.Swap(0)
+}
+
+// Close unsubscribes. The channel is not closed, so a pending receive must
+// also watch for its own stop condition.
+func (ps *ProbeSubscription) Close() {
+	ps.hub.mu.Lock()
+	delete(ps.hub.subs, ps)
+	ps.hub.mu.Unlock()
+}
+
+// probeHub fans probe events out to subscribers without blocking the publisher.
+type probeHub struct {
+	mu   sync.Mutex
+	subs map[*ProbeSubscription]struct{}
+}
+
+func (h *probeHub) subscribe() *ProbeSubscription {
+	ch := make(chan ProbeEvent, probeEventBuffer)
+	ps := &ProbeSubscription{C: ch, ch: ch, hub: h}
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[*ProbeSubscription]struct{})
+	}
+	h.subs[ps] = struct{}{}
+	h.mu.Unlock()
+	return ps
+}
+
+func (h *probeHub) publish(ev ProbeEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ps := range h.subs {
+		select {
+		case ps.ch <- ev:
+		default:
+			ps.dropped.Add(1)
+		}
+	}
+}
+
+// SubscribeProbes returns a subscription to the probe events of monitors with
+// probe_events on. Close it when done.
+func (s *Scheduler) SubscribeProbes() *ProbeSubscription {
+	return s.analyzer.probes.subscribe()
+}
+
+// forwardProbes posts published probe events to system.probe_webhook_url in
+// batches until the scheduler stops. Events published while no URL is set
+// are discarded.
+func (s *Scheduler) forwardProbes() {
+	defer s.wg.Done()
+	sub := s.SubscribeProbes()
+	defer sub.Close()
+
+	// A delivery in flight is abandoned on stop rather than holding it up.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(probeWebhookFlush)
+	defer ticker.Stop()
+	var batch []ProbeEvent
+	flush := func() {
+		sys := s.cfgMgr.Get().System
+		if n := sub.Dropped(); n > 0 && sys.ProbeWebhookURL != "" {
+			slog.Warn("probe webhook fell behind, events dropped", "dropped", n)
+		}
+		if len(batch) == 0 || sys.ProbeWebhookURL == "" {
+			batch = batch[:0]
+			return
+		}
+		if err := postProbeEvents(ctx, sys.ProbeWebhookURL, batch, sys.NotifyTimeout()); err != nil {
+			slog.Warn("probe webhook delivery failed", "events", len(batch), "error", err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case ev := <-sub.C:
+			batch = append(batch, ev)
+			if len(batch) >= probeWebhookBatch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// postProbeEvents sends one batch as {"events": [...]}; the URL is validated
+// on save.
+func postProbeEvents(ctx context.Context, url string, events []ProbeEvent, timeout time.Duration) error {
+	body, err := json.Marshal(map[string]interface{}{"events": events})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", buildinfo.UserAgent)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
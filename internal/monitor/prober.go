@@ -1,23 +1,59 @@
 package monitor
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/sha1"
 	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/smtp"
+	"net/url"
 	"os/exec"
 	"regexp"
 	"runtime"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
+
+	"github.com/makt28/wink/internal/buildinfo"
+	"github.com/makt28/wink/internal/config"
+	"github.com/makt28/wink/internal/jsonpath"
 )
 
 // ProbeResult is the outcome of a single probe attempt.
 type ProbeResult struct {
-	Up      bool
-	Latency time.Duration
-	Error   string
+	Up       bool
+	Latency  time.Duration
+	Error    string    // human-readable failure, for display
+	Kind     ErrorKind // machine-readable cause of a failure; set by the prober
+	Category string    // cause of a failure for incident grouping; set by the scheduler (see CategorizeReason)
+
+	// MaintenanceUntil is set when the target announced planned maintenance
+	// (a 503 with Retry-After, see HTTPProber.HonorRetryAfter) lasting until then.
+	MaintenanceUntil time.Time
+
+	Timing *HTTPTiming // phase breakdown of a traced HTTP probe (HTTPProber.Trace); nil otherwise
+}
+
+// HTTPTiming breaks an HTTP probe down into its phases. Phases the probe never
+// reached are zero, and the phases of redirected requests add up.
+type HTTPTiming struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	TTFB    time.Duration // from the request being written to the first response byte
 }
 
 // Prober is the interface for all probe type implementations.
@@ -28,20 +64,75 @@ type Prober interface {
 // --- HTTP Prober ---
 
 type HTTPProber struct {
-	IgnoreTLS bool
+	Method     string // empty = GET; HEAD skips the body assertions (rejected on save)
+	IgnoreTLS  bool
+	UserAgent  string // empty = buildinfo.UserAgent
+	HostHeader string // overrides the Host header and TLS SNI when set
+
+	JSONPath     jsonpath.Path // when set, the JSON body value at this path must equal JSONExpected
+	JSONExpected string
+
+	MinBytes int // when > 0, a shorter response body marks the probe down
+	MaxBytes int // when > 0, a longer response body marks the probe down
+
+	ExpectContentType string // when set, the response media type must start with it (case-insensitive)
+
+	MaxLatency time.Duration // when > 0, a slower response (time to headers) marks the probe down
+
+	HonorRetryAfter bool // a 503 with Retry-After reports a maintenance window (ProbeResult.MaintenanceUntil)
+
+	MinTLSVersion uint16 // when set, an older negotiated TLS version marks the probe down (skipped with IgnoreTLS)
+	RequireHTTP2  bool   // when set, a response over HTTP/1.x marks the probe down
+
+	SourceIP net.IP // local address to connect from; nil = OS default
+
+	Trace bool // fill ProbeResult.Timing
 }
 
+// maxJSONBody caps how much of an HTTP response is read for a JSON assertion.
+const maxJSONBody = 1 << 20
+
 func (p *HTTPProber) Probe(ctx context.Context, target string) ProbeResult {
+	if !p.Trace {
+		return p.probe(ctx, target)
+	}
+	timer := &httpTimer{connectStart: make(map[string]time.Time)}
+	result := p.probe(httptrace.WithClientTrace(ctx, timer.clientTrace()), target)
+	timer.mu.Lock()
+	timing := timer.timing
+	timer.mu.Unlock()
+	result.Timing = &timing
+	return result
+}
+
+func (p *HTTPProber) probe(ctx context.Context, target string) ProbeResult {
 	start := time.Now()
 
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: p.IgnoreTLS},
+	tlsCfg := &tls.Config{InsecureSkipVerify: p.IgnoreTLS, RootCAs: rootCAs()}
+	if p.HostHeader != "" {
+		// Use the virtual host for SNI so probing by IP still gets the right certificate.
+		tlsCfg.ServerName = hostOnly(p.HostHeader)
 	}
+	transport := &http.Transport{TLSClientConfig: tlsCfg, DialContext: newDialer(p.SourceIP).DialContext}
+	// A custom TLS config or dialer turns HTTP/2 off unless it is asked for.
+	transport.ForceAttemptHTTP2 = p.RequireHTTP2
 	client := &http.Client{Transport: transport}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	method := p.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
 	if err != nil {
-		return ProbeResult{Up: false, Error: fmt.Sprintf("create request: %v", err)}
+		return ProbeResult{Up: false, Kind: KindUnknown, Error: fmt.Sprintf("create request: %v", redactURLError(err))}
+	}
+	ua := p.UserAgent
+	if ua == "" {
+		ua = buildinfo.UserAgent
+	}
+	req.Header.Set("User-Agent", ua)
+	if p.HostHeader != "" {
+		req.Host = p.HostHeader
 	}
 
 	resp, err := client.Do(req)
@@ -49,47 +140,596 @@ func (p *HTTPProber) Probe(ctx context.Context, target string) ProbeResult {
 		return ProbeResult{
 			Up:      false,
 			Latency: time.Since(start),
-			Error:   fmt.Sprintf("request failed: %v", err),
+			Kind:    classifyError(err),
+			Error:   fmt.Sprintf("request failed: %v", redactURLError(err)),
 		}
 	}
 	defer resp.Body.Close()
 	latency := time.Since(start)
 
 	if resp.StatusCode >= 400 {
-		return ProbeResult{
+		result := ProbeResult{
 			Up:      false,
 			Latency: latency,
+			Kind:    KindHTTPStatus,
 			Error:   fmt.Sprintf("HTTP %d", resp.StatusCode),
 		}
+		if p.HonorRetryAfter && resp.StatusCode == http.StatusServiceUnavailable {
+			if until, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				result.MaintenanceUntil = until
+				result.Error += " (maintenance until " + until.UTC().Format(time.RFC3339) + ")"
+			}
+		}
+		return result
+	}
+
+	if kind, msg := p.checkProtocol(resp); msg != "" {
+		return ProbeResult{Up: false, Latency: latency, Kind: kind, Error: msg}
+	}
+
+	if p.ExpectContentType != "" {
+		if msg := p.checkContentType(resp.Header.Get("Content-Type")); msg != "" {
+			return ProbeResult{Up: false, Latency: latency, Kind: KindProtocol, Error: msg}
+		}
+	}
+
+	if method != http.MethodHead && (p.JSONPath != nil || p.MinBytes > 0 || p.MaxBytes > 0) {
+		if msg := p.checkBody(resp.Body); msg != "" {
+			return ProbeResult{Up: false, Latency: latency, Kind: KindProtocol, Error: msg}
+		}
+	}
+
+	if p.MaxLatency > 0 && latency > p.MaxLatency {
+		return ProbeResult{Up: false, Latency: latency, Kind: KindTimeout, Error: fmt.Sprintf("slow (>%dms)", p.MaxLatency.Milliseconds())}
 	}
 
 	return ProbeResult{Up: true, Latency: latency}
 }
 
+// redactURLError masks credentials in the URL a *url.Error carries. The
+// standard library strips the userinfo password but keeps the query, and the
+// error text ends up in incidents, alerts and probe events.
+func redactURLError(err error) error {
+	var uerr *url.Error
+	if !errors.As(err, &uerr) {
+		return err
+	}
+	redacted := *uerr
+	redacted.URL = config.RedactTarget(uerr.URL)
+	return &redacted
+}
+
+// httpTimer collects an HTTPTiming from httptrace hooks, which may run on
+// other goroutines (DNS lookups and parallel dials).
+type httpTimer struct {
+	mu           sync.Mutex
+	timing       HTTPTiming
+	dnsStart     time.Time
+	connectStart map[string]time.Time // keyed by address: dual-stack dials race
+	tlsStart     time.Time
+	wroteRequest time.Time
+}
+
+func (t *httpTimer) clientTrace() *httptrace.ClientTrace {
+	// record adds the time since start to d, once per started phase.
+	record := func(start *time.Time, d *time.Duration) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if !start.IsZero() {
+			*d += time.Since(*start)
+			*start = time.Time{}
+		}
+	}
+	mark := func(start *time.Time) {
+		t.mu.Lock()
+		*start = time.Now()
+		t.mu.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { mark(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { record(&t.dnsStart, &t.timing.DNS) },
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			t.connectStart[network+" "+addr] = time.Now()
+			t.mu.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			start, ok := t.connectStart[network+" "+addr]
+			delete(t.connectStart, network+" "+addr)
+			// Only the dial that won counts; losers of a dual-stack race fail.
+			if ok && err == nil {
+				t.timing.Connect += time.Since(start)
+			}
+		},
+		TLSHandshakeStart:    func() { mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { record(&t.tlsStart, &t.timing.TLS) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { mark(&t.wroteRequest) },
+		GotFirstResponseByte: func() { record(&t.wroteRequest, &t.timing.TTFB) },
+	}
+}
+
+// parseRetryAfter returns the time a Retry-After header value points at, in
+// either its delay-seconds or HTTP-date form, capped at config.MaxRetryAfter from
+// now. It reports false for a missing or malformed value and for one already past.
+func parseRetryAfter(v string, now time.Time) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, false
+	}
+	limit := now.Add(config.MaxRetryAfter)
+	var until time.Time
+	// An out-of-range delay parses as the largest (or smallest) value and is capped.
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil || errors.Is(err, strconv.ErrRange) {
+		if secs <= 0 {
+			return time.Time{}, false
+		}
+		until = limit
+		if secs < int64(config.MaxRetryAfter/time.Second) {
+			until = now.Add(time.Duration(secs) * time.Second)
+		}
+	} else {
+		t, err := http.ParseTime(v)
+		if err != nil || !t.After(now) {
+			return time.Time{}, false
+		}
+		until = t
+		if until.After(limit) {
+			until = limit
+		}
+	}
+	return until, true
+}
+
+// checkProtocol checks the negotiated TLS and HTTP versions against
+// MinTLSVersion and RequireHTTP2 and returns a failure, or "" when they pass.
+func (p *HTTPProber) checkProtocol(resp *http.Response) (ErrorKind, string) {
+	if p.MinTLSVersion != 0 && !p.IgnoreTLS {
+		// resp.TLS is that of the final response, after any redirects.
+		if resp.TLS == nil {
+			return KindTLS, fmt.Sprintf("tls version: response was not encrypted, expected at least %s", tls.VersionName(p.MinTLSVersion))
+		}
+		if resp.TLS.Version < p.MinTLSVersion {
+			return KindTLS, fmt.Sprintf("tls version: negotiated %s, expected at least %s",
+				tls.VersionName(resp.TLS.Version), tls.VersionName(p.MinTLSVersion))
+		}
+	}
+	if p.RequireHTTP2 && resp.ProtoMajor < 2 {
+		return KindProtocol, fmt.Sprintf("http2: server answered over %s, expected HTTP/2", resp.Proto)
+	}
+	return "", ""
+}
+
+// checkContentType compares the media type of a Content-Type header, without
+// parameters such as charset, against ExpectContentType and returns a failure
+// message, or "" when it matches.
+func (p *HTTPProber) checkContentType(header string) string {
+	if header == "" {
+		return fmt.Sprintf("content-type: response has no Content-Type, expected %s", p.ExpectContentType)
+	}
+	media, _, _ := strings.Cut(header, ";")
+	media = strings.ToLower(strings.TrimSpace(media))
+	if !strings.HasPrefix(media, strings.ToLower(p.ExpectContentType)) {
+		return fmt.Sprintf("content-type: got %s, expected %s", media, p.ExpectContentType)
+	}
+	return ""
+}
+
+// checkBody reads the response body once for the size and JSON assertions and
+// returns a failure message, or "" when all of them pass. Only the prefix the
+// JSON check needs is kept in memory; the rest is counted and discarded.
+func (p *HTTPProber) checkBody(body io.Reader) string {
+	var keep int64
+	if p.JSONPath != nil {
+		keep = maxJSONBody + 1
+	}
+	limit := keep
+	if p.MaxBytes > 0 && int64(p.MaxBytes)+1 > limit {
+		limit = int64(p.MaxBytes) + 1
+	}
+	if int64(p.MinBytes) > limit {
+		limit = int64(p.MinBytes)
+	}
+
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(body, keep))
+	if err == nil {
+		var rest int64
+		rest, err = io.Copy(io.Discard, io.LimitReader(body, limit-n))
+		n += rest
+	}
+	if err != nil {
+		return fmt.Sprintf("read body: %v", err)
+	}
+
+	if p.MinBytes > 0 && n < int64(p.MinBytes) {
+		return fmt.Sprintf("size: response body is %d bytes, below minimum %d", n, p.MinBytes)
+	}
+	if p.MaxBytes > 0 && n > int64(p.MaxBytes) {
+		return fmt.Sprintf("size: response body exceeds %d bytes", p.MaxBytes)
+	}
+	if p.JSONPath != nil {
+		return p.checkJSON(buf.Bytes())
+	}
+	return ""
+}
+
+// checkJSON evaluates the JSON assertion against a response body and returns a
+// failure message, or "" when the value matches.
+func (p *HTTPProber) checkJSON(data []byte) string {
+	if len(data) > maxJSONBody {
+		return fmt.Sprintf("json: response body exceeds %d bytes", maxJSONBody)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return "json: response is not valid JSON"
+	}
+
+	v, ok := p.JSONPath.Lookup(doc)
+	if !ok {
+		return "json: path not found"
+	}
+	if !jsonpath.Match(v, p.JSONExpected) {
+		return fmt.Sprintf("json: got %s, want %q", jsonpath.Format(v), p.JSONExpected)
+	}
+	return ""
+}
+
 // --- TCP Prober ---
 
-type TCPProber struct{}
+// maxTCPResponse caps how much of a TCP response is buffered while waiting for ExpectData.
+const maxTCPResponse = 64 << 10
+
+type TCPProber struct {
+	SendData    []byte         // written after connecting, if non-empty
+	ExpectData  []byte         // substring that must appear in the response
+	ExpectRegex *regexp.Regexp // used instead of ExpectData when set
+	SourceIP    net.IP         // local address to connect from; nil = OS default
+}
 
 func (p *TCPProber) Probe(ctx context.Context, target string) ProbeResult {
 	start := time.Now()
 
-	var d net.Dialer
-	conn, err := d.DialContext(ctx, "tcp", target)
+	conn, err := newDialer(p.SourceIP).DialContext(ctx, "tcp", target)
 	if err != nil {
 		return ProbeResult{
 			Up:      false,
 			Latency: time.Since(start),
+			Kind:    classifyError(err),
 			Error:   fmt.Sprintf("tcp dial: %v", err),
 		}
 	}
-	conn.Close()
+	defer conn.Close()
+
+	if len(p.SendData) == 0 && len(p.ExpectData) == 0 && p.ExpectRegex == nil {
+		return ProbeResult{Up: true, Latency: time.Since(start)}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if len(p.SendData) > 0 {
+		if _, err := conn.Write(p.SendData); err != nil {
+			return ProbeResult{Up: false, Latency: time.Since(start), Kind: classifyError(err), Error: fmt.Sprintf("tcp send: %v", err)}
+		}
+	}
+
+	if len(p.ExpectData) == 0 && p.ExpectRegex == nil {
+		return ProbeResult{Up: true, Latency: time.Since(start)}
+	}
+
+	var resp []byte
+	buf := make([]byte, 4096)
+	for len(resp) < maxTCPResponse {
+		n, err := conn.Read(buf)
+		resp = append(resp, buf[:n]...)
+		if p.matches(resp) {
+			return ProbeResult{Up: true, Latency: time.Since(start)}
+		}
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return ProbeResult{Up: false, Latency: time.Since(start), Kind: classifyError(err), Error: fmt.Sprintf("tcp expect: %v", err)}
+		}
+	}
+
+	return ProbeResult{
+		Up:      false,
+		Latency: time.Since(start),
+		Kind:    KindProtocol,
+		Error:   fmt.Sprintf("tcp expect: response did not match (got %q)", truncateBytes(resp, 64)),
+	}
+}
+
+func (p *TCPProber) matches(resp []byte) bool {
+	if p.ExpectRegex != nil {
+		return p.ExpectRegex.Match(resp)
+	}
+	return bytes.Contains(resp, p.ExpectData)
+}
+
+// truncateBytes returns at most n bytes of b, for use in error messages.
+func truncateBytes(b []byte, n int) []byte {
+	if len(b) > n {
+		return b[:n]
+	}
+	return b
+}
+
+// DecodePayload converts a configured payload string with Go-style escapes
+// (\r, \n, \t, \xHH, \\) into raw bytes. Invalid escapes are kept literally.
+func DecodePayload(s string) []byte {
+	var out []byte
+	for len(s) > 0 {
+		v, multibyte, tail, err := strconv.UnquoteChar(s, 0)
+		if err != nil {
+			out = append(out, s[0])
+			s = s[1:]
+			continue
+		}
+		if !multibyte {
+			out = append(out, byte(v))
+		} else {
+			out = utf8.AppendRune(out, v)
+		}
+		s = tail
+	}
+	return out
+}
+
+// --- SMTP Prober ---
+
+type SMTPProber struct {
+	RequireStartTLS bool
+	IgnoreTLS       bool
+	SourceIP        net.IP // local address to connect from; nil = OS default
+}
+
+// Probe connects to an SMTP server, checks the 220 greeting and the 250 reply to EHLO,
+// and optionally upgrades the session with STARTTLS. Targets without a port use 25.
+func (p *SMTPProber) Probe(ctx context.Context, target string) ProbeResult {
+	start := time.Now()
+
+	addr := withDefaultPort(target, "25")
+	host, _, _ := net.SplitHostPort(addr)
+
+	conn, err := newDialer(p.SourceIP).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return ProbeResult{Up: false, Latency: time.Since(start), Kind: classifyError(err), Error: fmt.Sprintf("smtp dial: %v", err)}
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// NewClient reads the greeting and fails unless it is a 220.
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return ProbeResult{Up: false, Latency: time.Since(start), Kind: classifyExchangeError(err), Error: fmt.Sprintf("smtp greeting: %v", err)}
+	}
+	defer c.Close()
+
+	if err := c.Hello("wink"); err != nil {
+		return ProbeResult{Up: false, Latency: time.Since(start), Kind: classifyExchangeError(err), Error: fmt.Sprintf("smtp ehlo: %v", err)}
+	}
+
+	if p.RequireStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return ProbeResult{Up: false, Latency: time.Since(start), Kind: KindProtocol, Error: "smtp: server does not offer STARTTLS"}
+		}
+		tlsCfg := &tls.Config{ServerName: host, InsecureSkipVerify: p.IgnoreTLS, RootCAs: rootCAs()}
+		if err := c.StartTLS(tlsCfg); err != nil {
+			return ProbeResult{Up: false, Latency: time.Since(start), Kind: KindTLS, Error: fmt.Sprintf("smtp starttls: %v", err)}
+		}
+	}
+	latency := time.Since(start)
+
+	c.Quit()
+	return ProbeResult{Up: true, Latency: latency}
+}
+
+// --- WebSocket Prober ---
+
+// wsGUID is the fixed GUID from RFC 6455 used to derive Sec-WebSocket-Accept.
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxWSFrame caps how much of a single frame the prober will read while waiting for a pong.
+const maxWSFrame = 64 * 1024
+
+type WSProber struct {
+	IgnoreTLS bool
+	UserAgent string // empty = buildinfo.UserAgent
+	Ping      bool   // send a ping frame after the handshake and require a pong
+	SourceIP  net.IP // local address to connect from; nil = OS default
+}
+
+// Probe performs the WebSocket opening handshake against a ws:// or wss:// URL and
+// is up when the server answers 101 with a valid Sec-WebSocket-Accept.
+func (p *WSProber) Probe(ctx context.Context, target string) ProbeResult {
+	start := time.Now()
+	fail := func(kind ErrorKind, format string, args ...interface{}) ProbeResult {
+		return ProbeResult{Up: false, Latency: time.Since(start), Kind: kind, Error: fmt.Sprintf(format, args...)}
+	}
+
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+		return fail(KindUnknown, "ws: invalid target %q", config.RedactTarget(target))
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "wss" {
+			port = "443"
+		}
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
 
-	return ProbeResult{Up: true, Latency: time.Since(start)}
+	conn, err := newDialer(p.SourceIP).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fail(classifyError(err), "ws dial: %v", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if u.Scheme == "wss" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname(), InsecureSkipVerify: p.IgnoreTLS, RootCAs: rootCAs()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return fail(KindTLS, "ws tls: %v", err)
+		}
+		conn = tlsConn
+	}
+
+	keyBytes := make([]byte, 16)
+	rand.Read(keyBytes)
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	ua := p.UserAgent
+	if ua == "" {
+		ua = buildinfo.UserAgent
+	}
+	httpScheme := "http"
+	if u.Scheme == "wss" {
+		httpScheme = "https"
+	}
+	req, _ := http.NewRequest(http.MethodGet, httpScheme+"://"+u.Host+u.RequestURI(), nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("User-Agent", ua)
+	if err := req.Write(conn); err != nil {
+		return fail(classifyExchangeError(err), "ws handshake: %v", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return fail(classifyExchangeError(err), "ws handshake: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return fail(KindHTTPStatus, "ws handshake: HTTP %d", resp.StatusCode)
+	}
+	sum := sha1.Sum([]byte(key + wsGUID))
+	if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		return fail(KindProtocol, "ws handshake: invalid Sec-WebSocket-Accept")
+	}
+
+	if p.Ping {
+		if err := wsPing(conn, br); err != nil {
+			return fail(classifyExchangeError(err), "ws ping: %v", err)
+		}
+	}
+	latency := time.Since(start)
+
+	// Best-effort close frame; the connection is torn down either way.
+	writeWSFrame(conn, 0x8, []byte{0x03, 0xe8})
+	return ProbeResult{Up: true, Latency: latency}
+}
+
+// wsPing sends a ping frame and waits for the matching pong, skipping any data
+// frames the server pushes in the meantime.
+func wsPing(conn net.Conn, br *bufio.Reader) error {
+	payload := []byte("wink")
+	if err := writeWSFrame(conn, 0x9, payload); err != nil {
+		return err
+	}
+	for {
+		opcode, data, err := readWSFrame(br)
+		if err != nil {
+			return err
+		}
+		switch opcode {
+		case 0xA:
+			if bytes.Equal(data, payload) {
+				return nil
+			}
+		case 0x8:
+			return fmt.Errorf("server closed the connection")
+		}
+	}
+}
+
+// writeWSFrame writes a single masked client frame (RFC 6455 §5.2).
+func writeWSFrame(w io.Writer, opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, 0x80|126, byte(n>>8), byte(n))
+	default:
+		return fmt.Errorf("payload too large")
+	}
+	mask := make([]byte, 4)
+	rand.Read(mask)
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := w.Write(frame)
+	return err
+}
+
+// readWSFrame reads one server frame and returns its opcode and payload.
+func readWSFrame(br *bufio.Reader) (byte, []byte, error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(br, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := hdr[0] & 0x0F
+	n := uint64(hdr[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(br, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(br, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxWSFrame {
+		return 0, nil, fmt.Errorf("frame of %d bytes exceeds limit", n)
+	}
+	var mask []byte
+	if hdr[1]&0x80 != 0 {
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(br, mask); err != nil {
+			return 0, nil, err
+		}
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(br, data); err != nil {
+		return 0, nil, err
+	}
+	if mask != nil {
+		for i := range data {
+			data[i] ^= mask[i%4]
+		}
+	}
+	return opcode, data, nil
 }
 
 // --- ICMP Ping Prober (system ping) ---
 
-type ICMPProber struct{}
+type ICMPProber struct {
+	SourceIP string        // source address passed to ping; empty = OS default
+	Count    int           // echo requests per check; <= 1 sends one
+	Timeout  time.Duration // the monitor's timeout, which the whole check must fit in; 0 = 5s
+	MaxLoss  int           // packet loss percentage that fails the check; 0 = only total loss
+}
 
 // pingLatencyRe matches RTT from ping output across platforms.
 // Linux:   rtt min/avg/max/mdev = 1.234/1.234/1.234/0.000 ms
@@ -97,14 +737,22 @@ type ICMPProber struct{}
 // Windows: Average = 1ms
 var pingLatencyRe = regexp.MustCompile(`(?:rtt|round-trip).*?=\s*[\d.]+/([\d.]+)/|Average\s*=\s*(\d+)\s*ms`)
 
-// Probe calls the system ping command and parses the result.
+// pingLossRe matches the packet loss summary across platforms.
+// Linux:   3 packets transmitted, 2 received, 33.3333% packet loss
+// macOS:   3 packets transmitted, 2 packets received, 33.3% packet loss
+// Windows: Lost = 1 (33% loss)
+var pingLossRe = regexp.MustCompile(`([\d.]+)%\s*(?:packet\s+)?loss`)
+
+// Probe calls the system ping command and parses the result. With several
+// packets the latency is the average round trip of those answered.
 func (p *ICMPProber) Probe(ctx context.Context, target string) ProbeResult {
-	var args []string
-	if runtime.GOOS == "windows" {
-		args = []string{"ping", "-n", "1", "-w", "5000", target}
-	} else {
-		args = []string{"ping", "-c", "1", "-W", "5", target}
+	v6 := isIPv6(target) || isIPv6(p.SourceIP)
+	args := p.command(runtime.GOOS, v6)
+	addr, err := resolveForPing(ctx, target, v6)
+	if err != nil {
+		return ProbeResult{Up: false, Kind: classifyError(err), Error: fmt.Sprintf("ping: %v", err)}
 	}
+	args = append(args, addr)
 
 	start := time.Now()
 	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
@@ -112,7 +760,7 @@ func (p *ICMPProber) Probe(ctx context.Context, target string) ProbeResult {
 	latency := time.Since(start)
 
 	if err != nil {
-		return ProbeResult{Up: false, Latency: latency, Error: fmt.Sprintf("ping: %v", err)}
+		return ProbeResult{Up: false, Latency: latency, Kind: classifyPingError(err), Error: fmt.Sprintf("ping: %v", err)}
 	}
 
 	// Parse latency from ping output.
@@ -126,18 +774,173 @@ func (p *ICMPProber) Probe(ctx context.Context, target string) ProbeResult {
 		}
 	}
 
+	// ping exits non-zero only when no reply came back at all; partial loss
+	// is judged against the configured threshold.
+	if p.MaxLoss > 0 && p.Count > 1 {
+		if m := pingLossRe.FindSubmatch(out); m != nil {
+			if loss, err := strconv.ParseFloat(string(m[1]), 64); err == nil && loss >= float64(p.MaxLoss) {
+				return ProbeResult{Up: false, Latency: latency, Kind: KindTimeout, Error: fmt.Sprintf("ping: %g%% packet loss (threshold %d%%)", loss, p.MaxLoss)}
+			}
+		}
+	}
+
 	return ProbeResult{Up: true, Latency: latency}
 }
 
-// NewProber creates the appropriate prober for a monitor type.
-func NewProber(monitorType string, ignoreTLS bool) Prober {
-	switch monitorType {
+// command returns the ping command line for goos, without the address. v6
+// selects IPv6, which the target or the source address may require.
+func (p *ICMPProber) command(goos string, v6 bool) []string {
+	count := max(p.Count, 1)
+	// Linux ping can send faster than the default one per second without root.
+	interval := time.Second
+	if goos == "linux" {
+		interval = 200 * time.Millisecond
+	}
+	// Wait for each reply as long as the timeout allows after the last packet
+	// is sent, but at least a second.
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	wait := max(timeout-time.Duration(count-1)*interval, time.Second)
+	n := strconv.Itoa(count)
+	waitSec := strconv.Itoa(int(wait / time.Second))
+	waitMs := strconv.Itoa(int(wait / time.Millisecond))
+
+	// IPv6 needs -6 on Linux and Windows, and the separate ping6 command on
+	// macOS and the BSDs, whose ping is IPv4 only (and has no -W). The BSD
+	// ping takes -W in milliseconds, Linux in seconds.
+	var args []string
+	switch {
+	case goos == "windows":
+		args = []string{"ping", "-n", n, "-w", waitMs}
+		if v6 {
+			args = append(args, "-6")
+		}
+	case goos == "linux":
+		args = []string{"ping", "-c", n, "-W", waitSec}
+		if count > 1 {
+			args = append(args, "-i", "0.2")
+		}
+		if v6 {
+			args = append(args, "-6")
+		}
+	case v6:
+		args = []string{"ping6", "-c", n}
+	default:
+		args = []string{"ping", "-c", n, "-W", waitMs}
+	}
+	if p.SourceIP != "" {
+		// Linux ping takes the source address via -I; BSD/macOS and Windows use -S.
+		flag := "-S"
+		if goos == "linux" {
+			flag = "-I"
+		}
+		args = append(args, flag, p.SourceIP)
+	}
+	return args
+}
+
+// withDefaultPort returns target as host:port, adding port when target has
+// none: a bare host, IPv6 address, or bracketed IPv6 address.
+func withDefaultPort(target, port string) string {
+	if _, _, err := net.SplitHostPort(target); err == nil {
+		return target
+	}
+	return net.JoinHostPort(strings.TrimSuffix(strings.TrimPrefix(target, "["), "]"), port)
+}
+
+// isIPv6 reports whether s is an IPv6 address, optionally with a zone.
+func isIPv6(s string) bool {
+	host, _, _ := strings.Cut(s, "%")
+	ip := net.ParseIP(host)
+	return ip != nil && ip.To4() == nil
+}
+
+// newDialer returns a dialer bound to source, or an unbound one when source is
+// nil. Host names are resolved through system.dns_resolver when it is set.
+func newDialer(source net.IP) *net.Dialer {
+	d := &net.Dialer{Resolver: currentResolver()}
+	if source != nil {
+		d.LocalAddr = &net.TCPAddr{IP: source}
+	}
+	return d
+}
+
+// CheckSourceIP reports whether ip is a local address probes can bind to.
+func CheckSourceIP(ip string) error {
+	l, err := net.Listen("tcp", net.JoinHostPort(ip, "0"))
+	if err != nil {
+		return err
+	}
+	return l.Close()
+}
+
+// hostOnly strips an optional port from a host header value.
+func hostOnly(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return hostport
+}
+
+// NewProber creates the appropriate prober for a monitor's type and options.
+func NewProber(m config.Monitor) Prober {
+	source := net.ParseIP(m.SourceIP) // validated on save; nil when unset
+	switch m.Type {
 	case "http":
-		return &HTTPProber{IgnoreTLS: ignoreTLS}
+		p := &HTTPProber{
+			Method:     m.Method,
+			IgnoreTLS:  m.IgnoreTLS,
+			UserAgent:  m.UserAgent,
+			HostHeader: m.HostHeader,
+			MinBytes:   m.MinBytes,
+			MaxBytes:   m.MaxBytes,
+			SourceIP:   source,
+
+			ExpectContentType: m.ExpectContentType,
+			HonorRetryAfter:   m.HonorRetryAfter,
+
+			MinTLSVersion: config.TLSVersions[m.MinTLSVersion],
+			RequireHTTP2:  m.RequireHTTP2,
+
+			Trace: m.TraceTiming,
+		}
+		if m.LatencyCountsAsDown {
+			p.MaxLatency = time.Duration(m.SlowThresholdMs) * time.Millisecond
+		}
+		if m.JSONPath != "" {
+			// Validated on save; an unparseable path disables the assertion.
+			if path, err := jsonpath.Parse(m.JSONPath); err == nil {
+				p.JSONPath = path
+				p.JSONExpected = m.JSONExpected
+			}
+		}
+		return p
 	case "tcp":
-		return &TCPProber{}
+		p := &TCPProber{
+			SendData:   DecodePayload(m.SendData),
+			ExpectData: DecodePayload(m.ExpectData),
+			SourceIP:   source,
+		}
+		if m.ExpectRegex && m.ExpectData != "" {
+			// Validated on save; a bad pattern falls back to substring matching.
+			if re, err := regexp.Compile(m.ExpectData); err == nil {
+				p.ExpectRegex = re
+			}
+		}
+		return p
+	case "ws":
+		return &WSProber{IgnoreTLS: m.IgnoreTLS, UserAgent: m.UserAgent, Ping: m.WSPing, SourceIP: source}
+	case "smtp":
+		return &SMTPProber{RequireStartTLS: m.SMTPStartTLS, IgnoreTLS: m.IgnoreTLS, SourceIP: source}
 	case "ping":
-		return &ICMPProber{}
+		return &ICMPProber{
+			SourceIP: m.SourceIP,
+			Count:    m.PingCount,
+			Timeout:  time.Duration(m.Timeout) * time.Second,
+			MaxLoss:  m.PingLossThreshold,
+		}
 	default:
 		return &HTTPProber{}
 	}
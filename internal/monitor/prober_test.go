@@ -0,0 +1,101 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWithDefaultPort(t *testing.T) {
+	tests := []struct{ target, want string }{
+		{"192.0.2.1", "192.0.2.1:25"},
+		{"192.0.2.1:587", "192.0.2.1:587"},
+		{"2001:db8::1", "[2001:db8::1]:25"},
+		{"[2001:db8::1]", "[2001:db8::1]:25"},
+		{"[2001:db8::1]:587", "[2001:db8::1]:587"},
+		{"mail.example.com", "mail.example.com:25"},
+		{"mail.example.com:587", "mail.example.com:587"},
+	}
+	for _, tt := range tests {
+		if got := withDefaultPort(tt.target, "25"); got != tt.want {
+			t.Errorf("withDefaultPort(%q) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestTCPProberTargets(t *testing.T) {
+	tests := []struct {
+		network, listen, host string
+	}{
+		{"tcp4", "127.0.0.1:0", "127.0.0.1"},
+		{"tcp6", "[::1]:0", "::1"},
+		{"tcp4", "127.0.0.1:0", "localhost"},
+	}
+	for _, tt := range tests {
+		l, err := net.Listen(tt.network, tt.listen)
+		if err != nil {
+			t.Logf("skipping %s: %v", tt.host, err)
+			continue
+		}
+		go func() {
+			for {
+				c, err := l.Accept()
+				if err != nil {
+					return
+				}
+				c.Close()
+			}
+		}()
+		_, port, _ := net.SplitHostPort(l.Addr().String())
+		target := net.JoinHostPort(tt.host, port)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		r := (&TCPProber{}).Probe(ctx, target)
+		cancel()
+		l.Close()
+		if !r.Up {
+			t.Errorf("TCPProber.Probe(%q) down: %s", target, r.Error)
+		}
+	}
+}
+
+func TestICMPProberCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		goos   string
+		p      ICMPProber
+		target string
+		want   []string
+	}{
+		{"linux ipv4", "linux", ICMPProber{}, "192.0.2.1",
+			[]string{"ping", "-c", "1", "-W", "5"}},
+		{"linux ipv6", "linux", ICMPProber{}, "2001:db8::1",
+			[]string{"ping", "-c", "1", "-W", "5", "-6"}},
+		{"linux hostname", "linux", ICMPProber{}, "host.example.com",
+			[]string{"ping", "-c", "1", "-W", "5"}},
+		{"linux ipv6 source", "linux", ICMPProber{SourceIP: "2001:db8::2"}, "host.example.com",
+			[]string{"ping", "-c", "1", "-W", "5", "-6", "-I", "2001:db8::2"}},
+		{"linux zone", "linux", ICMPProber{}, "fe80::1%eth0",
+			[]string{"ping", "-c", "1", "-W", "5", "-6"}},
+		{"linux count", "linux", ICMPProber{Count: 3, Timeout: 5 * time.Second}, "192.0.2.1",
+			[]string{"ping", "-c", "3", "-W", "4", "-i", "0.2"}},
+		{"darwin ipv4", "darwin", ICMPProber{}, "192.0.2.1",
+			[]string{"ping", "-c", "1", "-W", "5000"}},
+		{"darwin ipv6", "darwin", ICMPProber{}, "2001:db8::1",
+			[]string{"ping6", "-c", "1"}},
+		{"darwin hostname", "darwin", ICMPProber{}, "host.example.com",
+			[]string{"ping", "-c", "1", "-W", "5000"}},
+		{"windows ipv4", "windows", ICMPProber{}, "192.0.2.1",
+			[]string{"ping", "-n", "1", "-w", "5000"}},
+		{"windows ipv6", "windows", ICMPProber{SourceIP: "2001:db8::2"}, "2001:db8::1",
+			[]string{"ping", "-n", "1", "-w", "5000", "-6", "-S", "2001:db8::2"}},
+	}
+	for _, tt := range tests {
+		v6 := isIPv6(tt.target) || isIPv6(tt.p.SourceIP)
+		if got := tt.p.command(tt.goos, v6); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: command = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/makt28/wink/internal/config"
+)
+
+// Built-in incident categories. system.reason_rules may add others.
+const (
+	CategoryTimeout   = "timeout"
+	CategoryDNS       = "dns"
+	CategoryRefused   = "connection_refused"
+	CategoryReset     = "connection_reset"
+	CategoryUnreach   = "unreachable"
+	CategoryTLS       = "tls"
+	CategoryHTTP5xx   = "http_5xx"
+	CategoryHTTP4xx   = "http_4xx"
+	CategoryAssertion = "assertion"
+	CategoryLatency   = "latency"
+	CategoryOther     = "other"
+)
+
+// builtinReasons map probe error text to a category. The first match wins, so
+// specific causes come before the generic ones they are wrapped in (a TLS
+// handshake timeout is a TLS problem, not a plain timeout).
+var builtinReasons = []struct {
+	re       *regexp.Regexp
+	category string
+}{
+	{regexp.MustCompile(`^HTTP 5\d\d`), CategoryHTTP5xx},
+	{regexp.MustCompile(`^HTTP 4\d\d`), CategoryHTTP4xx},
+	{regexp.MustCompile(`^(json|size|content-type|tcp expect|http2): |does not offer STARTTLS`), CategoryAssertion},
+	{regexp.MustCompile(`^slow \(>\d+ms\)`), CategoryLatency},
+	{regexp.MustCompile(`(?i)(\btls|starttls)[: ]|x509|certificate`), CategoryTLS},
+	{regexp.MustCompile(`(?i)no such host|server misbehaving|lookup .*: `), CategoryDNS},
+	{regexp.MustCompile(`(?i)connection refused`), CategoryRefused},
+	{regexp.MustCompile(`(?i)connection reset|broken pipe|EOF$`), CategoryReset},
+	{regexp.MustCompile(`(?i)timeout|deadline exceeded|timed out`), CategoryTimeout},
+	{regexp.MustCompile(`(?i)no route to host|network is unreachable|host is down|^ping: exit status|packet loss`), CategoryUnreach},
+}
+
+// ruleCache holds compiled system.reason_rules patterns, keyed by pattern.
+var ruleCache sync.Map
+
+// CategorizeReason maps a raw probe error to a stable category for grouping
+// incidents. rules (system.reason_rules) are tried first, in order, then the
+// built-in patterns. An empty reason has no category.
+func CategorizeReason(reason string, rules []config.ReasonRule) string {
+	if strings.TrimSpace(reason) == "" {
+		return ""
+	}
+	for _, rule := range rules {
+		re, ok := ruleCache.Load(rule.Match)
+		if !ok {
+			compiled, err := regexp.Compile(rule.Match)
+			if err != nil {
+				// Validated on load; skip rather than fail the probe.
+				continue
+			}
+			re, _ = ruleCache.LoadOrStore(rule.Match, compiled)
+		}
+		if re.(*regexp.Regexp).MatchString(reason) {
+			return rule.Category
+		}
+	}
+	for _, b := range builtinReasons {
+		if b.re.MatchString(reason) {
+			return b.category
+		}
+	}
+	return CategoryOther
+}
@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// probeResolver is the DNS resolver probes use for host names, set from
+// system.dns_resolver. nil means the host's resolver.
+var probeResolver struct {
+	mu       sync.RWMutex
+	addr     string
+	resolver *net.Resolver
+}
+
+// SetDNSResolver makes probes resolve host names through the DNS server at
+// addr ("ip:port"), or through the host's resolver when addr is empty. It takes
+// effect on the next probe.
+func SetDNSResolver(addr string) {
+	probeResolver.mu.Lock()
+	defer probeResolver.mu.Unlock()
+	if addr == probeResolver.addr {
+		return
+	}
+	probeResolver.addr = addr
+	probeResolver.resolver = nil
+	if addr != "" {
+		probeResolver.resolver = newResolver(addr)
+	}
+}
+
+// currentResolver returns the resolver set by SetDNSResolver, or nil.
+func currentResolver() *net.Resolver {
+	probeResolver.mu.RLock()
+	defer probeResolver.mu.RUnlock()
+	return probeResolver.resolver
+}
+
+// newResolver returns a resolver that sends every query to addr instead of the
+// servers in the system configuration.
+func newResolver(addr string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+}
+
+// CheckDNSResolver sends a test query to the DNS server at addr and reports
+// whether it answered. A "no such host" answer counts: it proves the server
+// is reachable.
+func CheckDNSResolver(addr string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := newResolver(addr).LookupHost(ctx, "wink-resolver-check.invalid.")
+	var dnsErr *net.DNSError
+	if err == nil || (errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		return nil
+	}
+	return err
+}
+
+// resolveForPing resolves a ping target through the configured resolver, since
+// the ping command always uses the host's. IP literals, and every target when
+// no resolver is configured, are returned unchanged.
+func resolveForPing(ctx context.Context, target string, v6 bool) (string, error) {
+	r := currentResolver()
+	if host, _, _ := strings.Cut(target, "%"); r == nil || net.ParseIP(host) != nil {
+		return target, nil
+	}
+	network := "ip4"
+	if v6 {
+		network = "ip6"
+	}
+	ips, err := r.LookupIP(ctx, network, target)
+	if err != nil {
+		return "", err
+	}
+	return ips[0].String(), nil
+}
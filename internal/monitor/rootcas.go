@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"crypto/x509"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// probeRoots holds the certificate pool TLS probes verify against, set from
+// system.ca_bundle_path. A nil pool means the system trust store.
+var probeRoots struct {
+	mu   sync.RWMutex
+	pool *x509.CertPool
+}
+
+// SetCABundle makes TLS probes trust the PEM certificates in the file at path
+// in addition to the system trust store, or only the system store when path is
+// empty. The file is read again on every call, so a replaced bundle is picked
+// up by the next config change. If it can't be loaded the previous pool stays.
+func SetCABundle(path string) {
+	if path == "" {
+		probeRoots.mu.Lock()
+		probeRoots.pool = nil
+		probeRoots.mu.Unlock()
+		return
+	}
+	pool, err := LoadCABundle(path)
+	if err != nil {
+		slog.Error("failed to load ca_bundle_path, keeping the previous trust store", "path", path, "error", err)
+		return
+	}
+	probeRoots.mu.Lock()
+	probeRoots.pool = pool
+	probeRoots.mu.Unlock()
+}
+
+// LoadCABundle returns the system trust store plus the PEM certificates in the
+// file at path, which must contain at least one.
+func LoadCABundle(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		// No system store (e.g. a scratch container): trust the bundle alone.
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("no PEM certificates found")
+	}
+	return pool, nil
+}
+
+// rootCAs returns the pool set by SetCABundle, or nil for the system store.
+func rootCAs() *x509.CertPool {
+	probeRoots.mu.RLock()
+	defer probeRoots.mu.RUnlock()
+	return probeRoots.pool
+}
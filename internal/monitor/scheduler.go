@@ -2,6 +2,7 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"reflect"
 	"sync"
@@ -10,9 +11,31 @@ import (
 	"github.com/makt28/wink/internal/config"
 )
 
+// Errors returned by CheckNow.
+var (
+	ErrMonitorNotRunning = errors.New("monitor is not running")
+	ErrProbeInFlight     = errors.New("a probe is already running for this monitor")
+)
+
+// A monitor is reported stalled when no probe result has landed for
+// stallFactor times its longest expected gap between probes. The watchdog
+// checks every stallCheckInterval.
+const (
+	stallFactor        = 3
+	stallCheckInterval = 30 * time.Second
+)
+
+// skippedProbeRetry is the delay in seconds before retrying a monitor's first
+// probe when it was skipped because another probe of the monitor was running.
+const skippedProbeRetry = 1
+
 type runningMonitor struct {
-	cancel context.CancelFunc
-	cfg    config.Monitor
+	ctx       context.Context // cancelled when the monitor is stopped
+	cancel    context.CancelFunc
+	cfg       config.Monitor
+	started   time.Time
+	lastProbe time.Time // when a probe result was last recorded
+	maxGap    time.Duration
 }
 
 // Scheduler manages one goroutine per monitor and reacts to config changes.
@@ -22,19 +45,32 @@ type Scheduler struct {
 
 	mu       sync.Mutex
 	running  map[string]*runningMonitor
+	stalled  map[string]time.Time // monitor ID -> when the watchdog found it stalled
 	wg       sync.WaitGroup
 	stopOnce sync.Once
 	stopCh   chan struct{}
+
+	probeMu   sync.Mutex
+	probeIdle *sync.Cond      // signalled on probeMu whenever a probe ends
+	inFlight  map[string]bool // monitor IDs with a probe currently running
+
+	coalescer *probeCoalescer
 }
 
 // NewScheduler creates a new Scheduler.
 func NewScheduler(cfgMgr *config.Manager, analyzer *Analyzer) *Scheduler {
-	return &Scheduler{
+	s := &Scheduler{
 		cfgMgr:   cfgMgr,
 		analyzer: analyzer,
 		running:  make(map[string]*runningMonitor),
+		stalled:  make(map[string]time.Time),
+		inFlight: make(map[string]bool),
 		stopCh:   make(chan struct{}),
+
+		coalescer: newProbeCoalescer(),
 	}
+	s.probeIdle = sync.NewCond(&s.probeMu)
+	return s
 }
 
 // Start launches monitor goroutines and listens for config changes.
@@ -42,26 +78,140 @@ func (s *Scheduler) Start() {
 	cfg := s.cfgMgr.Get()
 	s.syncMonitors(cfg)
 
-	s.wg.Add(1)
+	s.wg.Add(3)
 	go s.watchChanges()
+	go s.watchStalls()
+	go s.forwardProbes()
 }
 
-// Stop cancels all monitor goroutines and waits for them to finish.
+// Stop cancels all monitor goroutines, waits for them to finish and flushes
+// each monitor's final state to history. Once it returns no probe result will
+// be recorded, so history can be dumped safely.
 func (s *Scheduler) Stop() {
 	s.stopOnce.Do(func() {
 		close(s.stopCh)
 
 		s.mu.Lock()
+		count := len(s.running)
 		for id, rm := range s.running {
 			rm.cancel()
 			delete(s.running, id)
 		}
+		clear(s.stalled)
 		s.mu.Unlock()
 
 		s.wg.Wait()
+		s.analyzer.Flush()
+		slog.Info("scheduler stopped", "monitors", count)
 	})
 }
 
+// RemoveMonitor stops a deleted monitor and then drops its analyzer state,
+// history and incidents. It waits for a probe that is still running (scheduled
+// or manual) to finish first, so that probe cannot record into the monitor's
+// history after it was removed. Call it after the monitor is gone from config.
+func (s *Scheduler) RemoveMonitor(id string) {
+	s.mu.Lock()
+	if rm, ok := s.running[id]; ok {
+		rm.cancel()
+		delete(s.running, id)
+	}
+	delete(s.stalled, id)
+	s.mu.Unlock()
+
+	s.probeMu.Lock()
+	for s.inFlight[id] {
+		s.probeIdle.Wait()
+	}
+	s.probeMu.Unlock()
+
+	s.analyzer.RemoveMonitor(id)
+}
+
+// RunningCount returns the number of monitor goroutines currently scheduled.
+func (s *Scheduler) RunningCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.running)
+}
+
+// StalledSince returns when the monitor was found stalled: running, but with
+// no probe result recorded for stallFactor times its expected interval.
+func (s *Scheduler) StalledSince(id string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.stalled[id]
+	return t, ok
+}
+
+// StalledCount returns the number of monitors currently stalled.
+func (s *Scheduler) StalledCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.stalled)
+}
+
+// watchStalls periodically looks for running monitors whose probes stopped
+// landing, which would otherwise leave their last state standing silently.
+func (s *Scheduler) watchStalls() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(stallCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.checkStalls(time.Now())
+		}
+	}
+}
+
+// checkStalls marks monitors stalled whose last probe result (or start, if
+// none landed yet) is older than stallFactor times their longest expected gap.
+// With system.restart_stalled_monitors set they are restarted as well.
+func (s *Scheduler) checkStalls(now time.Time) {
+	sys := s.cfgMgr.Get().System
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, rm := range s.running {
+		last := rm.lastProbe
+		if last.IsZero() {
+			last = rm.started
+		}
+		silent := now.Sub(last)
+		if silent <= stallFactor*rm.maxGap {
+			continue
+		}
+		if _, ok := s.stalled[id]; !ok {
+			s.stalled[id] = now
+			slog.Error("monitor stalled, no probe recorded", "id", id, "name", rm.cfg.Name, "silent_for", silent.Round(time.Second).String())
+		}
+		if sys.RestartStalledMonitors {
+			slog.Warn("restarting stalled monitor", "id", id, "name", rm.cfg.Name)
+			rm.cancel()
+			delete(s.running, id)
+			s.startMonitor(rm.cfg, sys.CheckInterval)
+		}
+	}
+}
+
+// markProbed records that a probe result of the monitor landed, clearing a
+// stall.
+func (s *Scheduler) markProbed(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rm, ok := s.running[id]; ok {
+		rm.lastProbe = time.Now()
+	}
+	if _, ok := s.stalled[id]; ok {
+		delete(s.stalled, id)
+		slog.Info("stalled monitor resumed", "id", id)
+	}
+}
+
 func (s *Scheduler) watchChanges() {
 	defer s.wg.Done()
 
@@ -80,14 +230,27 @@ func (s *Scheduler) watchChanges() {
 
 // syncMonitors diffs running goroutines against config and starts/stops as needed.
 func (s *Scheduler) syncMonitors(cfg config.Config) {
+	SetDNSResolver(cfg.System.DNSResolver)
+	SetCABundle(cfg.System.CABundlePath)
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	desired := make(map[string]config.Monitor)
-	for _, m := range cfg.Monitors {
-		if m.IsEnabled() {
-			desired[m.ID] = m
+	if cfg.System.IsMonitoringEnabled() {
+		for _, m := range cfg.Monitors {
+			if m.IsEnabled() {
+				// Resolve the source address here so a change to the system
+				// default restarts the affected monitors.
+				if m.SourceIP == "" {
+					m.SourceIP = cfg.System.ProbeSourceIP
+				}
+				m.ProbeEvents = m.ProbeEvents || cfg.System.ProbeEvents
+				desired[m.ID] = m
+			}
 		}
+	} else if len(s.running) > 0 {
+		slog.Warn("monitoring paused, stopping all monitors", "count", len(s.running))
 	}
 
 	// Stop monitors removed or changed
@@ -97,6 +260,7 @@ func (s *Scheduler) syncMonitors(cfg config.Config) {
 			slog.Info("stopping removed monitor", "id", id)
 			rm.cancel()
 			delete(s.running, id)
+			delete(s.stalled, id)
 			s.analyzer.RemoveState(id)
 		} else if !reflect.DeepEqual(rm.cfg, dm) {
 			slog.Info("restarting changed monitor", "id", id)
@@ -113,10 +277,10 @@ func (s *Scheduler) syncMonitors(cfg config.Config) {
 	}
 }
 
+// startMonitor launches the probe loop for m. Probes run sequentially and the
+// interval is measured from the end of one probe to the start of the next, so a
+// slow probe delays the schedule instead of overlapping with the following one.
 func (s *Scheduler) startMonitor(m config.Monitor, defaultInterval int) {
-	ctx, cancel := context.WithCancel(context.Background())
-	s.running[m.ID] = &runningMonitor{cancel: cancel, cfg: m}
-
 	interval := m.Interval
 	if interval <= 0 {
 		interval = defaultInterval
@@ -127,46 +291,216 @@ func (s *Scheduler) startMonitor(m config.Monitor, defaultInterval int) {
 	}
 	timeout := m.Timeout
 
-	prober := NewProber(m.Type, m.IgnoreTLS)
+	// The longest the loop may legitimately wait between two results: the
+	// slower cadence or the initial failure backoff, plus a probe's timeout.
+	maxGap := max(interval, retryInterval, s.cfgMgr.Get().System.InitialBackoffMax) + timeout
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.running[m.ID] = &runningMonitor{
+		ctx: ctx, cancel: cancel, cfg: m,
+		started: time.Now(), maxGap: time.Duration(maxGap) * time.Second,
+	}
+
+	prober := NewProber(m)
 
 	s.wg.Add(1)
 	go func(m config.Monitor, normalInterval, retryInterval, timeout int) {
 		defer s.wg.Done()
 		slog.Info("monitor started", "id", m.ID, "name", m.Name, "type", m.Type, "interval", normalInterval)
 
-		currentInterval := normalInterval
+		// A monitor that has never had a successful probe backs off on failure
+		// (see system.initial_backoff_max) until its first success.
+		provisional := !s.analyzer.HasSucceeded(m.ID)
+		backoff := 0
+		// hold counts the successful probes still to run at the retry interval
+		// after a failure (monitor.retry_hold), so a flaky link doesn't flip the
+		// cadence on every probe.
+		hold := 0
+		// currentInterval is the delay before the next probe; 0 until the
+		// first one ran.
+		currentInterval := 0
 
-		// First probe immediately
-		ar := s.runProbe(ctx, prober, m, timeout)
-		if ar.IsFailing && retryInterval < normalInterval {
-			currentInterval = retryInterval
+		nextInterval := func(ar AnalyzeResult) int {
+			if ar.Skipped {
+				// Another probe of this monitor was running (a manual check, or
+				// the previous goroutine's after a restart). Keep the cadence,
+				// or retry shortly so the first probe isn't lost.
+				if currentInterval > 0 {
+					return currentInterval
+				}
+				return skippedProbeRetry
+			}
+			if provisional && !ar.IsFailing && s.analyzer.HasSucceeded(m.ID) {
+				provisional = false
+			}
+			if !ar.IsFailing {
+				if hold > 0 && retryInterval < normalInterval {
+					hold--
+					return retryInterval
+				}
+				return normalInterval
+			}
+			hold = m.RetryHold
+			if provisional {
+				if limit := s.cfgMgr.Get().System.InitialBackoffMax; limit > 0 {
+					backoff = nextBackoff(backoff, retryInterval, limit)
+					slog.Debug("new monitor failing, backing off", "id", m.ID, "name", m.Name, "next_probe_in", backoff)
+					return backoff
+				}
+			}
+			if retryInterval < normalInterval {
+				return retryInterval
+			}
+			return normalInterval
 		}
 
+		// First probe immediately
+		currentInterval = nextInterval(s.runProbe(ctx, prober, m, timeout))
+
 		timer := time.NewTimer(time.Duration(currentInterval) * time.Second)
 		defer timer.Stop()
 
 		for {
 			select {
 			case <-ctx.Done():
-				slog.Info("monitor stopped", "id", m.ID, "name", m.Name)
-				return
-			case <-timer.C:
-				ar := s.runProbe(ctx, prober, m, timeout)
-				if ar.IsFailing && retryInterval < normalInterval {
-					currentInterval = retryInterval
+				if st, ok := s.analyzer.State(m.ID); ok {
+					slog.Info("monitor stopped", "id", m.ID, "name", m.Name, "up", st.IsUp, "fail_count", st.FailCount)
 				} else {
-					currentInterval = normalInterval
+					slog.Info("monitor stopped", "id", m.ID, "name", m.Name)
 				}
+				return
+			case <-timer.C:
+				currentInterval = nextInterval(s.runProbe(ctx, prober, m, timeout))
 				timer.Reset(time.Duration(currentInterval) * time.Second)
 			}
 		}
 	}(m, interval, retryInterval, timeout)
 }
 
+// nextBackoff doubles the previous delay, starting at base and capped at limit
+// (but never below base).
+func nextBackoff(prev, base, limit int) int {
+	if limit < base {
+		limit = base
+	}
+	next := base
+	if prev > 0 {
+		next = prev * 2
+	}
+	if next > limit {
+		next = limit
+	}
+	return next
+}
+
+// runProbe executes one probe and feeds the result to the analyzer. If a probe for
+// the same monitor is still running (e.g. from a goroutine that was just restarted
+// after a config change), the probe is skipped and the result has Skipped set,
+// as it does when the monitor is stopped before its result is recorded. With system.probe_coalesce_window
+// set, monitors with identical probe settings share results (see probeCoalescer).
 func (s *Scheduler) runProbe(ctx context.Context, prober Prober, m config.Monitor, timeout int) AnalyzeResult {
+	if !s.beginProbe(m.ID) {
+		slog.Debug("previous probe still running, skipping", "id", m.ID, "name", m.Name)
+		return AnalyzeResult{Skipped: true}
+	}
+	defer s.endProbe(m.ID)
+	if ctx.Err() != nil {
+		// Stopped while waiting for the timer; RemoveMonitor may already be done.
+		return AnalyzeResult{Skipped: true}
+	}
+
 	probeCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
 	defer cancel()
 
-	result := prober.Probe(probeCtx, m.Target)
-	return s.analyzer.Process(m.ID, m.Name, m.Target, m.MaxRetries, m.ReminderInterval, result)
+	var result ProbeResult
+	if window := s.cfgMgr.Get().System.ProbeCoalesceWindow; window > 0 {
+		var shared bool
+		result, shared = s.coalescer.do(probeCtx, probeKey(m), time.Duration(window)*time.Second, func() ProbeResult {
+			return probeTargets(probeCtx, prober, m)
+		})
+		if shared {
+			slog.Debug("reused coalesced probe result", "id", m.ID, "name", m.Name, "up", result.Up)
+		}
+	} else {
+		result = probeTargets(probeCtx, prober, m)
+	}
+	if ctx.Err() != nil {
+		// The monitor was stopped (removed, edited or paused) mid-probe; the
+		// failure is ours, not the target's, so don't record it.
+		return AnalyzeResult{Skipped: true}
+	}
+	s.categorize(&result)
+	ar := s.analyzer.Process(m, result)
+	s.markProbed(m.ID)
+	return ar
+}
+
+// CheckNow runs an immediate out-of-band probe of a running monitor and feeds the
+// result to the analyzer like a scheduled probe. The monitor's timer is left
+// alone, and probe coalescing is bypassed so the result is always fresh.
+func (s *Scheduler) CheckNow(ctx context.Context, id string) (ProbeResult, error) {
+	// The probe is registered while s.mu is held so RemoveMonitor, which takes
+	// the monitor out of s.running first, always waits for it.
+	s.mu.Lock()
+	rm, ok := s.running[id]
+	if !ok {
+		s.mu.Unlock()
+		return ProbeResult{}, ErrMonitorNotRunning
+	}
+	if !s.beginProbe(id) {
+		s.mu.Unlock()
+		return ProbeResult{}, ErrProbeInFlight
+	}
+	s.mu.Unlock()
+	defer s.endProbe(id)
+	m := rm.cfg
+
+	probeCtx, cancel := context.WithTimeout(ctx, time.Duration(m.Timeout)*time.Second)
+	defer cancel()
+	// Stopping the monitor aborts the check too.
+	stop := context.AfterFunc(rm.ctx, cancel)
+	defer stop()
+
+	result := probeTargets(probeCtx, NewProber(m), m)
+	if err := ctx.Err(); err != nil {
+		// The caller went away mid-probe; don't record a failure that isn't the target's.
+		return ProbeResult{}, err
+	}
+	if rm.ctx.Err() != nil {
+		return ProbeResult{}, ErrMonitorNotRunning
+	}
+	slog.Info("manual check", "id", m.ID, "name", m.Name, "up", result.Up)
+	s.categorize(&result)
+	s.analyzer.Process(m, result)
+	s.markProbed(id)
+	return result, nil
+}
+
+// categorize sets the incident category of a failed probe, and its kind if the
+// prober left it unset.
+func (s *Scheduler) categorize(result *ProbeResult) {
+	if !result.Up {
+		result.Category = CategorizeReason(result.Error, s.cfgMgr.Get().System.ReasonRules)
+		if result.Kind == "" {
+			result.Kind = KindUnknown
+		}
+	}
+}
+
+// beginProbe marks a probe of id as running. It returns false if one already is.
+func (s *Scheduler) beginProbe(id string) bool {
+	s.probeMu.Lock()
+	defer s.probeMu.Unlock()
+	if s.inFlight[id] {
+		return false
+	}
+	s.inFlight[id] = true
+	return true
+}
+
+func (s *Scheduler) endProbe(id string) {
+	s.probeMu.Lock()
+	delete(s.inFlight, id)
+	s.probeIdle.Broadcast()
+	s.probeMu.Unlock()
 }
@@ -0,0 +1,120 @@
+package monitor
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/makt28/wink/internal/config"
+	"github.com/makt28/wink/internal/storage"
+)
+
+// blockingProber signals started when a probe begins and returns a failure only
+// once release is closed, ignoring cancellation like a prober stuck in I/O.
+type blockingProber struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingProber) Probe(ctx context.Context, target string) ProbeResult {
+	close(p.started)
+	<-p.release
+	return ProbeResult{Up: false, Error: "released"}
+}
+
+func newTestScheduler(t *testing.T) (*Scheduler, *storage.HistoryManager) {
+	t.Helper()
+	dir := t.TempDir()
+	cfgMgr, err := config.NewManager(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	histMgr, err := storage.NewHistoryManager(filepath.Join(dir, "history.json"), filepath.Join(dir, "incidents.json"), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewScheduler(cfgMgr, NewAnalyzer(histMgr, nil)), histMgr
+}
+
+func TestRemoveMonitorDuringProbe(t *testing.T) {
+	s, histMgr := newTestScheduler(t)
+	m := config.Monitor{ID: "m1", Name: "api", Type: "tcp", Target: "127.0.0.1:1", Timeout: 5, MaxRetries: 3}
+	histMgr.RecordProbe(m.ID, 10, 0, true, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.running[m.ID] = &runningMonitor{ctx: ctx, cancel: cancel, cfg: m}
+	s.mu.Unlock()
+
+	prober := &blockingProber{started: make(chan struct{}), release: make(chan struct{})}
+	probed := make(chan AnalyzeResult)
+	go func() { probed <- s.runProbe(ctx, prober, m, m.Timeout) }()
+	<-prober.started
+
+	removed := make(chan struct{})
+	go func() {
+		s.RemoveMonitor(m.ID)
+		close(removed)
+	}()
+
+	select {
+	case <-removed:
+		t.Fatal("RemoveMonitor returned while a probe was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if histMgr.GetMonitor(m.ID) == nil {
+		t.Fatal("history removed before the in-flight probe ended")
+	}
+
+	close(prober.release)
+	if ar := <-probed; !ar.Skipped {
+		t.Errorf("probe of a removed monitor returned %+v, want it skipped", ar)
+	}
+	select {
+	case <-removed:
+	case <-time.After(time.Second):
+		t.Fatal("RemoveMonitor did not return after the probe ended")
+	}
+
+	if h := histMgr.GetMonitor(m.ID); h != nil {
+		t.Errorf("history still present after removal: %+v", h)
+	}
+	if _, ok := s.analyzer.State(m.ID); ok {
+		t.Error("analyzer state still present after removal")
+	}
+	if s.RunningCount() != 0 {
+		t.Errorf("RunningCount = %d, want 0", s.RunningCount())
+	}
+}
+
+func TestStopFlushesConfirmedState(t *testing.T) {
+	s, histMgr := newTestScheduler(t)
+	m := config.Monitor{ID: "m1", Name: "api", Type: "tcp", Target: "127.0.0.1:1", MaxRetries: 3}
+
+	// One failure is within max_retries: the raw probe says down, the
+	// analyzer still considers the monitor up.
+	s.analyzer.Process(m, ProbeResult{Up: false, Error: "refused"})
+	if h := histMgr.GetMonitor(m.ID); h == nil || h.IsUp {
+		t.Fatalf("history before stop = %+v, want IsUp false from the raw probe", h)
+	}
+
+	s.Stop()
+	if h := histMgr.GetMonitor(m.ID); h == nil || !h.IsUp {
+		t.Errorf("history after stop = %+v, want the confirmed IsUp true", h)
+	}
+}
+
+func TestRunProbeSkipsWhileInFlight(t *testing.T) {
+	s, _ := newTestScheduler(t)
+	m := config.Monitor{ID: "m1", Name: "api", Type: "tcp", Target: "127.0.0.1:1", Timeout: 5}
+
+	if !s.beginProbe(m.ID) {
+		t.Fatal("beginProbe failed on an idle monitor")
+	}
+	ar := s.runProbe(context.Background(), &blockingProber{}, m, m.Timeout)
+	s.endProbe(m.ID)
+	if !ar.Skipped || ar.IsFailing {
+		t.Errorf("runProbe during another probe = %+v, want Skipped", ar)
+	}
+}
@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/makt28/wink/internal/config"
+)
+
+// probeTargets probes each of the monitor's targets and combines the results
+// according to its up_policy. A single target is probed as is.
+func probeTargets(ctx context.Context, prober Prober, m config.Monitor) ProbeResult {
+	targets := m.ProbeTargets()
+	if len(targets) == 1 {
+		return prober.Probe(ctx, targets[0])
+	}
+
+	results := make([]ProbeResult, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = prober.Probe(ctx, target)
+		}()
+	}
+	wg.Wait()
+	return combineResults(m.UpPolicy, targets, results)
+}
+
+// combineResults merges the results of probing several targets. With policy
+// "any" the monitor is up if one target is, and reports the latency of the
+// fastest one; otherwise all must be up and the slowest is reported. A failure
+// lists every failed target, and is a maintenance window only if all of them
+// announced one.
+func combineResults(policy string, targets []string, results []ProbeResult) ProbeResult {
+	var failed []string
+	var first *ProbeResult // first failure, for the error kind
+	var maintenance time.Time
+	inMaintenance := true
+	for i, r := range results {
+		if r.Up {
+			continue
+		}
+		failed = append(failed, config.RedactTarget(targets[i])+": "+r.Error)
+		if first == nil {
+			first = &results[i]
+		}
+		if r.MaintenanceUntil.IsZero() {
+			inMaintenance = false
+		} else if maintenance.IsZero() || r.MaintenanceUntil.Before(maintenance) {
+			maintenance = r.MaintenanceUntil
+		}
+	}
+	up := len(failed) == 0 || (policy == "any" && len(failed) < len(results))
+
+	// Latency and timing come from one of the results matching the outcome.
+	fastest := up && policy == "any"
+	pick := -1
+	for i, r := range results {
+		if r.Up != up {
+			continue
+		}
+		if pick < 0 || (fastest && r.Latency < results[pick].Latency) || (!fastest && r.Latency > results[pick].Latency) {
+			pick = i
+		}
+	}
+
+	combined := ProbeResult{Up: up, Latency: results[pick].Latency, Timing: results[pick].Timing}
+	if !up {
+		combined.Kind = first.Kind
+		combined.Error = fmt.Sprintf("%d/%d targets down: %s", len(failed), len(results), strings.Join(failed, "; "))
+		if inMaintenance {
+			combined.MaintenanceUntil = maintenance
+		}
+	}
+	return combined
+}
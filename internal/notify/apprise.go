@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// appriseKeyRe matches the configuration keys the Apprise API accepts.
+var appriseKeyRe = regexp.MustCompile(`^[\w-]{1,128}$`)
+
+// AppriseNotifier sends alerts through an Apprise API server, which relays
+// them to any of the services Apprise supports. It either names the services
+// itself (URLs, stateless) or a configuration stored on the server (ConfigKey).
+type AppriseNotifier struct {
+	Server    string // base URL of the Apprise API, e.g. http://apprise:8000
+	ConfigKey string // key of a configuration saved on the server
+	URLs      string // Apprise service URLs, comma- or newline-separated
+	Remark    string
+	Timeout   time.Duration // HTTP client timeout; zero uses defaultSendTimeout
+}
+
+func (a *AppriseNotifier) Type() string { return "apprise" }
+
+func (a *AppriseNotifier) Validate() error {
+	if err := validateWebhookURL(a.Server); err != nil {
+		return fmt.Errorf("apprise: %w", err)
+	}
+	switch {
+	case a.ConfigKey == "" && strings.TrimSpace(a.URLs) == "":
+		return errors.New("apprise: a config key or service urls are required")
+	case a.ConfigKey != "" && strings.TrimSpace(a.URLs) != "":
+		return errors.New("apprise: set either a config key or service urls, not both")
+	case a.ConfigKey != "" && !appriseKeyRe.MatchString(a.ConfigKey):
+		return errors.New("apprise: config key may only contain letters, digits, _ and -")
+	}
+	return nil
+}
+
+// appriseType maps an event to the Apprise notification type, which services
+// use for the icon and color.
+func appriseType(eventType string) string {
+	switch eventType {
+	case "down":
+		return "failure"
+	case "anomaly", "slow":
+		return "warning"
+	default:
+		return "success"
+	}
+}
+
+func (a *AppriseNotifier) Send(ctx context.Context, event AlertEvent) error {
+	title, body := formatPlainMessage(event, a.Remark)
+	payload := map[string]string{
+		"title": title,
+		"body":  body,
+		"type":  appriseType(event.Type),
+	}
+	endpoint := strings.TrimRight(a.Server, "/") + "/notify/"
+	if a.ConfigKey != "" {
+		endpoint += url.PathEscape(a.ConfigKey)
+	} else {
+		payload["urls"] = strings.Join(strings.Fields(strings.ReplaceAll(a.URLs, ",", " ")), ",")
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("apprise: marshal payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("apprise: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := doRequest(req, a.Timeout)
+	if err != nil {
+		return fmt.Errorf("apprise: send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The API answers 204 when the key has no configuration: nothing was sent.
+	if resp.StatusCode/100 != 2 || resp.StatusCode == http.StatusNoContent {
+		return &AppriseError{StatusCode: resp.StatusCode, Message: appriseMessage(resp.Body)}
+	}
+	return nil
+}
+
+// appriseMessage extracts the error the Apprise API reported: the "error"
+// field of a JSON answer, or the start of a plain-text one.
+func appriseMessage(r io.Reader) string {
+	raw, _ := io.ReadAll(io.LimitReader(r, 4096))
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(raw, &apiErr) == nil {
+		return apiErr.Error
+	}
+	msg := strings.TrimSpace(string(raw))
+	if strings.HasPrefix(msg, "<") {
+		return "" // an HTML error page says nothing useful in one line
+	}
+	if r := []rune(msg); len(r) > 200 {
+		msg = string(r[:200]) + "..."
+	}
+	return msg
+}
+
+// AppriseError is a notification the Apprise API did not deliver.
+type AppriseError struct {
+	StatusCode int
+	Message    string // error reported by the API, if any
+}
+
+func (e *AppriseError) Error() string {
+	msg := fmt.Sprintf("apprise: unexpected status %d", e.StatusCode)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// NoConfig reports whether the server has no configuration for the key.
+func (e *AppriseError) NoConfig() bool {
+	return e.StatusCode == http.StatusNoContent
+}
+
+// DeliveryFailed reports whether Apprise accepted the request but some of
+// the services it notifies failed.
+func (e *AppriseError) DeliveryFailed() bool {
+	return e.StatusCode == http.StatusFailedDependency
+}
+
+// BadRequest reports whether the request was rejected, usually because of
+// malformed service URLs.
+func (e *AppriseError) BadRequest() bool {
+	return e.StatusCode == http.StatusBadRequest
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBarkServer is the public Bark server used when none is configured.
+const DefaultBarkServer = "https://api.day.app"
+
+// BarkNotifier sends alerts to an iOS device via a Bark server.
+type BarkNotifier struct {
+	Server    string // e.g. https://api.day.app or a self-hosted bark-server
+	DeviceKey string
+	Sound     string // optional Bark sound name
+	Remark    string
+	Timeout   time.Duration // HTTP client timeout; zero uses defaultSendTimeout
+}
+
+func (b *BarkNotifier) Type() string { return "bark" }
+
+func (b *BarkNotifier) Validate() error {
+	if b.DeviceKey == "" {
+		return errors.New("bark: device_key is required")
+	}
+	if b.Server != "" {
+		if err := validateWebhookURL(b.Server); err != nil {
+			return fmt.Errorf("bark: server: %w", err)
+		}
+	}
+	return nil
+}
+
+func (b *BarkNotifier) Send(ctx context.Context, event AlertEvent) error {
+	title, body := formatPlainMessage(event, b.Remark)
+
+	server := strings.TrimRight(b.Server, "/")
+	if server == "" {
+		server = DefaultBarkServer
+	}
+	endpoint := fmt.Sprintf("%s/%s/%s/%s", server,
+		url.PathEscape(b.DeviceKey), url.PathEscape(title), url.PathEscape(body))
+
+	q := url.Values{}
+	q.Set("group", "Wink")
+	// Outages break through Focus modes; recoveries and anomalies do not.
+	if event.Type == "down" {
+		q.Set("level", "timeSensitive")
+	} else {
+		q.Set("level", "active")
+	}
+	if b.Sound != "" {
+		q.Set("sound", b.Sound)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"?"+q.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("bark: create request: %w", err)
+	}
+
+	resp, err := doRequest(req, b.Timeout)
+	if err != nil {
+		return fmt.Errorf("bark: send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bark: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Per-host send rate. Slack and Discord webhooks and the Telegram Bot API all
+// throttle at around one message per second per destination; the burst lets a
+// short flap through undelayed while a storm of alerts is spread out.
+const (
+	hostRate  = 1.0 // tokens per second
+	hostBurst = 5.0
+
+	// hostMaxWait bounds how long a send may queue for a token when its
+	// context has no earlier deadline.
+	hostMaxWait = 2 * time.Minute
+
+	// hostSweepInterval is how often buckets that have refilled, and so behave
+	// like a new one, are dropped from the limiter.
+	hostSweepInterval = time.Minute
+)
+
+// sharedTransport pools connections across every notifier send.
+var sharedTransport = &http.Transport{
+	Proxy:               http.ProxyFromEnvironment,
+	MaxIdleConns:        32,
+	MaxIdleConnsPerHost: 4,
+	IdleConnTimeout:     90 * time.Second,
+	TLSHandshakeTimeout: 10 * time.Second,
+}
+
+var limiter = &hostLimiter{buckets: make(map[string]*tokenBucket)}
+
+// doRequest sends req over the shared transport once the per-host rate limit
+// allows it. Waiting for a token ends with the request context or after
+// hostMaxWait, and the send itself is bounded by timeout, which starts once
+// the token is granted. A caller that wants a storm to queue rather than time
+// out passes a context without a deadline, as Router.Notify does.
+func doRequest(req *http.Request, timeout time.Duration) (*http.Response, error) {
+	waitCtx, cancel := context.WithTimeout(req.Context(), hostMaxWait)
+	err := limiter.wait(waitCtx, req.URL.Host)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("rate limit wait for %s: %w", req.URL.Host, err)
+	}
+	client := &http.Client{Transport: sharedTransport, Timeout: sendTimeout(timeout)}
+	return client.Do(req)
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// hostLimiter is a token bucket per destination host.
+type hostLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// wait takes a token for host, sleeping until one is available or ctx ends.
+// Tokens are reserved up front so concurrent senders queue in arrival order.
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.sweep(now)
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &tokenBucket{tokens: hostBurst, last: now}
+		l.buckets[host] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * hostRate
+	if b.tokens > hostBurst {
+		b.tokens = hostBurst
+	}
+	b.last = now
+	b.tokens--
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / hostRate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		// Hand the reservation back so later sends are not delayed for nothing.
+		l.mu.Lock()
+		b.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// sweep drops buckets that have refilled to the burst, at most once per
+// hostSweepInterval, so hosts that are no longer sent to don't accumulate.
+// l.mu must be held.
+func (l *hostLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < hostSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for host, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*hostRate >= hostBurst {
+			delete(l.buckets, host)
+		}
+	}
+}
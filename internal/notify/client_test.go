@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHostLimiterSweepsRefilledBuckets(t *testing.T) {
+	l := &hostLimiter{buckets: make(map[string]*tokenBucket)}
+	ctx := context.Background()
+	if err := l.wait(ctx, "idle.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.wait(ctx, "busy.example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	// idle refilled long ago; busy is still short of the burst.
+	l.mu.Lock()
+	l.buckets["idle.example.com"].last = time.Now().Add(-time.Hour)
+	l.buckets["busy.example.com"].tokens = -2
+	l.lastSweep = time.Now().Add(-2 * hostSweepInterval)
+	l.sweep(time.Now())
+	_, idle := l.buckets["idle.example.com"]
+	_, busy := l.buckets["busy.example.com"]
+	l.mu.Unlock()
+
+	if idle {
+		t.Error("refilled bucket was not swept")
+	}
+	if !busy {
+		t.Error("bucket short of the burst was swept")
+	}
+}
+
+func TestDoRequestCancelAbortsQueuedSend(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits.Add(1) }))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	// Use up the burst so the next send queues for about a second.
+	for i := 0; i < int(hostBurst); i++ {
+		if err := limiter.wait(context.Background(), host); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	resp, err := doRequest(req, 5*time.Second)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("queued send completed after its context was cancelled")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if waited := time.Since(start); waited > 500*time.Millisecond {
+		t.Errorf("cancelled send returned after %s, want it to stop queuing at once", waited)
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("server got %d requests, want none", n)
+	}
+}
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"time"
+)
+
+// GoogleChatNotifier posts a card to a Google Chat space through an incoming
+// webhook.
+type GoogleChatNotifier struct {
+	URL     string // incoming webhook URL of the space
+	Remark  string
+	Timeout time.Duration // HTTP client timeout; zero uses defaultSendTimeout
+}
+
+func (g *GoogleChatNotifier) Type() string { return "googlechat" }
+
+func (g *GoogleChatNotifier) Validate() error {
+	if err := validateWebhookURL(g.URL); err != nil {
+		return fmt.Errorf("googlechat: %w", err)
+	}
+	return nil
+}
+
+func (g *GoogleChatNotifier) Send(ctx context.Context, event AlertEvent) error {
+	title, _ := formatPlainMessage(event, g.Remark)
+	_, status := eventStatus(event.Type)
+
+	// Card headers can't be colored, so the status line carries the color.
+	widgets := []map[string]interface{}{
+		{"textParagraph": map[string]string{
+			"text": fmt.Sprintf(`<font color="%s"><b>%s</b></font>`, eventColor(event.Type), status),
+		}},
+		chatField("Target", event.Target),
+	}
+	if event.Reason != "" {
+		widgets = append(widgets, chatField("Reason", event.Reason))
+	}
+	if event.Region != "" {
+		widgets = append(widgets, chatField("Region", event.Region))
+	}
+	widgets = append(widgets, chatField("Time", formatEventTime(event)))
+
+	payload := map[string]interface{}{
+		"text": title, // shown in notifications and clients without card support
+		"cardsV2": []map[string]interface{}{{
+			"cardId": "wink",
+			"card": map[string]interface{}{
+				"header":   map[string]string{"title": event.MonitorName, "subtitle": g.Remark},
+				"sections": []map[string]interface{}{{"widgets": widgets}},
+			},
+		}},
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("googlechat: marshal payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.URL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("googlechat: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	resp, err := doRequest(req, g.Timeout)
+	if err != nil {
+		return fmt.Errorf("googlechat: send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("googlechat: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// chatField is a labelled value widget of a Google Chat card.
+func chatField(label, value string) map[string]interface{} {
+	return map[string]interface{}{
+		"decoratedText": map[string]string{"topLabel": label, "text": html.EscapeString(value)},
+	}
+}
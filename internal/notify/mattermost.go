@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// MattermostNotifier posts a message with a colored attachment to a Mattermost
+// channel through an incoming webhook.
+type MattermostNotifier struct {
+	URL     string // incoming webhook URL
+	Remark  string
+	Timeout time.Duration // HTTP client timeout; zero uses defaultSendTimeout
+}
+
+func (m *MattermostNotifier) Type() string { return "mattermost" }
+
+func (m *MattermostNotifier) Validate() error {
+	if err := validateWebhookURL(m.URL); err != nil {
+		return fmt.Errorf("mattermost: %w", err)
+	}
+	return nil
+}
+
+type mattermostField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func (m *MattermostNotifier) Send(ctx context.Context, event AlertEvent) error {
+	title, body := formatPlainMessage(event, m.Remark)
+
+	fields := []mattermostField{{Title: "Target", Value: event.Target}}
+	if event.Reason != "" {
+		fields = append(fields, mattermostField{Title: "Reason", Value: event.Reason})
+	}
+	if event.Region != "" {
+		fields = append(fields, mattermostField{Title: "Region", Value: event.Region, Short: true})
+	}
+	fields = append(fields, mattermostField{Title: "Time", Value: formatEventTime(event), Short: true})
+
+	payload := map[string]interface{}{
+		"username": "Wink",
+		"attachments": []map[string]interface{}{{
+			"fallback": title + "\n" + body,
+			"color":    eventColor(event.Type),
+			"title":    title,
+			"fields":   fields,
+		}},
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("mattermost: marshal payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.URL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("mattermost: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(req, m.Timeout)
+	if err != nil {
+		return fmt.Errorf("mattermost: send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("mattermost: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -1,16 +1,40 @@
 package notify
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// defaultSendTimeout bounds a notifier's HTTP client when no timeout is configured.
+const defaultSendTimeout = 10 * time.Second
+
+// sendTimeout returns d, or defaultSendTimeout when d is unset.
+func sendTimeout(d time.Duration) time.Duration {
+	if d <= 0 {
+		return defaultSendTimeout
+	}
+	return d
+}
 
 // AlertEvent represents a status change event to be sent via notifiers.
 type AlertEvent struct {
 	MonitorID   string
 	MonitorName string
-	Type        string // "down" or "up"
+	Type        string // "down", "up", "anomaly", "slow" or "fast"
 	Target      string
 	Reason      string
+	ErrorKind   string // cause of a "down" event, e.g. "timeout" or "dns" (see monitor.ErrorKind)
 	Timestamp   int64
 	Timezone    string // IANA timezone name, e.g. "Asia/Shanghai"; empty = UTC
+	Region      string // system.region of the instance that ran the probe; empty when unset
+	Reminder    bool   // a repeated "down" alert for a monitor that is still down
+
+	ResponseTimeMs   int     // latency of the probe that triggered the event
+	Uptime24h        float64 // 24h uptime percentage at the time of the event
+	IncidentDuration int64   // seconds the resolved incident lasted ("up" events only)
 }
 
 // Notifier is the interface that all notification channel implementations must satisfy.
@@ -24,3 +48,80 @@ type Notifier interface {
 	// Validate checks whether the notifier configuration is valid.
 	Validate() error
 }
+
+// eventStatus returns the icon and upper-case label used in alert messages.
+func eventStatus(eventType string) (icon, status string) {
+	switch eventType {
+	case "down":
+		return "🔴", "DOWN"
+	case "anomaly":
+		return "🟡", "LATENCY ANOMALY"
+	case "slow":
+		return "🟠", "SLOW"
+	case "fast":
+		return "🟢", "LATENCY OK"
+	default:
+		return "🟢", "UP"
+	}
+}
+
+// eventColor returns the hex color used for an event in services that render
+// colored cards or attachments.
+func eventColor(eventType string) string {
+	switch eventType {
+	case "down":
+		return "#dc2626"
+	case "anomaly":
+		return "#eab308"
+	case "slow":
+		return "#f97316"
+	default:
+		return "#16a34a"
+	}
+}
+
+// validateWebhookURL checks that raw is an absolute http(s) URL.
+func validateWebhookURL(raw string) error {
+	if raw == "" {
+		return errors.New("url is required")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q", raw)
+	}
+	return nil
+}
+
+// formatEventTime renders the event timestamp in its timezone, e.g.
+// "2024-01-02 15:04:05 Asia/Shanghai", falling back to UTC.
+func formatEventTime(event AlertEvent) string {
+	t := time.Unix(event.Timestamp, 0).UTC()
+	tzLabel := "UTC"
+	if event.Timezone != "" {
+		if loc, err := time.LoadLocation(event.Timezone); err == nil {
+			t = t.In(loc)
+			tzLabel = event.Timezone
+		}
+	}
+	return t.Format("2006-01-02 15:04:05") + " " + tzLabel
+}
+
+// formatPlainMessage builds a title and plain-text body for push services
+// that have no markup support.
+func formatPlainMessage(event AlertEvent, remark string) (title, body string) {
+	icon, status := eventStatus(event.Type)
+	title = fmt.Sprintf("%s [%s] %s", icon, status, event.MonitorName)
+	if remark != "" {
+		title = "[" + remark + "] " + title
+	}
+
+	body = "Target: " + event.Target
+	if event.Reason != "" {
+		body += "\nReason: " + event.Reason
+	}
+	if event.Region != "" {
+		body += "\nRegion: " + event.Region
+	}
+	body += "\nTime: " + formatEventTime(event)
+	return title, body
+}
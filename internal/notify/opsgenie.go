@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Opsgenie Alerts API base URLs by account region.
+const (
+	opsgenieAPIUS = "https://api.opsgenie.com"
+	opsgenieAPIEU = "https://api.eu.opsgenie.com"
+)
+
+// opsgenieMessageLimit is the maximum length of an alert message.
+const opsgenieMessageLimit = 130
+
+// OpsgenieNotifier creates an Opsgenie alert when a monitor goes down and closes
+// it when the monitor recovers. Alerts are keyed by an alias derived from the
+// monitor ID, so repeated down alerts are deduplicated by Opsgenie and the up
+// event closes the right alert.
+type OpsgenieNotifier struct {
+	APIKey  string // API key of an Opsgenie API integration
+	Region  string // "us" (default) or "eu"
+	Remark  string
+	Timeout time.Duration // HTTP client timeout; zero uses defaultSendTimeout
+}
+
+func (o *OpsgenieNotifier) Type() string { return "opsgenie" }
+
+func (o *OpsgenieNotifier) Validate() error {
+	if o.APIKey == "" {
+		return errors.New("opsgenie: api_key is required")
+	}
+	if o.Region != "" && o.Region != "us" && o.Region != "eu" {
+		return fmt.Errorf("opsgenie: region must be \"us\" or \"eu\", got %q", o.Region)
+	}
+	return nil
+}
+
+func (o *OpsgenieNotifier) endpoint() string {
+	if o.Region == "eu" {
+		return opsgenieAPIEU
+	}
+	return opsgenieAPIUS
+}
+
+// opsgenieAlias is the dedup key of a monitor's alert. Latency anomalies and
+// slow alerts get their own aliases so a recovery doesn't close them and they
+// don't merge into an outage alert; "fast" closes the slow alert.
+func opsgenieAlias(event AlertEvent) string {
+	id := event.MonitorID
+	if id == "" {
+		id = "test"
+	}
+	switch event.Type {
+	case "anomaly":
+		return "wink-" + id + "-anomaly"
+	case "slow", "fast":
+		return "wink-" + id + "-slow"
+	}
+	return "wink-" + id
+}
+
+func (o *OpsgenieNotifier) Send(ctx context.Context, event AlertEvent) error {
+	title, body := formatPlainMessage(event, o.Remark)
+	alias := opsgenieAlias(event)
+
+	var path string
+	var payload map[string]interface{}
+	if event.Type == "up" || event.Type == "fast" {
+		path = "/v2/alerts/" + url.PathEscape(alias) + "/close?identifierType=alias"
+		payload = map[string]interface{}{
+			"source": "wink",
+			"note":   body,
+		}
+	} else {
+		priority := "P1"
+		if event.Type == "anomaly" || event.Type == "slow" {
+			priority = "P3"
+		}
+		message := []rune(title)
+		if len(message) > opsgenieMessageLimit {
+			message = message[:opsgenieMessageLimit]
+		}
+		details := map[string]string{"target": event.Target}
+		if event.Region != "" {
+			details["region"] = event.Region
+		}
+		payload = map[string]interface{}{
+			"message":     string(message),
+			"alias":       alias,
+			"description": body,
+			"priority":    priority,
+			"source":      "wink",
+			"entity":      event.MonitorName,
+			"details":     details,
+		}
+		path = "/v2/alerts"
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("opsgenie: marshal payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.endpoint()+path, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("opsgenie: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+o.APIKey)
+
+	resp, err := doRequest(req, o.Timeout)
+	if err != nil {
+		return fmt.Errorf("opsgenie: send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Alert requests are processed asynchronously and answered with 202.
+	if resp.StatusCode/100 != 2 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiErr)
+		if apiErr.Message != "" {
+			return fmt.Errorf("opsgenie: unexpected status %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("opsgenie: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pushoverAPI is the Pushover message endpoint.
+const pushoverAPI = "https://api.pushover.net/1/messages.json"
+
+// pushoverPriorityNormal is the priority of everything except down alerts.
+const pushoverPriorityNormal = 0
+
+// PushoverNotifier sends alerts via the Pushover API.
+type PushoverNotifier struct {
+	Token        string // application API token
+	UserKey      string // user or group key
+	DownPriority int    // priority for down alerts; other events are sent at normal priority
+	Sound        string // optional Pushover sound name
+	Remark       string
+	Timeout      time.Duration // HTTP client timeout; zero uses defaultSendTimeout
+}
+
+func (p *PushoverNotifier) Type() string { return "pushover" }
+
+func (p *PushoverNotifier) Validate() error {
+	if p.Token == "" {
+		return errors.New("pushover: token is required")
+	}
+	if p.UserKey == "" {
+		return errors.New("pushover: user_key is required")
+	}
+	if p.DownPriority < -2 || p.DownPriority > 1 {
+		return fmt.Errorf("pushover: priority must be between -2 and 1, got %d", p.DownPriority)
+	}
+	return nil
+}
+
+func (p *PushoverNotifier) Send(ctx context.Context, event AlertEvent) error {
+	title, body := formatPlainMessage(event, p.Remark)
+
+	priority := pushoverPriorityNormal
+	if event.Type == "down" {
+		priority = p.DownPriority
+	}
+
+	form := url.Values{}
+	form.Set("token", p.Token)
+	form.Set("user", p.UserKey)
+	form.Set("title", title)
+	form.Set("message", body)
+	form.Set("priority", strconv.Itoa(priority))
+	form.Set("timestamp", strconv.FormatInt(event.Timestamp, 10))
+	if p.Sound != "" {
+		form.Set("sound", p.Sound)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pushoverAPI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("pushover: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := doRequest(req, p.Timeout)
+	if err != nil {
+		return fmt.Errorf("pushover: send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("pushover: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package notify
 import (
 	"context"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/makt28/wink/internal/config"
@@ -11,57 +12,169 @@ import (
 // Router routes alert events to the appropriate contact group's notifiers.
 type Router struct {
 	cfgMgr *config.Manager
+
+	mu         sync.Mutex
+	lastSent   map[string]time.Time // notifier ID -> last message let through (min_interval_seconds)
+	suppressed map[string]int       // notifier ID -> messages dropped since then
 }
 
 // NewRouter creates a new notification router.
 func NewRouter(cfgMgr *config.Manager) *Router {
-	return &Router{cfgMgr: cfgMgr}
+	return &Router{
+		cfgMgr:     cfgMgr,
+		lastSent:   make(map[string]time.Time),
+		suppressed: make(map[string]int),
+	}
+}
+
+// throttle applies the notifier's min_interval_seconds. It reports whether the
+// event must be dropped and, when it is let through, how many messages were
+// dropped since the previous one. Initial "down" alerts are never dropped but
+// still start a new window; reminders and all other events are throttled.
+func (r *Router) throttle(nc config.NotifierConfig, event AlertEvent) (drop bool, suppressed int) {
+	if nc.MinIntervalSeconds <= 0 {
+		return false, 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	critical := event.Type == "down" && !event.Reminder
+	if !critical && now.Sub(r.lastSent[nc.ID]) < time.Duration(nc.MinIntervalSeconds)*time.Second {
+		r.suppressed[nc.ID]++
+		return true, 0
+	}
+	r.lastSent[nc.ID] = now
+	suppressed = r.suppressed[nc.ID]
+	delete(r.suppressed, nc.ID)
+	return false, suppressed
+}
+
+// Skip reasons reported by Plan for notifiers an event is not sent to.
+const (
+	SkipGroupMuted    = "group_muted"    // the monitor's group is muted
+	SkipEventFiltered = "event_filtered" // the notifier's events filter excludes the event type
+	SkipNotFound      = "not_found"      // no notifier with this ID is configured
+	SkipUnknownType   = "unknown_type"   // the notifier's type is not supported
+)
+
+// Delivery is the routing decision for one of a monitor's notifier_ids.
+type Delivery struct {
+	NotifierID string
+	Notifier   config.NotifierConfig // zero when Skip is SkipNotFound
+	Skip       string                // empty when the event is sent; otherwise one of the Skip constants
+}
+
+// Plan resolves where an event of eventType for the monitor would be sent
+// under cfg, one Delivery per entry of its notifier_ids, in order. Groups only
+// affect routing when muted, which silences all their monitors. Plan sends
+// nothing and ignores min_interval_seconds, which depends on earlier messages.
+func Plan(cfg config.Config, monitorID, eventType string) []Delivery {
+	var notifierIDs []string
+	var groupID string
+	for _, m := range cfg.Monitors {
+		if m.ID == monitorID {
+			notifierIDs = m.NotifierIDs
+			groupID = m.GroupID
+			break
+		}
+	}
+	g, ok := cfg.ContactGroups[groupID]
+	muted := ok && g.Muted
+
+	globalNotifiers := make(map[string]config.NotifierConfig, len(cfg.Notifiers))
+	for _, nc := range cfg.Notifiers {
+		globalNotifiers[nc.ID] = nc
+	}
+
+	deliveries := make([]Delivery, 0, len(notifierIDs))
+	for _, id := range notifierIDs {
+		d := Delivery{NotifierID: id}
+		nc, ok := globalNotifiers[id]
+		switch {
+		case !ok:
+			d.Skip = SkipNotFound
+		case muted:
+			d.Skip = SkipGroupMuted
+		case !nc.WantsEvent(eventType):
+			d.Skip = SkipEventFiltered
+		case BuildNotifier(nc, cfg.System) == nil:
+			d.Skip = SkipUnknownType
+		}
+		d.Notifier = nc
+		deliveries = append(deliveries, d)
+	}
+	return deliveries
 }
 
-// Notify sends an alert event to notifiers selected by the monitor's notifier_ids.
-// Groups are purely visual — notification routing uses the global notifier pool.
-// If notifier_ids is empty, no notifications are sent.
+// Notify sends an alert event to notifiers selected by the monitor's notifier_ids
+// (see Plan). If notifier_ids is empty, no notifications are sent.
 func (r *Router) Notify(event AlertEvent) {
 	cfg := r.cfgMgr.Get()
 
-	// Find the monitor to get its notifier_ids
-	var notifierIDs []string
+	// Find the monitor to get its timezone and group
+	var monitorTZ, groupID string
 	for _, m := range cfg.Monitors {
 		if m.ID == event.MonitorID {
-			notifierIDs = m.NotifierIDs
+			monitorTZ = m.Timezone
+			groupID = m.GroupID
 			break
 		}
 	}
 
-	if len(notifierIDs) == 0 {
-		slog.Debug("monitor has no notifier_ids, skipping notification", "monitor_id", event.MonitorID)
+	// Events of a muted group are dropped, not queued, so unmuting never
+	// replays what happened in between.
+	if g, ok := cfg.ContactGroups[groupID]; ok && g.Muted {
+		slog.Info("group is muted, skipping notification",
+			"group_id", groupID, "monitor_id", event.MonitorID, "event_type", event.Type)
 		return
 	}
 
-	// Build notifier lookup: ID -> NotifierConfig
-	globalNotifiers := make(map[string]config.NotifierConfig, len(cfg.Notifiers))
-	for _, nc := range cfg.Notifiers {
-		globalNotifiers[nc.ID] = nc
+	deliveries := Plan(cfg, event.MonitorID, event.Type)
+	if len(deliveries) == 0 {
+		slog.Debug("monitor has no notifier_ids, skipping notification", "monitor_id", event.MonitorID)
+		return
 	}
 
-	// Set timezone from config
+	// Set timezone from the monitor, falling back to the system setting
 	event.Timezone = cfg.System.Timezone
+	if monitorTZ != "" {
+		event.Timezone = monitorTZ
+	}
+	event.Region = cfg.System.Region
 
 	// Fan-out to matched notifiers
-	for _, id := range notifierIDs {
-		nc, ok := globalNotifiers[id]
-		if !ok {
+	for _, d := range deliveries {
+		id, nc := d.NotifierID, d.Notifier
+		switch d.Skip {
+		case SkipNotFound:
 			slog.Warn("notifier not found", "notifier_id", id, "monitor_id", event.MonitorID)
 			continue
-		}
-		notifier := BuildNotifier(nc)
-		if notifier == nil {
+		case SkipEventFiltered:
+			slog.Debug("notifier filters out event type, skipping",
+				"notifier_id", id, "monitor_id", event.MonitorID, "event_type", event.Type)
+			continue
+		case SkipUnknownType:
 			slog.Error("unknown notifier type", "type", nc.Type, "notifier_id", id)
 			continue
 		}
+		notifier := BuildNotifier(nc, cfg.System)
+		drop, suppressed := r.throttle(nc, event)
+		if drop {
+			slog.Info("notifier is throttled, skipping notification",
+				"notifier_id", id, "monitor_id", event.MonitorID, "event_type", event.Type,
+				"min_interval_seconds", nc.MinIntervalSeconds)
+			continue
+		}
+		if suppressed > 0 {
+			slog.Info("throttled notifications were dropped since the last message",
+				"notifier_id", id, "dropped", suppressed)
+		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		if err := notifier.Send(ctx, event); err != nil {
+		// No deadline: during a storm the send queues for its host's rate
+		// limit (up to hostMaxWait) instead of timing out in the queue. The
+		// notifier's client timeout bounds the send itself.
+		if err := notifier.Send(context.Background(), event); err != nil {
 			slog.Error("notification send failed",
 				"type", nc.Type,
 				"notifier_id", id,
@@ -76,18 +189,26 @@ func (r *Router) Notify(event AlertEvent) {
 				"event_type", event.Type,
 			)
 		}
-		cancel()
 	}
 }
 
-// BuildNotifier constructs a Notifier from a NotifierConfig.
-func BuildNotifier(nc config.NotifierConfig) Notifier {
+// BuildNotifier constructs a Notifier from a NotifierConfig. sys supplies the
+// send timeout, which bounds each send's HTTP client once its rate-limit wait
+// is over, and system-wide notifier defaults.
+func BuildNotifier(nc config.NotifierConfig, sys config.SystemConfig) Notifier {
+	timeout := sys.NotifyTimeout()
 	switch nc.Type {
 	case "telegram":
+		tmpl := nc.MessageTemplate
+		if tmpl == "" {
+			tmpl = sys.TelegramTemplate
+		}
 		return &TelegramNotifier{
 			BotToken: nc.BotToken,
 			ChatID:   nc.ChatID,
 			Remark:   nc.Remark,
+			Template: tmpl,
+			Timeout:  timeout,
 		}
 	case "webhook":
 		method := nc.Method
@@ -95,9 +216,66 @@ func BuildNotifier(nc config.NotifierConfig) Notifier {
 			method = "POST"
 		}
 		return &WebhookNotifier{
-			URL:    nc.URL,
-			Method: method,
-			Remark: nc.Remark,
+			URLs:    nc.WebhookURLs(),
+			Mode:    nc.Delivery,
+			Method:  method,
+			Remark:  nc.Remark,
+			Timeout: timeout,
+
+			Encoding: nc.Encoding,
+		}
+	case "bark":
+		return &BarkNotifier{
+			Server:    nc.URL,
+			DeviceKey: nc.DeviceKey,
+			Sound:     nc.Sound,
+			Remark:    nc.Remark,
+			Timeout:   timeout,
+		}
+	case "pushover":
+		return &PushoverNotifier{
+			Token:        nc.Token,
+			UserKey:      nc.UserKey,
+			DownPriority: nc.DownPriority(),
+			Sound:        nc.Sound,
+			Remark:       nc.Remark,
+			Timeout:      timeout,
+		}
+	case "opsgenie":
+		return &OpsgenieNotifier{
+			APIKey:  nc.APIKey,
+			Region:  nc.Region,
+			Remark:  nc.Remark,
+			Timeout: timeout,
+		}
+	case "googlechat":
+		return &GoogleChatNotifier{
+			URL:     nc.URL,
+			Remark:  nc.Remark,
+			Timeout: timeout,
+		}
+	case "mattermost":
+		return &MattermostNotifier{
+			URL:     nc.URL,
+			Remark:  nc.Remark,
+			Timeout: timeout,
+		}
+	case "twilio":
+		return &TwilioNotifier{
+			AccountSID: nc.AccountSID,
+			AuthToken:  nc.AuthToken,
+			From:       nc.From,
+			To:         nc.Recipients(),
+			Remark:     nc.Remark,
+			Timeout:    timeout,
+		}
+	case "apprise":
+		return &AppriseNotifier{
+			Server:    nc.URL,
+			ConfigKey: nc.ConfigKey,
+			URLs:      nc.AppriseURLs,
+			Remark:    nc.Remark,
+			Timeout:   timeout,
 		}
 	default:
 		return nil
@@ -6,7 +6,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -15,6 +18,8 @@ type TelegramNotifier struct {
 	BotToken string
 	ChatID   string
 	Remark   string
+	Template string        // text/template for the message body; empty uses formatTelegramMessage
+	Timeout  time.Duration // HTTP client timeout; zero uses defaultSendTimeout
 }
 
 func (t *TelegramNotifier) Type() string { return "telegram" }
@@ -31,6 +36,14 @@ func (t *TelegramNotifier) Validate() error {
 
 func (t *TelegramNotifier) Send(ctx context.Context, event AlertEvent) error {
 	text := formatTelegramMessage(event, t.Remark)
+	if t.Template != "" {
+		// A broken template must not drop the alert; send the built-in format instead.
+		if msg, err := renderMessage(t.Template, event, t.Remark); err != nil {
+			slog.Warn("telegram: message template failed, using default format", "error", err)
+		} else {
+			text = msg
+		}
+	}
 
 	payload := map[string]interface{}{
 		"chat_id":    t.ChatID,
@@ -43,6 +56,33 @@ func (t *TelegramNotifier) Send(ctx context.Context, event AlertEvent) error {
 		return fmt.Errorf("telegram: marshal payload: %w", err)
 	}
 
+	err = t.post(ctx, body)
+	var tgErr *TelegramError
+	if !errors.As(err, &tgErr) || !tgErr.RateLimited() || tgErr.RetryAfter <= 0 {
+		return err
+	}
+
+	// Throttled during an alert storm: wait as told and retry once, unless the
+	// send deadline ends first or, without one, the wait exceeds hostMaxWait.
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < tgErr.RetryAfter {
+		return err
+	} else if !ok && tgErr.RetryAfter > hostMaxWait {
+		return err
+	}
+	slog.Warn("telegram: rate limited, retrying", "chat_id", t.ChatID, "retry_after", tgErr.RetryAfter)
+	timer := time.NewTimer(tgErr.RetryAfter)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return err
+	case <-timer.C:
+	}
+	return t.post(ctx, body)
+}
+
+// post sends one sendMessage request. Rejections by the Bot API are returned
+// as *TelegramError.
+func (t *TelegramNotifier) post(ctx context.Context, body []byte) error {
 	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.BotToken)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
@@ -50,28 +90,65 @@ func (t *TelegramNotifier) Send(ctx context.Context, event AlertEvent) error {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := doRequest(req, t.Timeout)
 	if err != nil {
 		return fmt.Errorf("telegram: send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram: unexpected status %d", resp.StatusCode)
+		var apiResp struct {
+			Description string `json:"description"`
+			Parameters  struct {
+				RetryAfter int `json:"retry_after"`
+			} `json:"parameters"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiResp)
+		return &TelegramError{
+			StatusCode:  resp.StatusCode,
+			Description: apiResp.Description,
+			RetryAfter:  time.Duration(apiResp.Parameters.RetryAfter) * time.Second,
+		}
 	}
 	return nil
 }
 
-func formatTelegramMessage(event AlertEvent, remark string) string {
-	var icon, status string
-	if event.Type == "down" {
-		icon = "🔴"
-		status = "DOWN"
-	} else {
-		icon = "🟢"
-		status = "UP"
+// TelegramError is a request rejected by the Telegram Bot API.
+type TelegramError struct {
+	StatusCode  int
+	Description string        // "description" from the response, e.g. "Bad Request: chat not found"
+	RetryAfter  time.Duration // how long Telegram asked to wait; set on 429
+}
+
+func (e *TelegramError) Error() string {
+	msg := fmt.Sprintf("telegram: unexpected status %d", e.StatusCode)
+	if e.Description != "" {
+		msg += ": " + e.Description
+	} else if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %s)", e.RetryAfter)
 	}
+	return msg
+}
+
+// RateLimited reports whether Telegram throttled the request.
+func (e *TelegramError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// Unauthorized reports whether the bot token was rejected.
+func (e *TelegramError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+// ChatUnavailable reports whether the chat does not exist or the bot cannot
+// post to it (never started, kicked, or lacking rights).
+func (e *TelegramError) ChatUnavailable() bool {
+	return e.StatusCode == http.StatusForbidden ||
+		(e.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(e.Description), "chat not found"))
+}
+
+func formatTelegramMessage(event AlertEvent, remark string) string {
+	icon, status := eventStatus(event.Type)
 
 	var msg string
 	if remark != "" {
@@ -85,15 +162,11 @@ func formatTelegramMessage(event AlertEvent, remark string) string {
 		msg += fmt.Sprintf("\nReason: %s", event.Reason)
 	}
 
-	t := time.Unix(event.Timestamp, 0)
-	tzLabel := "UTC"
-	if event.Timezone != "" {
-		if loc, err := time.LoadLocation(event.Timezone); err == nil {
-			t = t.In(loc)
-			tzLabel = event.Timezone
-		}
+	if event.Region != "" {
+		msg += fmt.Sprintf("\nRegion: %s", event.Region)
 	}
-	msg += fmt.Sprintf("\nTime: %s %s", t.Format("2006-01-02 15:04:05"), tzLabel)
+
+	msg += "\nTime: " + formatEventTime(event)
 
 	return msg
 }
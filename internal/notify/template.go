@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// messageData is what a message template is executed with: every AlertEvent
+// field plus the notifier remark and the event time in the event's timezone.
+type messageData struct {
+	AlertEvent
+	Remark string
+	Time   string // e.g. "2024-01-02 15:04:05 Asia/Shanghai"
+	Icon   string // 🔴, 🟢 or 🟡
+	Status string // DOWN, UP or LATENCY ANOMALY
+}
+
+// templateFuncs are the helpers available to message templates, in addition to
+// the text/template builtins (html, printf, ...).
+var templateFuncs = template.FuncMap{
+	// formatTime renders a unix timestamp in an IANA timezone (empty = UTC).
+	"formatTime": func(ts int64, tz string) string {
+		return formatEventTime(AlertEvent{Timestamp: ts, Timezone: tz})
+	},
+	"icon": func(eventType string) string {
+		icon, _ := eventStatus(eventType)
+		return icon
+	},
+	"status": func(eventType string) string {
+		_, status := eventStatus(eventType)
+		return status
+	},
+}
+
+// sampleEvent is used to check that a template executes, not just parses, so
+// references to unknown fields are caught on save.
+var sampleEvent = AlertEvent{
+	MonitorID:   "sample",
+	MonitorName: "Sample",
+	Type:        "down",
+	Target:      "https://example.com",
+	Reason:      "HTTP 503",
+	ErrorKind:   "http_status",
+	Timestamp:   time.Now().Unix(),
+}
+
+// ValidateMessageTemplate parses text and executes it against a sample event.
+// An empty template is valid and means the built-in format.
+func ValidateMessageTemplate(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	tmpl, err := template.New("message").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(io.Discard, newMessageData(sampleEvent, "remark"))
+}
+
+// renderMessage executes the message template text for event.
+func renderMessage(text string, event AlertEvent, remark string) (string, error) {
+	tmpl, err := template.New("message").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, newMessageData(event, remark)); err != nil {
+		return "", err
+	}
+	msg := strings.TrimSpace(buf.String())
+	if msg == "" {
+		return "", fmt.Errorf("template rendered an empty message")
+	}
+	return msg, nil
+}
+
+func newMessageData(event AlertEvent, remark string) messageData {
+	icon, status := eventStatus(event.Type)
+	return messageData{
+		AlertEvent: event,
+		Remark:     remark,
+		Time:       formatEventTime(event),
+		Icon:       icon,
+		Status:     status,
+	}
+}
+
+// isURLTemplate reports whether a webhook URL has placeholders to render per
+// event; any other URL is used literally.
+func isURLTemplate(raw string) bool {
+	return strings.Contains(raw, "{{")
+}
+
+// renderURL executes the webhook URL template raw for event. The output of
+// every action is escaped so a field cannot add path segments or query
+// parameters, and the result must still be an absolute http(s) URL.
+func renderURL(raw string, event AlertEvent, remark string) (string, error) {
+	if !isURLTemplate(raw) {
+		return raw, nil
+	}
+	tmpl, err := template.New("url").Funcs(templateFuncs).
+		Funcs(template.FuncMap{"escapeURLPart": escapeURLPart}).Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	escapeActions(tmpl.Tree, tmpl.Tree.Root)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, newMessageData(event, remark)); err != nil {
+		return "", err
+	}
+	rendered := strings.TrimSpace(buf.String())
+	if err := validateWebhookURL(rendered); err != nil {
+		return "", err
+	}
+	return rendered, nil
+}
+
+// escapeActions pipes the output of every action in list through
+// escapeURLPart, the way html/template adds its escapers. Actions that only
+// declare variables print nothing and are left alone.
+func escapeActions(tree *parse.Tree, list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+	for _, n := range list.Nodes {
+		switch n := n.(type) {
+		case *parse.ActionNode:
+			if len(n.Pipe.Decl) == 0 {
+				escaper := parse.NewIdentifier("escapeURLPart").SetTree(tree).SetPos(n.Pos)
+				n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos, Args: []parse.Node{escaper}})
+			}
+		case *parse.IfNode:
+			escapeActions(tree, n.List)
+			escapeActions(tree, n.ElseList)
+		case *parse.RangeNode:
+			escapeActions(tree, n.List)
+			escapeActions(tree, n.ElseList)
+		case *parse.WithNode:
+			escapeActions(tree, n.List)
+			escapeActions(tree, n.ElseList)
+		}
+	}
+}
+
+// escapeURLPart percent-encodes v for use as a path segment or query value.
+func escapeURLPart(v any) string {
+	return strings.ReplaceAll(url.QueryEscape(fmt.Sprint(v)), "+", "%20")
+}
@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// twilioAPI is the base URL of the Twilio REST API.
+const twilioAPI = "https://api.twilio.com/2010-04-01"
+
+// twilioBodyLimit caps the SMS text so an alert fits in a single GSM-7 segment.
+const twilioBodyLimit = 160
+
+// Twilio error codes that point at a setting to fix.
+const (
+	twilioCodeAuth         = 20003 // account SID or auth token rejected
+	twilioCodeInvalidTo    = 21211 // recipient is not a valid phone number
+	twilioCodeInvalidFrom  = 21212 // sender is not a valid phone number
+	twilioCodeFromNotOwned = 21606 // sender is not an SMS-capable number of the account
+	twilioCodeUnverified   = 21608 // trial accounts can only text verified numbers
+)
+
+var (
+	twilioSIDRe   = regexp.MustCompile(`^AC[0-9a-fA-F]{32}$`)
+	twilioPhoneRe = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`) // E.164
+)
+
+// TwilioNotifier sends alerts as SMS through the Twilio Messages API, one
+// message per recipient.
+type TwilioNotifier struct {
+	AccountSID string
+	AuthToken  string
+	From       string   // E.164 number or alphanumeric sender ID
+	To         []string // E.164 recipient numbers
+	Remark     string
+	Timeout    time.Duration // HTTP client timeout; zero uses defaultSendTimeout
+}
+
+func (t *TwilioNotifier) Type() string { return "twilio" }
+
+func (t *TwilioNotifier) Validate() error {
+	if !twilioSIDRe.MatchString(t.AccountSID) {
+		return errors.New("twilio: account_sid must be AC followed by 32 hex characters")
+	}
+	if t.AuthToken == "" {
+		return errors.New("twilio: auth_token is required")
+	}
+	if t.From == "" {
+		return errors.New("twilio: from is required")
+	}
+	if len(t.To) == 0 {
+		return errors.New("twilio: to is required")
+	}
+	for _, to := range t.To {
+		if !twilioPhoneRe.MatchString(to) {
+			return fmt.Errorf("twilio: %q is not an E.164 phone number (e.g. +14155550100)", to)
+		}
+	}
+	return nil
+}
+
+// Send texts every recipient. It fails if any message is rejected, reporting
+// the first error, but still tries the remaining recipients.
+func (t *TwilioNotifier) Send(ctx context.Context, event AlertEvent) error {
+	body := formatSMS(event, t.Remark)
+	var firstErr error
+	failed := 0
+	for _, to := range t.To {
+		if err := t.post(ctx, to, body); err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if failed > 1 {
+		return fmt.Errorf("twilio: %d of %d messages failed, first: %w", failed, len(t.To), firstErr)
+	}
+	return firstErr
+}
+
+// post sends one message. Rejections by the API are returned as *TwilioError.
+func (t *TwilioNotifier) post(ctx context.Context, to, body string) error {
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("From", t.From)
+	form.Set("Body", body)
+
+	endpoint := twilioAPI + "/Accounts/" + url.PathEscape(t.AccountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("twilio: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(t.AccountSID, t.AuthToken)
+
+	resp, err := doRequest(req, t.Timeout)
+	if err != nil {
+		return fmt.Errorf("twilio: send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		var apiErr struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiErr)
+		return &TwilioError{StatusCode: resp.StatusCode, Code: apiErr.Code, Message: apiErr.Message, To: to}
+	}
+	return nil
+}
+
+// TwilioError is a message rejected by the Twilio API.
+type TwilioError struct {
+	StatusCode int
+	Code       int    // Twilio error code, e.g. 21211; 0 if the response had none
+	Message    string // "message" from the response
+	To         string // recipient of the rejected message
+}
+
+func (e *TwilioError) Error() string {
+	msg := fmt.Sprintf("twilio: unexpected status %d", e.StatusCode)
+	if e.Code != 0 {
+		msg += fmt.Sprintf(": error %d", e.Code)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg + " (to " + e.To + ")"
+}
+
+// Unauthorized reports whether the account SID or auth token was rejected.
+func (e *TwilioError) Unauthorized() bool {
+	return e.Code == twilioCodeAuth || e.StatusCode == http.StatusUnauthorized
+}
+
+// BadRecipient reports whether the "to" number was rejected.
+func (e *TwilioError) BadRecipient() bool {
+	return e.Code == twilioCodeInvalidTo
+}
+
+// BadSender reports whether the "from" number is invalid or not one of the
+// account's SMS-capable numbers.
+func (e *TwilioError) BadSender() bool {
+	return e.Code == twilioCodeInvalidFrom || e.Code == twilioCodeFromNotOwned
+}
+
+// Unverified reports whether a trial account tried to text a number that is
+// not verified on it.
+func (e *TwilioError) Unverified() bool {
+	return e.Code == twilioCodeUnverified
+}
+
+// formatSMS builds a short plain-text alert, e.g. "DOWN: API - HTTP 503", cut
+// to twilioBodyLimit. Emoji are left out: they switch the whole message to
+// UCS-2, which cuts a segment to 70 characters.
+func formatSMS(event AlertEvent, remark string) string {
+	_, status := eventStatus(event.Type)
+	msg := status + ": " + event.MonitorName
+	if event.Reason != "" {
+		msg += " - " + event.Reason
+	}
+	if remark != "" {
+		msg = "[" + remark + "] " + msg
+	}
+	if r := []rune(msg); len(r) > twilioBodyLimit {
+		msg = string(r[:twilioBodyLimit-3]) + "..."
+	}
+	return msg
+}
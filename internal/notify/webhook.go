@@ -6,30 +6,78 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
 )
 
-// WebhookNotifier sends alerts via an HTTP webhook.
+// WebhookNotifier sends alerts via an HTTP webhook to one or more URLs.
 type WebhookNotifier struct {
-	URL    string
-	Method string
-	Remark string
+	URLs    []string // each may hold placeholders such as {{.MonitorID}}, rendered per event
+	Mode    string   // with several URLs: "any" (default) succeeds if one delivery does, "all" needs every one
+	Method  string
+	Remark  string
+	Timeout time.Duration // HTTP client timeout; zero uses defaultSendTimeout
+
+	// Encoding places the fields: "json" (default) or "form" encodes them as
+	// the body, "query" appends them to the URL and sends no body.
+	Encoding string
+}
+
+// DeliveryResult is the outcome of delivering an event to one webhook URL.
+type DeliveryResult struct {
+	URL   string `json:"url"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
 }
 
 func (w *WebhookNotifier) Type() string { return "webhook" }
 
 func (w *WebhookNotifier) Validate() error {
-	if w.URL == "" {
+	if len(w.URLs) == 0 {
 		return errors.New("webhook: url is required")
 	}
-	if w.Method == "" {
-		return errors.New("webhook: method is required")
+	for _, u := range w.URLs {
+		if isURLTemplate(u) {
+			// Render a sample so unknown fields and a result that is not
+			// a URL are caught on save, not on the first alert.
+			if _, err := renderURL(u, sampleEvent, w.Remark); err != nil {
+				return fmt.Errorf("webhook: url template: %w", err)
+			}
+			continue
+		}
+		if err := validateWebhookURL(u); err != nil {
+			return fmt.Errorf("webhook: %w", err)
+		}
+	}
+	if w.Method != "POST" && w.Method != "GET" {
+		return fmt.Errorf("webhook: method must be POST or GET, got %q", w.Method)
+	}
+	if w.Mode != "" && w.Mode != "any" && w.Mode != "all" {
+		return fmt.Errorf("webhook: delivery must be \"any\" or \"all\", got %q", w.Mode)
+	}
+	switch w.Encoding {
+	case "", "json", "query":
+	case "form":
+		// A GET body is dropped by most servers and proxies.
+		if w.Method != "POST" {
+			return errors.New("webhook: form encoding needs method POST; use query to send the fields with GET")
+		}
+	default:
+		return fmt.Errorf("webhook: encoding must be \"json\", \"form\" or \"query\", got %q", w.Encoding)
 	}
 	return nil
 }
 
 func (w *WebhookNotifier) Send(ctx context.Context, event AlertEvent) error {
+	_, err := w.Deliver(ctx, event)
+	return err
+}
+
+// Deliver sends the event to every URL in turn and reports each outcome. The
+// error is nil if at least one delivery succeeded, or all of them in "all" mode.
+func (w *WebhookNotifier) Deliver(ctx context.Context, event AlertEvent) ([]DeliveryResult, error) {
 	payload := map[string]interface{}{
 		"monitor_id":   event.MonitorID,
 		"monitor_name": event.MonitorName,
@@ -37,24 +85,88 @@ func (w *WebhookNotifier) Send(ctx context.Context, event AlertEvent) error {
 		"target":       event.Target,
 		"reason":       event.Reason,
 		"timestamp":    event.Timestamp,
+
+		"response_time_ms": event.ResponseTimeMs,
+		"uptime_24h":       event.Uptime24h,
+	}
+	if event.Type == "up" && event.IncidentDuration > 0 {
+		payload["incident_duration"] = event.IncidentDuration
 	}
 	if w.Remark != "" {
 		payload["remark"] = w.Remark
 	}
+	if event.Region != "" {
+		payload["region"] = event.Region
+	}
+	if event.ErrorKind != "" {
+		payload["error_kind"] = event.ErrorKind
+	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("webhook: marshal payload: %w", err)
+	var body []byte
+	var contentType string
+	var query url.Values
+	switch w.Encoding {
+	case "form":
+		body, contentType = []byte(payloadValues(payload).Encode()), "application/x-www-form-urlencoded"
+	case "query":
+		query = payloadValues(payload)
+	default:
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: marshal payload: %w", err)
+		}
+		body, contentType = b, "application/json"
 	}
 
-	req, err := http.NewRequestWithContext(ctx, w.Method, w.URL, bytes.NewReader(body))
+	results := make([]DeliveryResult, 0, len(w.URLs))
+	var firstErr error
+	failed := 0
+	for _, u := range w.URLs {
+		res := DeliveryResult{URL: u, OK: true}
+		target, err := renderURL(u, event, w.Remark)
+		if err != nil {
+			err = fmt.Errorf("webhook: url template: %w", err)
+		} else {
+			res.URL = target
+			if query != nil {
+				target = withQuery(target, query)
+			}
+			err = w.post(ctx, target, body, contentType)
+		}
+		if err != nil {
+			res.OK, res.Error = false, err.Error()
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		results = append(results, res)
+	}
+
+	switch {
+	case failed == 0:
+		return results, nil
+	case len(w.URLs) == 1:
+		return results, firstErr
+	case failed == len(w.URLs) || w.Mode == "all":
+		return results, fmt.Errorf("webhook: %d of %d deliveries failed, first: %w", failed, len(w.URLs), firstErr)
+	default:
+		slog.Warn("webhook delivery partially failed", "failed", failed, "total", len(w.URLs), "error", firstErr)
+		return results, nil
+	}
+}
+
+// post sends one encoded payload to url; a nil body sends none.
+func (w *WebhookNotifier) post(ctx context.Context, url string, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, w.Method, url, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("webhook: create request: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := doRequest(req, w.Timeout)
 	if err != nil {
 		return fmt.Errorf("webhook: send request: %w", err)
 	}
@@ -65,3 +177,27 @@ func (w *WebhookNotifier) Send(ctx context.Context, event AlertEvent) error {
 	}
 	return nil
 }
+
+// payloadValues flattens the payload fields into form values.
+func payloadValues(payload map[string]interface{}) url.Values {
+	v := make(url.Values, len(payload))
+	for key, val := range payload {
+		v.Set(key, fmt.Sprint(val))
+	}
+	return v
+}
+
+// withQuery adds the fields to the query of rawURL, keeping the parameters it
+// already has. Validated URLs always parse; should one not, it is left as is.
+func withQuery(rawURL string, fields url.Values) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	q := u.Query()
+	for key, vals := range fields {
+		q[key] = vals
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,122 @@
+// Package semver compares semantic version strings such as "1.2.3",
+// "v0.1.10" or "2.0.0-rc.1+build.5", following the precedence rules of
+// semver 2.0.0. Missing minor or patch numbers are treated as 0.
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version. Build metadata is dropped since it
+// does not affect precedence.
+type Version struct {
+	Major, Minor, Patch int
+	Pre                 []string // dot-separated pre-release identifiers; nil for a release
+}
+
+// Parse parses s, accepting an optional leading "v".
+func Parse(s string) (Version, error) {
+	var v Version
+	rest := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(rest, '+'); i >= 0 {
+		rest = rest[:i]
+	}
+	if i := strings.IndexByte(rest, '-'); i >= 0 {
+		pre := rest[i+1:]
+		rest = rest[:i]
+		if pre == "" {
+			return v, fmt.Errorf("invalid version %q: empty pre-release", s)
+		}
+		v.Pre = strings.Split(pre, ".")
+		for _, id := range v.Pre {
+			if id == "" {
+				return v, fmt.Errorf("invalid version %q: empty pre-release identifier", s)
+			}
+		}
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) > 3 {
+		return v, fmt.Errorf("invalid version %q: too many components", s)
+	}
+	nums := [3]int{}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version %q", s)
+		}
+		nums[i] = n
+	}
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 as a is lower than, equal to or higher than b.
+func (a Version) Compare(b Version) int {
+	if c := compareInt(a.Major, b.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(a.Minor, b.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(a.Patch, b.Patch); c != 0 {
+		return c
+	}
+
+	// A release ranks above any of its pre-releases.
+	switch {
+	case a.Pre == nil && b.Pre == nil:
+		return 0
+	case a.Pre == nil:
+		return 1
+	case b.Pre == nil:
+		return -1
+	}
+	for i := 0; i < len(a.Pre) && i < len(b.Pre); i++ {
+		if c := compareIdent(a.Pre[i], b.Pre[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(a.Pre), len(b.Pre))
+}
+
+// Compare parses and compares two version strings.
+func Compare(a, b string) (int, error) {
+	va, err := Parse(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := Parse(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
+
+// compareIdent orders pre-release identifiers: numeric ones numerically and
+// below alphanumeric ones, which compare in ASCII order.
+func compareIdent(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareInt(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -1,13 +1,19 @@
 package storage
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/makt28/wink/internal/backup"
 )
 
 const CurrentHistoryVersion = 1
@@ -15,6 +21,10 @@ const CurrentHistoryVersion = 1
 // incidentRetention is how long incidents are kept (30 days).
 const incidentRetention = 30 * 24 * time.Hour
 
+// AutoClosedReason prefixes the reason of incidents closed by
+// SetIncidentMaxOpen rather than by a recovery.
+const AutoClosedReason = "auto-closed: exceeded max open duration"
+
 // HistoryData is the root structure persisted in history.json (latency only).
 type HistoryData struct {
 	Version      int                        `json:"version"`
@@ -38,14 +48,43 @@ type MonitorHistory struct {
 	LatencyHistory []LatencyPoint `json:"latency_history"`
 	Incidents      []Incident     `json:"incidents,omitempty"`
 	LastCheckTime  int64          `json:"last_check_time"`
+	LastUpTime     int64          `json:"last_up_time,omitempty"` // last successful probe; 0 if none is known
 	IsUp           bool           `json:"is_up"`
+	RecentErrors   []ProbeError   `json:"recent_errors,omitempty"` // newest last, capped at maxRecentErrors
+
+	// MaintenanceUntil is when the maintenance window announced by the target
+	// ends (unix seconds); 0 when not in maintenance. See RecordMaintenance.
+	MaintenanceUntil int64 `json:"maintenance_until,omitempty"`
+
+	// LastTiming is the phase breakdown of the latest traced HTTP probe
+	// (trace_timing); nil when none was recorded. See RecordTiming.
+	LastTiming *ProbeTiming `json:"last_timing,omitempty"`
+}
+
+// ProbeTiming breaks the latency of an HTTP probe down into its phases.
+type ProbeTiming struct {
+	Time      int64 `json:"t"`
+	DNSMs     int   `json:"dns_ms"`
+	ConnectMs int   `json:"connect_ms"`
+	TLSMs     int   `json:"tls_ms"`
+	TTFBMs    int   `json:"ttfb_ms"` // from the request being sent to the first response byte
+}
+
+// ProbeError is the error message of a single failed probe.
+type ProbeError struct {
+	Time int64  `json:"t"`
+	Msg  string `json:"msg"`
 }
 
+// maxRecentErrors bounds how many failed-probe messages are kept per monitor.
+const maxRecentErrors = 20
+
 // LatencyPoint is a single probe result with timestamp.
 type LatencyPoint struct {
 	Time    int64 `json:"t"`
 	Latency int   `json:"v"`
 	Up      bool  `json:"up"`
+	Raw     int   `json:"raw,omitempty"` // measured latency when Latency was clamped to the monitor's cap
 }
 
 // Incident records a DOWN/UP state transition.
@@ -55,6 +94,8 @@ type Incident struct {
 	ResolvedAt *int64 `json:"resolved_at"`
 	Duration   int64  `json:"duration"`
 	Reason     string `json:"reason"`
+	Category   string `json:"category,omitempty"` // normalized cause, e.g. "timeout" or "http_5xx"
+	Kind       string `json:"kind,omitempty"`     // error kind reported by the prober, e.g. "dns" or "http_status"
 }
 
 // HistoryManager manages in-memory history state with periodic and event-driven persistence.
@@ -64,7 +105,31 @@ type HistoryManager struct {
 	incidents     map[string][]Incident
 	filePath      string
 	incidentsPath string
-	maxHistoryPts int
+	maxHistoryPts int           // guarded by mu
+	retention     time.Duration // guarded by mu; when set, points are kept by age instead of count
+	maxOpen       time.Duration // guarded by mu; when set, older open incidents are closed on Dump
+	backupCount   int
+
+	// Dirty flags, guarded by mu: set when in-memory state diverges from the
+	// corresponding file, cleared when a dump takes a snapshot of it.
+	historyDirty   bool
+	incidentsDirty bool
+
+	dumpMu sync.Mutex // serializes Dump so backup rotation and writes don't interleave
+
+	loadFailed bool // history.json could not be loaded at startup; the reason is logged
+
+	statusMu     sync.Mutex
+	lastDumpOK   int64  // unix time of the last successful Dump
+	lastDumpErr  string // error from the most recent Dump, "" if it succeeded
+	dumpFailures int    // consecutive failed Dumps
+}
+
+// DumpStatus reports the outcome of recent persistence attempts.
+type DumpStatus struct {
+	LastSuccess         int64  `json:"last_success"`
+	LastError           string `json:"last_error,omitempty"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
 }
 
 // NewHistoryManager loads history and incidents from disk or creates empty state.
@@ -83,10 +148,23 @@ func NewHistoryManager(filePath string, incidentsPath string, maxHistoryPoints i
 			Version:  CurrentHistoryVersion,
 			Monitors: make(map[string]*MonitorHistory),
 		}
-	} else {
-		if err := hm.loadHistory(); err != nil {
-			return nil, fmt.Errorf("load history: %w", err)
+	} else if err := hm.loadHistory(); errors.Is(err, errCorrupt) {
+		// A crash mid-write can leave a partial file; keep the service available
+		// rather than refusing to start, and report it on /healthz.
+		dst, qerr := quarantine(filePath)
+		if qerr != nil {
+			return nil, fmt.Errorf("load history: %w (moving it aside failed: %v)", err, qerr)
+		}
+		slog.Error("history file is corrupt, starting with empty history", "path", filePath, "moved_to", dst, "error", err)
+		hm.loadFailed = true
+		hm.data = HistoryData{
+			Version:  CurrentHistoryVersion,
+			Monitors: make(map[string]*MonitorHistory),
 		}
+	} else if err != nil {
+		return nil, fmt.Errorf("load history: %w", err)
+	} else {
+		hm.lastDumpOK = hm.data.LastDumpTime
 	}
 
 	// Load incidents.json
@@ -109,6 +187,8 @@ func (hm *HistoryManager) migrateIncidentsFromHistory() {
 		if len(h.Incidents) > 0 {
 			hm.incidents[id] = h.Incidents
 			h.Incidents = nil
+			hm.historyDirty = true
+			hm.incidentsDirty = true
 		}
 	}
 }
@@ -145,51 +225,119 @@ func (hm *HistoryManager) GetAll() map[string]MonitorHistory {
 	return result
 }
 
-// RecordProbe appends a latency point and updates status.
-func (hm *HistoryManager) RecordProbe(monitorID string, latencyMs int, up bool) {
+// RecordProbe appends a latency point and updates status. With capMs > 0, a
+// higher latency is stored as capMs and the measured value as the point's Raw.
+// For failed probes, errMsg is also kept in the monitor's bounded RecentErrors list.
+func (hm *HistoryManager) RecordProbe(monitorID string, latencyMs, capMs int, up bool, errMsg string) {
 	hm.mu.Lock()
 	defer hm.mu.Unlock()
 
 	h := hm.ensureMonitor(monitorID)
-	h.LatencyHistory = append(h.LatencyHistory, LatencyPoint{
+	p := LatencyPoint{
 		Time:    time.Now().Unix(),
 		Latency: latencyMs,
 		Up:      up,
-	})
+	}
+	if capMs > 0 && latencyMs > capMs {
+		p.Latency, p.Raw = capMs, latencyMs
+	}
+	h.LatencyHistory = append(h.LatencyHistory, p)
 
-	// Ring buffer: trim to max
-	if len(h.LatencyHistory) > hm.maxHistoryPts {
-		excess := len(h.LatencyHistory) - hm.maxHistoryPts
-		h.LatencyHistory = h.LatencyHistory[excess:]
+	if !up {
+		h.RecentErrors = append(h.RecentErrors, ProbeError{Time: time.Now().Unix(), Msg: errMsg})
+		if len(h.RecentErrors) > maxRecentErrors {
+			h.RecentErrors = h.RecentErrors[len(h.RecentErrors)-maxRecentErrors:]
+		}
 	}
 
+	hm.trim(h, time.Now().Unix())
+
 	h.LastCheckTime = time.Now().Unix()
+	if up {
+		h.LastUpTime = h.LastCheckTime
+	}
 	h.IsUp = up
+	h.MaintenanceUntil = 0
+	hm.recalcUptime(h)
+	hm.historyDirty = true
+}
+
+// RecordMaintenance notes a probe that ran during a maintenance window ending at
+// until (unix seconds). Like maintenance beats imported from Uptime Kuma, it adds
+// no latency point, so the window counts neither for nor against uptime.
+func (hm *HistoryManager) RecordMaintenance(monitorID string, until int64) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	h := hm.ensureMonitor(monitorID)
+	h.LastCheckTime = time.Now().Unix()
+	h.MaintenanceUntil = until
+	hm.recalcUptime(h)
+	hm.historyDirty = true
+}
+
+// RecordTiming replaces the monitor's latest probe timing breakdown.
+func (hm *HistoryManager) RecordTiming(monitorID string, t ProbeTiming) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	h := hm.ensureMonitor(monitorID)
+	h.LastTiming = &t
+	hm.historyDirty = true
+}
+
+// ImportHistory merges externally sourced latency points into a monitor's history,
+// applying the retention limit, and derives the current state from the last point.
+func (hm *HistoryManager) ImportHistory(monitorID string, points []LatencyPoint) {
+	if len(points) == 0 {
+		return
+	}
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	h := hm.ensureMonitor(monitorID)
+	merged := append(append([]LatencyPoint{}, h.LatencyHistory...), points...)
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Time < merged[j].Time })
+	last := merged[len(merged)-1]
+	h.LatencyHistory = merged
+	hm.trim(h, time.Now().Unix())
+
+	h.LastCheckTime = last.Time
+	if t := lastUpTime(merged); t > h.LastUpTime {
+		h.LastUpTime = t
+	}
+	h.IsUp = last.Up
 	hm.recalcUptime(h)
+	hm.historyDirty = true
 }
 
 // RecordDown creates an open incident.
-func (hm *HistoryManager) RecordDown(monitorID string, reason string) {
+func (hm *HistoryManager) RecordDown(monitorID, reason, category, kind string) {
 	hm.mu.Lock()
 	defer hm.mu.Unlock()
 
 	h := hm.ensureMonitor(monitorID)
 	h.IsUp = false
+	hm.historyDirty = true
+	hm.incidentsDirty = true
 
 	hm.incidents[monitorID] = append(hm.incidents[monitorID], Incident{
 		Type:      "down",
 		StartedAt: time.Now().Unix(),
 		Reason:    reason,
+		Category:  category,
+		Kind:      kind,
 	})
 }
 
-// RecordUp resolves the latest open incident.
-func (hm *HistoryManager) RecordUp(monitorID string) {
+// RecordUp resolves the latest open incident and returns its duration in seconds.
+func (hm *HistoryManager) RecordUp(monitorID string) int64 {
 	hm.mu.Lock()
 	defer hm.mu.Unlock()
 
 	h := hm.ensureMonitor(monitorID)
 	h.IsUp = true
+	hm.historyDirty = true
 
 	incs := hm.incidents[monitorID]
 	now := time.Now().Unix()
@@ -197,67 +345,266 @@ func (hm *HistoryManager) RecordUp(monitorID string) {
 		if incs[i].ResolvedAt == nil {
 			incs[i].ResolvedAt = &now
 			incs[i].Duration = now - incs[i].StartedAt
-			break
+			hm.incidentsDirty = true
+			return incs[i].Duration
 		}
 	}
+	return 0
+}
+
+// SetIsUp records the confirmed up/down state of a monitor. RecordProbe sets
+// IsUp from each raw probe, which inside the retry window differs from the
+// state the analyzer settled on. A monitor without history (e.g. one just
+// removed) is not recreated.
+func (hm *HistoryManager) SetIsUp(monitorID string, up bool) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	if h, ok := hm.data.Monitors[monitorID]; ok && h.IsUp != up {
+		h.IsUp = up
+		hm.historyDirty = true
+	}
 }
 
 // RemoveMonitor deletes history and incidents for a removed monitor.
 func (hm *HistoryManager) RemoveMonitor(id string) {
 	hm.mu.Lock()
 	defer hm.mu.Unlock()
-	delete(hm.data.Monitors, id)
-	delete(hm.incidents, id)
+	if _, ok := hm.data.Monitors[id]; ok {
+		delete(hm.data.Monitors, id)
+		hm.historyDirty = true
+	}
+	if _, ok := hm.incidents[id]; ok {
+		delete(hm.incidents, id)
+		hm.incidentsDirty = true
+	}
 }
 
-// Dump persists current state to disk atomically (both history.json and incidents.json).
-func (hm *HistoryManager) Dump() error {
-	hm.mu.RLock()
-	now := time.Now().Unix()
+// DumpStatus returns the result of recent Dump calls.
+func (hm *HistoryManager) DumpStatus() DumpStatus {
+	hm.statusMu.Lock()
+	defer hm.statusMu.Unlock()
+	return DumpStatus{
+		LastSuccess:         hm.lastDumpOK,
+		LastError:           hm.lastDumpErr,
+		ConsecutiveFailures: hm.dumpFailures,
+	}
+}
 
-	// Copy history data (without incidents)
-	dataCopy := HistoryData{
-		Version:      hm.data.Version,
-		LastDumpTime: now,
-		Monitors:     make(map[string]*MonitorHistory, len(hm.data.Monitors)),
+// LoadFailed reports whether history.json was discarded at startup. Why, and
+// where the file was moved, is only logged.
+func (hm *HistoryManager) LoadFailed() bool {
+	return hm.loadFailed
+}
+
+// SetRetention sets how many latency points each monitor keeps: the newest
+// maxPoints, or, when retention is positive, those younger than retention
+// however many there are. It applies from the next probe or Dump.
+func (hm *HistoryManager) SetRetention(maxPoints int, retention time.Duration) {
+	hm.mu.Lock()
+	hm.maxHistoryPts = maxPoints
+	hm.retention = retention
+	hm.mu.Unlock()
+}
+
+// trim applies the retention limit to h's latency history and reports whether
+// it dropped anything. Callers hold mu.
+func (hm *HistoryManager) trim(h *MonitorHistory, now int64) bool {
+	pts := h.LatencyHistory
+	drop := 0
+	if hm.retention > 0 {
+		cutoff := now - int64(hm.retention.Seconds())
+		drop = sort.Search(len(pts), func(i int) bool { return pts[i].Time >= cutoff })
+	} else if len(pts) > hm.maxHistoryPts {
+		drop = len(pts) - hm.maxHistoryPts
 	}
-	for k, v := range hm.data.Monitors {
-		cp := *v
-		cp.Incidents = nil // incidents go in separate file
-		dataCopy.Monitors[k] = &cp
+	if drop == 0 {
+		return false
 	}
+	h.LatencyHistory = pts[drop:]
+	return true
+}
 
-	// Copy incidents with 30-day eviction
-	cutoff := now - int64(incidentRetention.Seconds())
-	incidentsCopy := IncidentsData{
-		Version:      CurrentHistoryVersion,
-		LastDumpTime: now,
-		Monitors:     make(map[string][]Incident, len(hm.incidents)),
+// SetIncidentMaxOpen makes Dump close incidents that have been open longer
+// than d, as resolved at that age; 0 leaves them open.
+func (hm *HistoryManager) SetIncidentMaxOpen(d time.Duration) {
+	hm.mu.Lock()
+	hm.maxOpen = d
+	hm.mu.Unlock()
+}
+
+// closeStale resolves incidents open longer than maxOpen, e.g. of a target
+// that was decommissioned while down, so they stop counting as downtime.
+// Callers hold mu.
+func (hm *HistoryManager) closeStale(now int64) {
+	if hm.maxOpen <= 0 {
+		return
+	}
+	limit := int64(hm.maxOpen.Seconds())
+	for k, incs := range hm.incidents {
+		var closed []Incident
+		for i, inc := range incs {
+			if inc.ResolvedAt != nil || now-inc.StartedAt <= limit {
+				continue
+			}
+			if closed == nil {
+				// Copy: copies handed out by GetMonitor share the old slice.
+				closed = slices.Clone(incs)
+			}
+			resolved := inc.StartedAt + limit
+			closed[i].ResolvedAt = &resolved
+			closed[i].Duration = limit
+			closed[i].Reason = AutoClosedReason
+			if inc.Reason != "" {
+				closed[i].Reason += " (" + inc.Reason + ")"
+			}
+			slog.Warn("incident auto-closed", "monitor_id", k, "started_at", inc.StartedAt, "max_open", hm.maxOpen.String())
+		}
+		if closed != nil {
+			hm.incidents[k] = closed
+			hm.incidentsDirty = true
+		}
+	}
+}
+
+// SetBackupCount sets how many rotated backups of each data file Dump keeps.
+func (hm *HistoryManager) SetBackupCount(n int) {
+	hm.statusMu.Lock()
+	hm.backupCount = n
+	hm.statusMu.Unlock()
+}
+
+// Dump persists whatever changed since the last write: history.json when
+// probes were recorded, incidents.json when incidents changed. Files that are
+// already up to date are neither rewritten nor rotated.
+func (hm *HistoryManager) Dump() error {
+	return hm.persist(true)
+}
+
+// DumpIncidents persists incidents.json only, if it changed. It runs on every
+// down/up transition so incidents survive a crash, while latency points, which
+// change on every probe, are left to the periodic Dump.
+func (hm *HistoryManager) DumpIncidents() error {
+	return hm.persist(false)
+}
+
+func (hm *HistoryManager) persist(withHistory bool) error {
+	hm.dumpMu.Lock()
+	err := hm.dump(withHistory)
+	hm.dumpMu.Unlock()
+
+	hm.statusMu.Lock()
+	if err != nil {
+		hm.lastDumpErr = err.Error()
+		hm.dumpFailures++
+	} else {
+		hm.lastDumpOK = time.Now().Unix()
+		hm.lastDumpErr = ""
+		hm.dumpFailures = 0
 	}
+	hm.statusMu.Unlock()
+	return err
+}
+
+func (hm *HistoryManager) dump(withHistory bool) error {
+	hm.mu.Lock()
+	now := time.Now().Unix()
+
+	hm.closeStale(now)
+
+	// Evict incidents past the retention window, unless still unresolved.
+	cutoff := now - int64(incidentRetention.Seconds())
 	for k, incs := range hm.incidents {
+		// Build a new slice: copies handed out by GetMonitor share the old one.
 		var kept []Incident
 		for _, inc := range incs {
-			// Keep if started within retention window OR still unresolved
 			if inc.StartedAt >= cutoff || inc.ResolvedAt == nil {
 				kept = append(kept, inc)
 			}
 		}
-		if len(kept) > 0 {
-			incidentsCopy.Monitors[k] = kept
+		if len(kept) != len(incs) {
+			hm.incidents[k] = kept
+			hm.incidentsDirty = true
+		}
+	}
+
+	// Age out points of monitors that are no longer probed, or since the
+	// retention setting was lowered.
+	if withHistory {
+		for _, h := range hm.data.Monitors {
+			if hm.trim(h, now) {
+				hm.recalcUptime(h)
+				hm.historyDirty = true
+			}
+		}
+	}
+
+	// Copy history data (without incidents)
+	var dataCopy *HistoryData
+	if withHistory && hm.historyDirty {
+		dataCopy = &HistoryData{
+			Version:      hm.data.Version,
+			LastDumpTime: now,
+			Monitors:     make(map[string]*MonitorHistory, len(hm.data.Monitors)),
+		}
+		for k, v := range hm.data.Monitors {
+			cp := *v
+			cp.Incidents = nil // incidents go in separate file
+			dataCopy.Monitors[k] = &cp
+		}
+		hm.historyDirty = false
+	}
+
+	var incidentsCopy *IncidentsData
+	if hm.incidentsDirty {
+		incidentsCopy = &IncidentsData{
+			Version:      CurrentHistoryVersion,
+			LastDumpTime: now,
+			Monitors:     make(map[string][]Incident, len(hm.incidents)),
+		}
+		for k, incs := range hm.incidents {
+			if len(incs) > 0 {
+				incidentsCopy.Monitors[k] = append([]Incident(nil), incs...)
+			}
 		}
+		hm.incidentsDirty = false
 	}
-	hm.mu.RUnlock()
+	hm.mu.Unlock()
 
-	// Write both files
-	if err := atomicWriteJSON(hm.filePath, dataCopy); err != nil {
-		return fmt.Errorf("dump history: %w", err)
+	if dataCopy != nil {
+		if err := hm.writeFile(hm.filePath, dataCopy); err != nil {
+			hm.markDirty(true, incidentsCopy != nil)
+			return fmt.Errorf("dump history: %w", err)
+		}
 	}
-	if err := atomicWriteJSON(hm.incidentsPath, incidentsCopy); err != nil {
-		return fmt.Errorf("dump incidents: %w", err)
+	if incidentsCopy != nil {
+		if err := hm.writeFile(hm.incidentsPath, incidentsCopy); err != nil {
+			hm.markDirty(false, true)
+			return fmt.Errorf("dump incidents: %w", err)
+		}
 	}
 	return nil
 }
 
+// writeFile rotates the backups of path and atomically replaces it with v.
+func (hm *HistoryManager) writeFile(path string, v interface{}) error {
+	hm.statusMu.Lock()
+	keep := hm.backupCount
+	hm.statusMu.Unlock()
+	if err := backup.Rotate(path, keep); err != nil {
+		slog.Warn("history backup failed", "path", path, "error", err)
+	}
+	return atomicWriteJSON(path, v)
+}
+
+// markDirty flags files whose snapshot failed to write, so the next dump
+// retries them.
+func (hm *HistoryManager) markDirty(history, incidents bool) {
+	hm.mu.Lock()
+	hm.historyDirty = hm.historyDirty || history
+	hm.incidentsDirty = hm.incidentsDirty || incidents
+	hm.mu.Unlock()
+}
+
 func (hm *HistoryManager) ensureMonitor(id string) *MonitorHistory {
 	h, ok := hm.data.Monitors[id]
 	if !ok {
@@ -298,24 +645,127 @@ func calcUptimeWindow(points []LatencyPoint, now int64, windowSec int64) float64
 	return float64(up) / float64(total) * 100.0
 }
 
+// loadHistory streams history.json one monitor at a time, so a large file is
+// never held in memory twice, and logs progress while it works. Decode errors
+// are wrapped in errCorrupt.
 func (hm *HistoryManager) loadHistory() error {
-	data, err := os.ReadFile(hm.filePath)
+	f, err := os.Open(hm.filePath)
 	if err != nil {
 		return err
 	}
-
-	var hd HistoryData
-	if err := json.Unmarshal(data, &hd); err != nil {
-		return fmt.Errorf("parse history JSON: %w", err)
+	defer f.Close()
+	var size int64
+	if info, err := f.Stat(); err == nil {
+		size = info.Size()
 	}
 
-	if hd.Monitors == nil {
-		hd.Monitors = make(map[string]*MonitorHistory)
+	start := time.Now()
+	lastLog := start
+	hd := HistoryData{Monitors: make(map[string]*MonitorHistory)}
+	dec := json.NewDecoder(bufio.NewReaderSize(f, 1<<20))
+
+	err = decodeObject(dec, func(key string) error {
+		switch key {
+		case "version":
+			return dec.Decode(&hd.Version)
+		case "last_dump_time":
+			return dec.Decode(&hd.LastDumpTime)
+		case "monitors":
+			return decodeObject(dec, func(id string) error {
+				var h MonitorHistory
+				if err := dec.Decode(&h); err != nil {
+					return err
+				}
+				if h.LastUpTime == 0 {
+					// Written before last_up_time existed.
+					h.LastUpTime = lastUpTime(h.LatencyHistory)
+				}
+				hd.Monitors[id] = &h
+				if time.Since(lastLog) >= historyProgressInterval {
+					lastLog = time.Now()
+					slog.Info("loading history", "monitors", len(hd.Monitors), "progress", progress(dec.InputOffset(), size))
+				}
+				return nil
+			})
+		default:
+			var skip json.RawMessage
+			return dec.Decode(&skip)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("%w: parse history JSON at byte %d: %v", errCorrupt, dec.InputOffset(), err)
 	}
+
+	slog.Info("history loaded", "monitors", len(hd.Monitors), "bytes", size, "duration_ms", time.Since(start).Milliseconds())
 	hm.data = hd
 	return nil
 }
 
+// lastUpTime returns the time of the newest successful point, or 0.
+func lastUpTime(points []LatencyPoint) int64 {
+	for i := len(points) - 1; i >= 0; i-- {
+		if points[i].Up {
+			return points[i].Time
+		}
+	}
+	return 0
+}
+
+// historyProgressInterval is how often loadHistory logs while reading a large file.
+const historyProgressInterval = 2 * time.Second
+
+// errCorrupt marks a data file that exists but cannot be decoded.
+var errCorrupt = errors.New("corrupt data file")
+
+// decodeObject reads a JSON object from dec, calling field for each key with
+// the decoder positioned at that key's value. field must consume the value.
+func decodeObject(dec *json.Decoder, field func(key string) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil // null
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("expected object, got %v", tok)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("expected object key, got %v", tok)
+		}
+		if err := field(key); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token() // closing '}'
+	return err
+}
+
+// progress formats how much of a file of size bytes has been read.
+func progress(offset, size int64) string {
+	if size <= 0 {
+		return "?"
+	}
+	return fmt.Sprintf("%d%%", offset*100/size)
+}
+
+// quarantine moves an undecodable data file aside so startup can continue with
+// empty state, and so the next Dump neither overwrites it nor rotates it into
+// the regular backups. It returns the new path.
+func quarantine(path string) (string, error) {
+	dst := fmt.Sprintf("%s.corrupt.%d", path, time.Now().Unix())
+	if err := os.Rename(path, dst); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
 func (hm *HistoryManager) loadIncidents() error {
 	data, err := os.ReadFile(hm.incidentsPath)
 	if err != nil {
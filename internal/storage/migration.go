@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -9,24 +11,32 @@ import (
 
 // MigrateHistoryFile checks the version of a history file and runs migrations if needed.
 func MigrateHistoryFile(filePath string) error {
-	data, err := os.ReadFile(filePath)
+	f, err := os.Open(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil // nothing to migrate
 		}
 		return err
 	}
+	defer f.Close()
 
-	var raw map[string]json.RawMessage
-	if err := json.Unmarshal(data, &raw); err != nil {
-		return fmt.Errorf("parse history for migration: %w", err)
-	}
-
+	// Only the version is needed, and Dump writes it first, so stop reading as
+	// soon as it is found instead of parsing a possibly very large file.
 	version := 0
-	if v, ok := raw["version"]; ok {
-		if err := json.Unmarshal(v, &version); err != nil {
-			version = 0
+	dec := json.NewDecoder(bufio.NewReader(f))
+	errFound := errors.New("found")
+	err = decodeObject(dec, func(key string) error {
+		if key == "version" {
+			if err := dec.Decode(&version); err != nil {
+				version = 0
+			}
+			return errFound
 		}
+		var skip json.RawMessage
+		return dec.Decode(&skip)
+	})
+	if err != nil && err != errFound {
+		return fmt.Errorf("parse history for migration: %w", err)
 	}
 
 	if version == CurrentHistoryVersion {
@@ -56,16 +66,9 @@ func MigrateConfigFile(filePath string) error {
 		return err
 	}
 
-	var raw map[string]json.RawMessage
-	if err := json.Unmarshal(data, &raw); err != nil {
-		return fmt.Errorf("parse config for migration: %w", err)
-	}
-
-	version := 0
-	if v, ok := raw["version"]; ok {
-		if err := json.Unmarshal(v, &version); err != nil {
-			version = 0
-		}
+	version, err := configVersion(data)
+	if err != nil {
+		return err
 	}
 
 	if version == CurrentHistoryVersion {
@@ -79,3 +82,38 @@ func MigrateConfigFile(filePath string) error {
 
 	return nil
 }
+
+// CheckConfigFile is the read-only counterpart of MigrateConfigFile: it returns
+// the version of a config file and fails if the migration chain can't bring it
+// to the current one, i.e. the file was written by a newer Wink.
+func CheckConfigFile(filePath string) (int, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, err
+	}
+	version, err := configVersion(data)
+	if err != nil {
+		return 0, err
+	}
+	if version > CurrentHistoryVersion {
+		return version, fmt.Errorf("config version %d is newer than this build supports (%d)", version, CurrentHistoryVersion)
+	}
+	return version, nil
+}
+
+// configVersion reads the version field of a config file; a missing or
+// malformed one counts as version 0.
+func configVersion(data []byte) (int, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return 0, fmt.Errorf("parse config for migration: %w", err)
+	}
+
+	version := 0
+	if v, ok := raw["version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			version = 0
+		}
+	}
+	return version, nil
+}
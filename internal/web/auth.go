@@ -3,8 +3,11 @@ package web
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -113,21 +116,24 @@ func NewLoginRateLimiter(maxAttempts int, lockoutSeconds int, stopCh <-chan stru
 
 // IsLocked returns true if the IP is currently locked out.
 func (rl *LoginRateLimiter) IsLocked(ip string) bool {
+	return rl.LockRemaining(ip) > 0
+}
+
+// LockRemaining returns how long the IP stays locked out, or zero if it is not.
+func (rl *LoginRateLimiter) LockRemaining(ip string) time.Duration {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 	a, ok := rl.attempts[ip]
-	if !ok {
-		return false
+	if !ok || a.failCount < rl.maxAttempts {
+		return 0
 	}
-	if a.failCount >= rl.maxAttempts && time.Since(a.lockedAt) < rl.lockoutDuration {
-		return true
-	}
-	if a.failCount >= rl.maxAttempts && time.Since(a.lockedAt) >= rl.lockoutDuration {
+	remaining := rl.lockoutDuration - time.Since(a.lockedAt)
+	if remaining <= 0 {
 		// Lockout expired, reset
 		delete(rl.attempts, ip)
-		return false
+		return 0
 	}
-	return false
+	return remaining
 }
 
 // RecordFailure increments the failure count for an IP.
@@ -189,15 +195,16 @@ func NewAuthHandler(cfgMgr *config.Manager, sessions *SessionStore, limiter *Log
 }
 
 func (ah *AuthHandler) LoginPage(w http.ResponseWriter, r *http.Request) {
-	lang := getLang(r)
-	ah.tmpl.Render(w, "login.html", map[string]interface{}{"Lang": lang})
+	if ah.renderLocked(w, r) {
+		return
+	}
+	ah.renderLogin(w, r, http.StatusOK, "", 0)
 }
 
 func (ah *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
-	ip := r.RemoteAddr
+	ip := loginIP(r)
 
-	if ah.limiter.IsLocked(ip) {
-		http.Error(w, "Too many login attempts. Try again later.", http.StatusTooManyRequests)
+	if ah.renderLocked(w, r) {
 		return
 	}
 
@@ -209,32 +216,133 @@ func (ah *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	if username != cfg.Auth.Username {
 		ah.limiter.RecordFailure(ip)
 		slog.Warn("login failed: wrong username", "ip", ip)
-		lang := getLang(r)
-		ah.tmpl.Render(w, "login.html", map[string]interface{}{"Error": translate(lang, "login.error"), "Lang": lang})
+		ah.loginFailed(w, r)
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(cfg.Auth.PasswordHash), []byte(password)); err != nil {
 		ah.limiter.RecordFailure(ip)
 		slog.Warn("login failed: wrong password", "ip", ip)
-		lang := getLang(r)
-		ah.tmpl.Render(w, "login.html", map[string]interface{}{"Error": translate(lang, "login.error"), "Lang": lang})
+		ah.loginFailed(w, r)
 		return
 	}
 
 	ah.limiter.ClearIP(ip)
 	token := ah.sessions.Create(username)
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "wink_session",
-		Value:    token,
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteStrictMode,
-	})
+	cookie := newCookie(r, ah.cfgMgr.Get().System, "wink_session", token)
+	cookie.HttpOnly = true
+	http.SetCookie(w, cookie)
 
 	slog.Info("login successful", "username", username, "ip", ip)
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	if cfg.Auth.UsesDefaultPassword() {
+		seeOther(w, r, "/password")
+		return
+	}
+	seeOther(w, r, "/")
+}
+
+// loginFailed shows the login form again after a failed attempt, or the
+// lockout if that attempt was the last one allowed.
+func (ah *AuthHandler) loginFailed(w http.ResponseWriter, r *http.Request) {
+	if ah.renderLocked(w, r) {
+		return
+	}
+	ah.renderLogin(w, r, http.StatusOK, translate(getLang(r), "login.error"), 0)
+}
+
+// renderLocked renders the login page with a countdown and reports true if
+// the client is locked out after too many failed attempts.
+func (ah *AuthHandler) renderLocked(w http.ResponseWriter, r *http.Request) bool {
+	remaining := ah.limiter.LockRemaining(loginIP(r))
+	if remaining <= 0 {
+		return false
+	}
+	// Round up so the countdown never reaches zero before the lockout ends.
+	secs := int((remaining + time.Second - 1) / time.Second)
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+	ah.renderLogin(w, r, http.StatusTooManyRequests, fmt.Sprintf(translate(getLang(r), "login.locked"), secs), secs)
+	return true
+}
+
+// loginIP returns the client address failed logins are counted against,
+// without the port, which changes with every connection.
+func loginIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// renderLogin renders the login page. A positive lockedSecs disables the form
+// and counts down until it may be submitted again.
+func (ah *AuthHandler) renderLogin(w http.ResponseWriter, r *http.Request, status int, errMsg string, lockedSecs int) {
+	ah.tmpl.RenderStatus(w, "login.html", status, map[string]interface{}{
+		"Lang":          getLang(r),
+		"Theme":         getTheme(r),
+		"Error":         errMsg,
+		"LockedSeconds": lockedSecs,
+	})
+}
+
+// ChangePasswordPage shows the mandatory password change while the admin
+// password is the shipped default. Afterwards it is changed in the settings.
+func (ah *AuthHandler) ChangePasswordPage(w http.ResponseWriter, r *http.Request) {
+	cfg := ah.cfgMgr.Get()
+	if !cfg.Auth.UsesDefaultPassword() {
+		seeOther(w, r, "/")
+		return
+	}
+	ah.renderChangePassword(w, r, cfg, "")
+}
+
+// ChangePassword replaces the shipped default password, which unlocks the rest
+// of the web UI. Existing sessions stay logged in.
+func (ah *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	lang := getLang(r)
+	cfg := ah.cfgMgr.Get()
+	if !cfg.Auth.UsesDefaultPassword() {
+		seeOther(w, r, "/")
+		return
+	}
+
+	hash, msg := newPasswordHash(lang, r.FormValue("new_password"), r.FormValue("confirm_password"), cfg.System.MinPasswordLength)
+	if msg != "" {
+		ah.renderChangePassword(w, r, cfg, msg)
+		return
+	}
+	changed := false
+	_, err := ah.cfgMgr.Update(func(cfg *config.Config) error {
+		// Another request may have replaced the default password meanwhile.
+		if !cfg.Auth.UsesDefaultPassword() {
+			return config.ErrUnchanged
+		}
+		cfg.Auth.PasswordHash = hash
+		changed = true
+		return nil
+	})
+	if err != nil {
+		slog.Error("failed to save new password", "error", err)
+		ah.renderChangePassword(w, r, cfg, translate(lang, "settings.error_save_failed")+": "+err.Error())
+		return
+	}
+	if !changed {
+		seeOther(w, r, "/")
+		return
+	}
+
+	slog.Info("default password changed", "username", cfg.Auth.Username, "ip", r.RemoteAddr)
+	seeOther(w, r, "/")
+}
+
+func (ah *AuthHandler) renderChangePassword(w http.ResponseWriter, r *http.Request, cfg config.Config, errMsg string) {
+	lang := getLang(r)
+	ah.tmpl.Render(w, "password.html", map[string]interface{}{
+		"Lang":     lang,
+		"Error":    errMsg,
+		"Username": cfg.Auth.Username,
+		"Hint":     fmt.Sprintf(translate(lang, "password.hint"), cfg.System.MinPasswordLength),
+	})
 }
 
 func (ah *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
@@ -243,16 +351,48 @@ func (ah *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		ah.sessions.Delete(cookie.Value)
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "wink_session",
-		Value:    "",
-		Path:     "/",
-		MaxAge:   -1,
-		HttpOnly: true,
-		SameSite: http.SameSiteStrictMode,
-	})
+	cookie = newCookie(r, ah.cfgMgr.Get().System, "wink_session", "")
+	cookie.MaxAge = -1
+	cookie.HttpOnly = true
+	http.SetCookie(w, cookie)
+
+	seeOther(w, r, "/login")
+}
 
-	http.Redirect(w, r, "/login", http.StatusSeeOther)
+// checkPassword enforces config.CheckPassword and returns an i18n error key, or
+// "" if acceptable.
+func checkPassword(password string, minLen int) string {
+	switch config.CheckPassword(password, minLen) {
+	case nil:
+		return ""
+	case config.ErrPasswordTooShort:
+		return "settings.password_too_short"
+	case config.ErrPasswordTooSimple:
+		return "settings.password_too_simple"
+	default:
+		return "settings.password_default"
+	}
+}
+
+// newPasswordHash checks a new password and its confirmation against the
+// password policy and returns its bcrypt hash, or a translated error message.
+func newPasswordHash(lang, password, confirm string, minLen int) (hash, errMsg string) {
+	if password != confirm {
+		return "", translate(lang, "settings.password_mismatch")
+	}
+	if key := checkPassword(password, minLen); key != "" {
+		msg := translate(lang, key)
+		if key == "settings.password_too_short" {
+			msg = fmt.Sprintf(msg, minLen)
+		}
+		return "", msg
+	}
+	b, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		slog.Error("failed to hash password", "error", err)
+		return "", translate(lang, "settings.error_internal") + ": " + err.Error()
+	}
+	return string(b), ""
 }
 
 func generateToken() string {
@@ -0,0 +1,78 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/makt28/wink/internal/config"
+)
+
+type basePathKey struct{}
+
+// mountBasePath serves h under base (system.base_path), for deployments behind
+// a proxy that forwards a subpath such as /wink/ without rewriting it. The
+// prefix is stripped before routing and kept in the request context, where
+// redirects and cookies pick it up. /healthz also answers at the root so
+// container health checks keep working.
+func mountBasePath(base string, h http.Handler) http.Handler {
+	if base == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == base {
+			http.Redirect(w, r, base+"/", http.StatusMovedPermanently)
+			return
+		}
+		rest, ok := strings.CutPrefix(r.URL.Path, base)
+		if !ok || !strings.HasPrefix(rest, "/") {
+			if r.URL.Path == "/healthz" {
+				h.ServeHTTP(w, r)
+				return
+			}
+			http.NotFound(w, r)
+			return
+		}
+		r2 := r.WithContext(context.WithValue(r.Context(), basePathKey{}, base))
+		u := *r.URL
+		u.Path = rest
+		u.RawPath = ""
+		r2.URL = &u
+		h.ServeHTTP(w, r2)
+	})
+}
+
+// basePath returns the prefix the request was served under, "" at the root.
+func basePath(r *http.Request) string {
+	base, _ := r.Context().Value(basePathKey{}).(string)
+	return base
+}
+
+// seeOther redirects to path, an absolute path within Wink such as "/settings".
+func seeOther(w http.ResponseWriter, r *http.Request, path string) {
+	http.Redirect(w, r, basePath(r)+path, http.StatusSeeOther)
+}
+
+// newCookie returns a cookie scoped to the base path, with the SameSite,
+// Secure and Domain attributes from the system settings.
+func newCookie(r *http.Request, sys config.SystemConfig, name, value string) *http.Cookie {
+	path := basePath(r)
+	if path == "" {
+		path = "/"
+	}
+	c := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   sys.CookieDomain,
+		Secure:   sys.CookieSecure,
+		SameSite: http.SameSiteStrictMode,
+	}
+	switch sys.CookieSameSite {
+	case "lax":
+		c.SameSite = http.SameSiteLaxMode
+	case "none":
+		c.SameSite = http.SameSiteNoneMode
+	}
+	return c
+}
@@ -0,0 +1,89 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/makt28/wink/internal/monitor"
+)
+
+// checkThrottle limits an action to one per key per cooldown, e.g. manual
+// checks to one per monitor.
+type checkThrottle struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+func newCheckThrottle() *checkThrottle {
+	return &checkThrottle{last: make(map[string]time.Time)}
+}
+
+// allow records a check of id and returns 0, or returns how long the caller must
+// wait if the previous check was less than cooldown ago.
+func (t *checkThrottle) allow(id string, cooldown time.Duration) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	if wait := cooldown - now.Sub(t.last[id]); wait > 0 {
+		return wait
+	}
+	t.last[id] = now
+	// Entries older than any sensible cooldown are dropped to keep the map small.
+	for k, at := range t.last {
+		if now.Sub(at) > time.Hour {
+			delete(t.last, k)
+		}
+	}
+	return 0
+}
+
+// CheckMonitor probes a monitor immediately and returns the fresh result. The
+// result is recorded like a scheduled probe; the monitor's schedule is unchanged.
+// Checks of one monitor are limited to one per system.min_interval.
+func (h *Handlers) CheckMonitor(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	cfg := h.cfgMgr.Get()
+	found := false
+	for _, m := range cfg.Monitors {
+		if m.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		writeJSONError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	cooldown := time.Duration(cfg.System.MinInterval) * time.Second
+	if wait := h.checks.allow(id, cooldown); wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		writeJSONError(w, http.StatusTooManyRequests, "checked too recently")
+		return
+	}
+
+	result, err := h.scheduler.CheckNow(r.Context(), id)
+	switch {
+	case errors.Is(err, monitor.ErrMonitorNotRunning), errors.Is(err, monitor.ErrProbeInFlight):
+		writeJSONError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		// The client disconnected; nobody is left to answer.
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"monitor_id": id,
+		"up":         result.Up,
+		"latency_ms": result.Latency.Milliseconds(),
+		"error":      result.Error,
+		"checked_at": time.Now().Unix(),
+	})
+}
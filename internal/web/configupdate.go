@@ -0,0 +1,37 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+)
+
+// errNotFound is returned from a config update whose monitor, group, notifier
+// or share link no longer exists.
+var errNotFound = errors.New("not found")
+
+// requestError is a check failing inside a config update. It is reported to
+// the client with its message and status rather than as a failed save.
+type requestError struct {
+	status int
+	msg    string
+}
+
+func (e *requestError) Error() string { return e.msg }
+
+// badRequest returns a requestError with status 400.
+func badRequest(msg string) error {
+	return &requestError{status: http.StatusBadRequest, msg: msg}
+}
+
+// asRequestError reports whether err is a requestError or errNotFound, and
+// the status and message to answer with.
+func asRequestError(err error, notFoundMsg string) (int, string, bool) {
+	var re *requestError
+	switch {
+	case errors.As(err, &re):
+		return re.status, re.msg, true
+	case errors.Is(err, errNotFound):
+		return http.StatusNotFound, notFoundMsg, true
+	}
+	return 0, "", false
+}
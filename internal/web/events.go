@@ -0,0 +1,74 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/makt28/wink/internal/monitor"
+)
+
+// eventsKeepAlive is how often an idle event stream gets a comment line, so
+// proxies don't close it.
+const eventsKeepAlive = 30 * time.Second
+
+// EventsHandler streams probe results as server-sent events.
+type EventsHandler struct {
+	scheduler *monitor.Scheduler
+	stopCh    <-chan struct{}
+}
+
+func NewEventsHandler(scheduler *monitor.Scheduler, stopCh <-chan struct{}) *EventsHandler {
+	return &EventsHandler{scheduler: scheduler, stopCh: stopCh}
+}
+
+// ServeHTTP sends a "probe" event for every probe of monitors with
+// probe_events on, optionally only for the monitor IDs given as ?monitor=.
+// A "dropped" event reports how many were lost while the client lagged.
+// Streams end on shutdown so they don't hold it up.
+func (eh *EventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	var only map[string]bool
+	if ids := r.URL.Query()["monitor"]; len(ids) > 0 {
+		only = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			only[id] = true
+		}
+	}
+
+	sub := eh.scheduler.SubscribeProbes()
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx would buffer the stream otherwise
+	fmt.Fprint(w, "retry: 5000\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-eh.stopCh:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case ev := <-sub.C:
+			if n := sub.Dropped(); n > 0 {
+				fmt.Fprintf(w, "event: dropped\ndata: {\"count\":%d}\n\n", n)
+			}
+			if only == nil || only[ev.MonitorID] {
+				data, _ := json.Marshal(ev)
+				fmt.Fprintf(w, "event: probe\ndata: %s\n\n", data)
+			}
+		}
+		flusher.Flush()
+	}
+}
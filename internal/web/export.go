@@ -0,0 +1,281 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/makt28/wink/internal/storage"
+)
+
+// APIMonitorHistory streams the full latency history and incidents of a monitor as JSON.
+//
+// Query parameters (all optional):
+//   - from, to: unix seconds bounding the range (inclusive)
+//   - interval: resample into buckets of this many seconds; each bucket reports the
+//     mean latency and is down if any probe in it failed
+//
+// Points clamped to the monitor's latency_cap_ms carry the measured latency as
+// "raw". Buckets average the measured latencies.
+func (h *Handlers) APIMonitorHistory(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	q := r.URL.Query()
+
+	from, errFrom := queryInt64(q.Get("from"), 0)
+	to, errTo := queryInt64(q.Get("to"), 0)
+	interval, errInterval := queryInt64(q.Get("interval"), 0)
+	if errFrom != nil || errTo != nil || errInterval != nil || interval < 0 || (to > 0 && to < from) {
+		writeJSONError(w, http.StatusBadRequest, "invalid from, to or interval")
+		return
+	}
+
+	found := false
+	for _, m := range h.cfgMgr.Get().Monitors {
+		if m.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		writeJSONError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	var points []storage.LatencyPoint
+	var incidents []storage.Incident
+	if hist := h.histMgr.GetMonitor(id); hist != nil {
+		points = hist.LatencyHistory
+		incidents = hist.Incidents
+	}
+
+	inRange := func(t int64) bool {
+		return t >= from && (to == 0 || t <= to)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	writeValue := func(v interface{}) {
+		b, _ := json.Marshal(v)
+		w.Write(b)
+	}
+
+	// Written piecewise so large histories are never held as one encoded buffer.
+	w.Write([]byte(`{"monitor_id":`))
+	writeValue(id)
+	w.Write([]byte(`,"from":` + strconv.FormatInt(from, 10) +
+		`,"to":` + strconv.FormatInt(to, 10) +
+		`,"interval":` + strconv.FormatInt(interval, 10) +
+		`,"points":[`))
+
+	first := true
+	emit := func(p storage.LatencyPoint) {
+		if !first {
+			w.Write([]byte(","))
+		}
+		first = false
+		writeValue(p)
+	}
+
+	if interval == 0 {
+		for _, p := range points {
+			if inRange(p.Time) {
+				emit(p)
+			}
+		}
+	} else {
+		var bucket storage.LatencyPoint
+		var sum, n int
+		flush := func() {
+			if n > 0 {
+				bucket.Latency = sum / n
+				emit(bucket)
+			}
+		}
+		for _, p := range points {
+			if !inRange(p.Time) {
+				continue
+			}
+			start := p.Time - p.Time%interval
+			if n == 0 || start != bucket.Time {
+				flush()
+				bucket = storage.LatencyPoint{Time: start, Up: true}
+				sum, n = 0, 0
+			}
+			if p.Raw > 0 {
+				sum += p.Raw
+			} else {
+				sum += p.Latency
+			}
+			n++
+			bucket.Up = bucket.Up && p.Up
+		}
+		flush()
+	}
+
+	w.Write([]byte(`],"incidents":[`))
+	first = true
+	for _, inc := range incidents {
+		// Keep incidents overlapping the range; open incidents extend to now.
+		if (to > 0 && inc.StartedAt > to) || (inc.ResolvedAt != nil && *inc.ResolvedAt < from) {
+			continue
+		}
+		if !first {
+			w.Write([]byte(","))
+		}
+		first = false
+		writeValue(inc)
+	}
+	w.Write([]byte("]}\n"))
+}
+
+// queryInt64 parses an optional integer query value.
+func queryInt64(s string, def int64) (int64, error) {
+	if s == "" {
+		return def, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// timelineSegment is one contiguous up or down period of a monitor.
+type timelineSegment struct {
+	State    string `json:"state"` // "up" or "down"
+	Start    int64  `json:"start"`
+	End      *int64 `json:"end"` // nil while the period is still ongoing
+	Duration int64  `json:"duration"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// APIMonitorTimeline returns the up/down periods of a monitor as consecutive
+// segments, derived from its incidents, with total uptime and downtime for the range.
+//
+// Query parameters (optional): from, to as unix seconds. The range is clipped to
+// start at the first recorded probe or incident; to defaults to now, and the
+// period covering now has a null end.
+func (h *Handlers) APIMonitorTimeline(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	q := r.URL.Query()
+
+	now := time.Now().Unix()
+	from, errFrom := queryInt64(q.Get("from"), 0)
+	to, errTo := queryInt64(q.Get("to"), 0)
+	if errFrom != nil || errTo != nil || (to > 0 && to < from) {
+		writeJSONError(w, http.StatusBadRequest, "invalid from or to")
+		return
+	}
+	if to == 0 || to > now {
+		to = now
+	}
+
+	found := false
+	for _, m := range h.cfgMgr.Get().Monitors {
+		if m.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		writeJSONError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	var incidents []storage.Incident
+	current := "pending"
+	first := int64(-1)
+	if hist := h.histMgr.GetMonitor(id); hist != nil {
+		incidents = append(incidents, hist.Incidents...)
+		if len(hist.LatencyHistory) > 0 {
+			first = hist.LatencyHistory[0].Time
+			current = "down"
+			if hist.IsUp {
+				current = "up"
+			}
+		}
+	}
+	sort.Slice(incidents, func(i, j int) bool { return incidents[i].StartedAt < incidents[j].StartedAt })
+	if len(incidents) > 0 && (first < 0 || incidents[0].StartedAt < first) {
+		first = incidents[0].StartedAt
+	}
+
+	segments := []timelineSegment{}
+	var upSecs, downSecs int64
+	if first >= 0 && first > from {
+		from = first
+	}
+
+	// add appends the part of [start, end) inside the range; ongoing periods
+	// (end < 0) run to now and keep a null end.
+	add := func(state string, start, end int64, reason string) {
+		ongoing := end < 0
+		if ongoing {
+			end = now
+		}
+		if start < from {
+			start = from
+		}
+		clipped := end > to
+		if clipped {
+			end = to
+		}
+		if first < 0 || end <= start {
+			return
+		}
+		seg := timelineSegment{State: state, Start: start, Duration: end - start, Reason: reason}
+		if !ongoing || clipped && to < now {
+			e := end
+			seg.End = &e
+		}
+		segments = append(segments, seg)
+		if state == "up" {
+			upSecs += seg.Duration
+		} else {
+			downSecs += seg.Duration
+		}
+	}
+
+	cursor := from
+	for _, inc := range incidents {
+		if inc.StartedAt > cursor {
+			add("up", cursor, inc.StartedAt, "")
+		}
+		end := int64(-1)
+		if inc.ResolvedAt != nil {
+			end = *inc.ResolvedAt
+		}
+		add("down", inc.StartedAt, end, inc.Reason)
+		if end < 0 {
+			cursor = to
+			break
+		}
+		if end > cursor {
+			cursor = end
+		}
+	}
+	if cursor < to {
+		add("up", cursor, -1, "")
+	}
+
+	uptime := 100.0
+	if total := upSecs + downSecs; total > 0 {
+		uptime = roundUptime(float64(upSecs) / float64(total) * 100)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"monitor_id":       id,
+		"from":             from,
+		"to":               to,
+		"current":          current,
+		"segments":         segments,
+		"uptime_seconds":   upSecs,
+		"downtime_seconds": downSecs,
+		"uptime_percent":   uptime,
+	})
+}
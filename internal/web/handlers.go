@@ -1,29 +1,38 @@
 package web
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"math"
+	"net"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/makt28/wink/internal/buildinfo"
 	"github.com/makt28/wink/internal/config"
+	"github.com/makt28/wink/internal/importer"
+	"github.com/makt28/wink/internal/monitor"
 	"github.com/makt28/wink/internal/notify"
+	"github.com/makt28/wink/internal/semver"
 	"github.com/makt28/wink/internal/storage"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // orderedGroup is a template-friendly struct for groups in display order.
 type orderedGroup struct {
-	ID   string
-	Name string
+	ID    string
+	Name  string
+	Muted bool
 }
 
 // buildOrderedGroups returns groups in the order specified by cfg.GroupOrder.
@@ -31,7 +40,7 @@ func buildOrderedGroups(cfg config.Config) []orderedGroup {
 	result := make([]orderedGroup, 0, len(cfg.GroupOrder))
 	for _, id := range cfg.GroupOrder {
 		if g, ok := cfg.ContactGroups[id]; ok {
-			result = append(result, orderedGroup{ID: g.ID, Name: g.Name})
+			result = append(result, orderedGroup{ID: g.ID, Name: g.Name, Muted: g.Muted})
 		}
 	}
 	return result
@@ -39,17 +48,25 @@ func buildOrderedGroups(cfg config.Config) []orderedGroup {
 
 // Handlers holds the HTMX page handlers.
 type Handlers struct {
-	cfgMgr  *config.Manager
-	histMgr *storage.HistoryManager
-	tmpl    *TemplateRenderer
+	cfgMgr    *config.Manager
+	histMgr   *storage.HistoryManager
+	scheduler *monitor.Scheduler
+	tmpl      *TemplateRenderer
+
+	checks      *checkThrottle
+	notifyTests *checkThrottle // test-all runs, under a single key
 }
 
 // NewHandlers creates page handlers.
-func NewHandlers(cfgMgr *config.Manager, histMgr *storage.HistoryManager, tmpl *TemplateRenderer) *Handlers {
+func NewHandlers(cfgMgr *config.Manager, histMgr *storage.HistoryManager, scheduler *monitor.Scheduler, tmpl *TemplateRenderer) *Handlers {
 	return &Handlers{
-		cfgMgr:  cfgMgr,
-		histMgr: histMgr,
-		tmpl:    tmpl,
+		cfgMgr:    cfgMgr,
+		histMgr:   histMgr,
+		scheduler: scheduler,
+		tmpl:      tmpl,
+		checks:    newCheckThrottle(),
+
+		notifyTests: newCheckThrottle(),
 	}
 }
 
@@ -61,11 +78,14 @@ func (h *Handlers) Dashboard(w http.ResponseWriter, r *http.Request) {
 	theme := getTheme(r)
 
 	data := map[string]interface{}{
-		"Total":       len(cfg.Monitors),
-		"Lang":        lang,
-		"Theme":       theme,
-		"Version":     version,
-		"I18nStrings": buildJSI18n(lang),
+		"Total":            len(cfg.Monitors),
+		"Lang":             lang,
+		"Theme":            theme,
+		"Version":          version,
+		"I18nStrings":      buildJSI18n(lang),
+		"MonitoringPaused": !cfg.System.IsMonitoringEnabled(),
+		"PollInterval":     cfg.System.DashboardRefreshSeconds * 1000,
+		"Viewer":           isViewer(r),
 	}
 
 	h.tmpl.Render(w, "dashboard.html", data)
@@ -81,40 +101,134 @@ type apiMonitorView struct {
 	Enabled      bool                   `json:"enabled"`
 	GroupID      string                 `json:"group_id"`
 	GroupName    string                 `json:"group_name"`
+	CreatedAt    int64                  `json:"created_at,omitempty"`
+	UpdatedAt    int64                  `json:"updated_at,omitempty"`
 	IsUp         bool                   `json:"is_up"`
 	HasHistory   bool                   `json:"has_history"`
 	Uptime24h    float64                `json:"uptime_24h"`
 	Uptime7d     float64                `json:"uptime_7d"`
 	Uptime30d    float64                `json:"uptime_30d"`
 	LastCheck    int64                  `json:"last_check"`
+	LastUp       int64                  `json:"last_up,omitempty"`     // last successful probe; absent if none is known
+	StateSince   int64                  `json:"state_since,omitempty"` // when the current up/down state began; absent if unknown
 	ResponseTime int                    `json:"response_time"`
 	Heartbeats   []storage.LatencyPoint `json:"heartbeats"`
+	Rollup       []rollupBucket         `json:"rollup,omitempty"` // with ?rollup=N, instead of heartbeats
+
+	MaintenanceUntil int64 `json:"maintenance_until,omitempty"` // end of a maintenance window announced by the target; absent outside one
+	StalledSince     int64 `json:"stalled_since,omitempty"`     // when the watchdog found the monitor's probes stopped landing; absent while healthy
 }
 
 // apiDetailView extends apiMonitorView with incidents and config fields.
 type apiDetailView struct {
 	apiMonitorView
-	MaxRetries       int                `json:"max_retries"`
-	RetryInterval    int                `json:"retry_interval"`
-	ReminderInterval int                `json:"reminder_interval"`
-	Timeout          int                `json:"timeout"`
-	IgnoreTLS        bool               `json:"ignore_tls"`
-	GroupID          string             `json:"group_id"`
-	Incidents        []storage.Incident `json:"incidents"`
+	MaxRetries        int                  `json:"max_retries"`
+	RetryInterval     int                  `json:"retry_interval"`
+	ReminderInterval  int                  `json:"reminder_interval"`
+	RecoveryThreshold int                  `json:"recovery_threshold"`
+	RetryHold         int                  `json:"retry_hold"`
+	Timeout           int                  `json:"timeout"`
+	IgnoreTLS         bool                 `json:"ignore_tls"`
+	Public            bool                 `json:"public"`
+	SourceIP          string               `json:"source_ip,omitempty"`
+	UserAgent         string               `json:"user_agent,omitempty"`
+	HostHeader        string               `json:"host_header,omitempty"`
+	Method            string               `json:"method,omitempty"`
+	JSONPath          string               `json:"json_path,omitempty"`
+	JSONExpected      string               `json:"json_expected,omitempty"`
+	MinBytes          int                  `json:"min_bytes,omitempty"`
+	MaxBytes          int                  `json:"max_bytes,omitempty"`
+	ExpectContentType string               `json:"expect_content_type,omitempty"`
+	Timezone          string               `json:"timezone,omitempty"`
+	SendData          string               `json:"send_data,omitempty"`
+	ExpectData        string               `json:"expect_data,omitempty"`
+	ExpectRegex       bool                 `json:"expect_regex,omitempty"`
+	SMTPStartTLS      bool                 `json:"smtp_starttls,omitempty"`
+	WSPing            bool                 `json:"ws_ping,omitempty"`
+	PingCount         int                  `json:"ping_count,omitempty"`
+	PingLossThreshold int                  `json:"ping_loss_threshold,omitempty"`
+	AnomalyK          float64              `json:"anomaly_k,omitempty"`
+	AnomalyCount      int                  `json:"anomaly_count,omitempty"`
+	SlowThresholdMs   int                  `json:"slow_threshold_ms,omitempty"`
+	SlowCount         int                  `json:"slow_count,omitempty"`
+	GroupID           string               `json:"group_id"`
+	Incidents         []storage.Incident   `json:"incidents"`
+	RecentErrors      []storage.ProbeError `json:"recent_errors"`
+
+	LatencyCountsAsDown bool `json:"latency_counts_as_down,omitempty"`
+	HonorRetryAfter     bool `json:"honor_retry_after,omitempty"`
+	LatencyCapMs        int  `json:"latency_cap_ms,omitempty"`
+
+	MinTLSVersion string `json:"min_tls_version,omitempty"`
+	RequireHTTP2  bool   `json:"require_http2,omitempty"`
+
+	RecoveryGraceSeconds int     `json:"recovery_grace_seconds,omitempty"`
+	SLATarget            float64 `json:"sla_target,omitempty"`
+
+	FailureMode        string `json:"failure_mode,omitempty"`
+	WindowSize         int    `json:"window_size,omitempty"`
+	WindowThresholdPct int    `json:"window_threshold_pct,omitempty"`
+
+	TraceTiming bool                 `json:"trace_timing,omitempty"`
+	Timing      *storage.ProbeTiming `json:"timing,omitempty"` // latest traced probe; only with trace_timing
+	ProbeEvents bool                 `json:"probe_events,omitempty"`
+
+	Targets  []string `json:"targets,omitempty"` // redacted; target joins them
+	UpPolicy string   `json:"up_policy,omitempty"`
 }
 
-// getPoints reads the "points" query param, clamped to [1, 200], default 90.
-func getPoints(r *http.Request) int {
+// getPoints reads the "points" query param, clamped to [1, config.MaxHeartbeatPoints].
+// def (system.default_heartbeat_points) is used when the param is absent or invalid.
+func getPoints(r *http.Request, def int) int {
 	n, err := strconv.Atoi(r.URL.Query().Get("points"))
 	if err != nil || n <= 0 {
-		return 90
+		return def
 	}
-	if n > 200 {
-		return 200
+	if n > config.MaxHeartbeatPoints {
+		return config.MaxHeartbeatPoints
 	}
 	return n
 }
 
+// monitorSortKeys are the ?sort= keys of GET /api/monitors, each comparing two
+// monitors in ascending order.
+var monitorSortKeys = map[string]func(a, b apiMonitorView) int{
+	"name": func(a, b apiMonitorView) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	},
+	"uptime_24h":    func(a, b apiMonitorView) int { return cmp.Compare(a.Uptime24h, b.Uptime24h) },
+	"uptime_7d":     func(a, b apiMonitorView) int { return cmp.Compare(a.Uptime7d, b.Uptime7d) },
+	"uptime_30d":    func(a, b apiMonitorView) int { return cmp.Compare(a.Uptime30d, b.Uptime30d) },
+	"response_time": func(a, b apiMonitorView) int { return cmp.Compare(a.ResponseTime, b.ResponseTime) },
+	"last_check":    func(a, b apiMonitorView) int { return cmp.Compare(a.LastCheck, b.LastCheck) },
+}
+
+// sortMonitorViews stably sorts views by key, so ties keep the dashboard order.
+// Monitors without history go last in either order, unless asUp is set, in
+// which case they sort as a healthy monitor would: 100% uptime and 0 ms.
+func sortMonitorViews(views []apiMonitorView, key string, desc, asUp bool) {
+	compare := monitorSortKeys[key]
+	value := func(v apiMonitorView) apiMonitorView {
+		if !v.HasHistory && asUp {
+			v.Uptime24h, v.Uptime7d, v.Uptime30d = 100, 100, 100
+		}
+		return v
+	}
+	slices.SortStableFunc(views, func(a, b apiMonitorView) int {
+		if !asUp && a.HasHistory != b.HasHistory && key != "name" {
+			if a.HasHistory {
+				return -1
+			}
+			return 1
+		}
+		c := compare(value(a), value(b))
+		if desc {
+			c = -c
+		}
+		return c
+	})
+}
+
 // roundUptime rounds to 2 decimal places.
 func roundUptime(v float64) float64 {
 	return math.Round(v*100) / 100
@@ -128,6 +242,104 @@ func lastLatency(pts []storage.LatencyPoint) int {
 	return pts[len(pts)-1].Latency
 }
 
+// stateSince returns when a monitor entered its current state: the start of its
+// open incident while down, the end of its last incident while up. Failures
+// that have not (yet) opened an incident date from the first of them, and a
+// monitor that was never down dates from its oldest retained probe. 0 means
+// there is no history to tell.
+func stateSince(hist storage.MonitorHistory) int64 {
+	if n := len(hist.Incidents); n > 0 {
+		last := hist.Incidents[n-1]
+		if !hist.IsUp && last.ResolvedAt == nil {
+			return last.StartedAt
+		}
+		if hist.IsUp && last.ResolvedAt != nil {
+			return *last.ResolvedAt
+		}
+	}
+	pts := hist.LatencyHistory
+	if hist.IsUp && len(hist.Incidents) == 0 {
+		if len(pts) == 0 {
+			return 0
+		}
+		return pts[0].Time
+	}
+	var since int64
+	for i := len(pts) - 1; i >= 0 && pts[i].Up == hist.IsUp; i-- {
+		since = pts[i].Time
+	}
+	return since
+}
+
+// maintenanceUntil returns when the monitor's announced maintenance window ends,
+// or 0 when it is not in one at now.
+func maintenanceUntil(hist storage.MonitorHistory, now int64) int64 {
+	if hist.MaintenanceUntil > now {
+		return hist.MaintenanceUntil
+	}
+	return 0
+}
+
+// rollupBucket summarizes the probes of one time span of a monitor's history.
+type rollupBucket struct {
+	Start   int64   `json:"start"`
+	End     int64   `json:"end"`      // exclusive
+	Count   int     `json:"count"`    // probes in the span
+	UpRatio float64 `json:"up_ratio"` // successful share of the probes, 0–1
+	Latency int     `json:"v"`        // mean latency of the successful probes; 0 if none
+}
+
+// getRollup reads the "rollup" query param: the number of buckets to summarize
+// the history into, or 0 for raw heartbeats. ok is false for an invalid value.
+func getRollup(r *http.Request) (n int, ok bool) {
+	v := r.URL.Query().Get("rollup")
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > config.MaxHeartbeatPoints {
+		return 0, false
+	}
+	return n, true
+}
+
+// rollupPoints splits the span from the first point to now into n equal
+// buckets. Buckets without probes are left out.
+func rollupPoints(pts []storage.LatencyPoint, n int, now int64) []rollupBucket {
+	buckets := []rollupBucket{}
+	if len(pts) == 0 {
+		return buckets
+	}
+	first := pts[0].Time
+	width := (now - first + int64(n)) / int64(n) // ceil((now-first+1)/n)
+	var cur rollupBucket
+	var up, sum int
+	flush := func() {
+		if cur.Count > 0 {
+			cur.UpRatio = math.Round(float64(up)/float64(cur.Count)*10000) / 10000
+			if up > 0 {
+				cur.Latency = sum / up
+			}
+			buckets = append(buckets, cur)
+		}
+	}
+	for _, p := range pts {
+		start := first + (p.Time-first)/width*width
+		if cur.Count == 0 || start != cur.Start {
+			flush()
+			cur = rollupBucket{Start: start, End: start + width}
+			up, sum = 0, 0
+		}
+		cur.Count++
+		if p.Up {
+			up++
+			sum += p.Latency
+		}
+	}
+	flush()
+	return buckets
+}
+
 // tailPoints returns the last n points from a slice.
 func tailPoints(pts []storage.LatencyPoint, n int) []storage.LatencyPoint {
 	if len(pts) <= n {
@@ -140,10 +352,32 @@ func tailPoints(pts []storage.LatencyPoint, n int) []storage.LatencyPoint {
 func (h *Handlers) APIMonitors(w http.ResponseWriter, r *http.Request) {
 	cfg := h.cfgMgr.Get()
 	histories := h.histMgr.GetAll()
-	points := getPoints(r)
+	points := getPoints(r, cfg.System.DefaultHeartbeatPoints)
+	rollup, ok := getRollup(r)
+	if !ok {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("rollup must be between 1 and %d", config.MaxHeartbeatPoints))
+		return
+	}
+
+	q := r.URL.Query()
+	sortKey, order, noHistory := q.Get("sort"), q.Get("order"), q.Get("no_history")
+	if sortKey != "" && monitorSortKeys[sortKey] == nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid sort: "+sortKey)
+		return
+	}
+	if order != "" && order != "asc" && order != "desc" {
+		writeJSONError(w, http.StatusBadRequest, "order must be asc or desc")
+		return
+	}
+	if noHistory != "" && noHistory != "last" && noHistory != "up" {
+		writeJSONError(w, http.StatusBadRequest, "no_history must be last or up")
+		return
+	}
 
+	now := time.Now().Unix()
 	views := make([]apiMonitorView, 0, len(cfg.Monitors))
-	for _, m := range cfg.Monitors {
+	for _, m := range cfg.OrderedMonitors() {
+		m = redactMonitor(m)
 		groupName := ""
 		if g, ok := cfg.ContactGroups[m.GroupID]; ok {
 			groupName = g.Name
@@ -152,11 +386,13 @@ func (h *Handlers) APIMonitors(w http.ResponseWriter, r *http.Request) {
 			ID:        m.ID,
 			Name:      m.Name,
 			Type:      m.Type,
-			Target:    m.Target,
+			Target:    m.TargetLabel(),
 			Interval:  m.Interval,
 			Enabled:   m.IsEnabled(),
 			GroupID:   m.GroupID,
 			GroupName: groupName,
+			CreatedAt: m.CreatedAt,
+			UpdatedAt: m.UpdatedAt,
 			IsUp:      true,
 		}
 		if hist, ok := histories[m.ID]; ok {
@@ -166,28 +402,50 @@ func (h *Handlers) APIMonitors(w http.ResponseWriter, r *http.Request) {
 			mv.Uptime7d = roundUptime(hist.Uptime7d)
 			mv.Uptime30d = roundUptime(hist.Uptime30d)
 			mv.LastCheck = hist.LastCheckTime
-			mv.Heartbeats = tailPoints(hist.LatencyHistory, points)
+			mv.LastUp = hist.LastUpTime
+			mv.StateSince = stateSince(hist)
+			if rollup > 0 {
+				mv.Rollup = rollupPoints(hist.LatencyHistory, rollup, now)
+			} else {
+				mv.Heartbeats = tailPoints(hist.LatencyHistory, points)
+			}
 			mv.ResponseTime = lastLatency(hist.LatencyHistory)
+			mv.MaintenanceUntil = maintenanceUntil(hist, now)
+		}
+		if t, ok := h.scheduler.StalledSince(m.ID); ok {
+			mv.StalledSince = t.Unix()
 		}
 		if mv.Heartbeats == nil {
 			mv.Heartbeats = []storage.LatencyPoint{}
 		}
 		views = append(views, mv)
 	}
+	if sortKey != "" {
+		sortMonitorViews(views, sortKey, order == "desc", noHistory == "up")
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	resp := map[string]interface{}{
 		"monitors":    views,
 		"total":       len(cfg.Monitors),
 		"group_order": cfg.GroupOrder,
-	})
+	}
+	if cfg.System.Region != "" {
+		resp["region"] = cfg.System.Region
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
 }
 
 // APIMonitorDetail returns JSON data for a single monitor with incidents.
 func (h *Handlers) APIMonitorDetail(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	cfg := h.cfgMgr.Get()
-	points := getPoints(r)
+	points := getPoints(r, cfg.System.DefaultHeartbeatPoints)
+	rollup, ok := getRollup(r)
+	if !ok {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("rollup must be between 1 and %d", config.MaxHeartbeatPoints))
+		return
+	}
 
 	var found *config.Monitor
 	for i := range cfg.Monitors {
@@ -203,23 +461,71 @@ func (h *Handlers) APIMonitorDetail(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
 		return
 	}
+	view := redactMonitor(*found)
+	found = &view
 
 	dv := apiDetailView{
 		apiMonitorView: apiMonitorView{
-			ID:       found.ID,
-			Name:     found.Name,
-			Type:     found.Type,
-			Target:   found.Target,
-			Interval: found.Interval,
-			Enabled:  found.IsEnabled(),
-			IsUp:     true,
+			ID:        found.ID,
+			Name:      found.Name,
+			Type:      found.Type,
+			Target:    found.TargetLabel(),
+			Interval:  found.Interval,
+			Enabled:   found.IsEnabled(),
+			CreatedAt: found.CreatedAt,
+			UpdatedAt: found.UpdatedAt,
+			IsUp:      true,
 		},
-		MaxRetries:       found.MaxRetries,
-		RetryInterval:    found.RetryInterval,
-		ReminderInterval: found.ReminderInterval,
-		Timeout:          found.Timeout,
-		IgnoreTLS:        found.IgnoreTLS,
-		GroupID:          found.GroupID,
+		MaxRetries:        found.MaxRetries,
+		RetryInterval:     found.RetryInterval,
+		ReminderInterval:  found.ReminderInterval,
+		RecoveryThreshold: max(found.RecoveryThreshold, 1),
+		RetryHold:         found.RetryHold,
+		Timeout:           found.Timeout,
+		IgnoreTLS:         found.IgnoreTLS,
+		Public:            found.Public,
+		SourceIP:          found.SourceIP,
+		UserAgent:         found.UserAgent,
+		HostHeader:        found.HostHeader,
+		Method:            found.Method,
+		JSONPath:          found.JSONPath,
+		JSONExpected:      found.JSONExpected,
+		MinBytes:          found.MinBytes,
+		MaxBytes:          found.MaxBytes,
+		ExpectContentType: found.ExpectContentType,
+		Timezone:          found.Timezone,
+		SendData:          found.SendData,
+		ExpectData:        found.ExpectData,
+		ExpectRegex:       found.ExpectRegex,
+		SMTPStartTLS:      found.SMTPStartTLS,
+		WSPing:            found.WSPing,
+		PingCount:         found.PingCount,
+		PingLossThreshold: found.PingLossThreshold,
+		AnomalyK:          found.AnomalyK,
+		AnomalyCount:      found.AnomalyCount,
+		SlowThresholdMs:   found.SlowThresholdMs,
+		SlowCount:         found.SlowCount,
+		GroupID:           found.GroupID,
+
+		LatencyCountsAsDown: found.LatencyCountsAsDown,
+		HonorRetryAfter:     found.HonorRetryAfter,
+		LatencyCapMs:        found.LatencyCapMs,
+
+		MinTLSVersion: found.MinTLSVersion,
+		RequireHTTP2:  found.RequireHTTP2,
+
+		RecoveryGraceSeconds: found.RecoveryGraceSeconds,
+		SLATarget:            found.SLATarget,
+
+		FailureMode:        found.FailureMode,
+		WindowSize:         found.WindowSize,
+		WindowThresholdPct: found.WindowThresholdPct,
+
+		TraceTiming: found.TraceTiming,
+		ProbeEvents: found.ProbeEvents,
+
+		Targets:  found.Targets,
+		UpPolicy: found.UpPolicy,
 	}
 
 	hist := h.histMgr.GetMonitor(id)
@@ -230,9 +536,24 @@ func (h *Handlers) APIMonitorDetail(w http.ResponseWriter, r *http.Request) {
 		dv.Uptime7d = roundUptime(hist.Uptime7d)
 		dv.Uptime30d = roundUptime(hist.Uptime30d)
 		dv.LastCheck = hist.LastCheckTime
-		dv.Heartbeats = tailPoints(hist.LatencyHistory, points)
+		dv.LastUp = hist.LastUpTime
+		dv.StateSince = stateSince(*hist)
+		if rollup > 0 {
+			dv.Rollup = rollupPoints(hist.LatencyHistory, rollup, time.Now().Unix())
+		} else {
+			dv.Heartbeats = tailPoints(hist.LatencyHistory, points)
+		}
 		dv.ResponseTime = lastLatency(hist.LatencyHistory)
-		dv.Incidents = hist.Incidents
+		dv.MaintenanceUntil = maintenanceUntil(*hist, time.Now().Unix())
+		q := r.URL.Query()
+		dv.Incidents = categorizedIncidents(hist.Incidents, cfg.System.ReasonRules, q.Get("category"), q.Get("kind"))
+		dv.RecentErrors = hist.RecentErrors
+		if found.TraceTiming {
+			dv.Timing = hist.LastTiming
+		}
+	}
+	if t, ok := h.scheduler.StalledSince(id); ok {
+		dv.StalledSince = t.Unix()
 	}
 	if dv.Heartbeats == nil {
 		dv.Heartbeats = []storage.LatencyPoint{}
@@ -240,23 +561,295 @@ func (h *Handlers) APIMonitorDetail(w http.ResponseWriter, r *http.Request) {
 	if dv.Incidents == nil {
 		dv.Incidents = []storage.Incident{}
 	}
+	if dv.RecentErrors == nil {
+		dv.RecentErrors = []storage.ProbeError{}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
+	// A share link is a read-only dashboard: status and heartbeats only. The
+	// settings, incidents and raw probe errors may name internal hosts.
+	if isViewer(r) {
+		json.NewEncoder(w).Encode(dv.apiMonitorView)
+		return
+	}
 	json.NewEncoder(w).Encode(dv)
 }
 
+// categorizedIncidents returns a copy of incidents with a category on each one,
+// keeping only those of the given category and error kind when they are not
+// empty. Incidents recorded before categories existed are categorized from their
+// reason; those recorded before error kinds have none and never match a kind.
+func categorizedIncidents(incidents []storage.Incident, rules []config.ReasonRule, category, kind string) []storage.Incident {
+	result := make([]storage.Incident, 0, len(incidents))
+	for _, inc := range incidents {
+		if inc.Category == "" {
+			inc.Category = monitor.CategorizeReason(inc.Reason, rules)
+		}
+		if category != "" && inc.Category != category {
+			continue
+		}
+		if kind != "" && inc.Kind != kind {
+			continue
+		}
+		result = append(result, inc)
+	}
+	return result
+}
+
+// apiWorstMonitor is the enabled monitor with the lowest 24h uptime in GET /api/summary.
+type apiWorstMonitor struct {
+	MonitorID   string  `json:"monitor_id"`
+	MonitorName string  `json:"monitor_name"`
+	Uptime24h   float64 `json:"uptime_24h"`
+}
+
+// apiWorstIncident is the longest-open incident reported by APISummary.
+type apiWorstIncident struct {
+	MonitorID   string `json:"monitor_id"`
+	MonitorName string `json:"monitor_name"`
+	StartedAt   int64  `json:"started_at"`
+	Duration    int64  `json:"duration"`
+	Reason      string `json:"reason"`
+}
+
+// apiConfigView is the response of GET /api/config: the effective system
+// settings and the auth settings that are not secret. The password hash is
+// deliberately absent.
+type apiConfigView struct {
+	Version           string        `json:"version"`
+	Commit            string        `json:"commit,omitempty"`
+	BuildDate         string        `json:"build_date,omitempty"`
+	MonitoringEnabled bool          `json:"monitoring_enabled"`
+	System            apiSystemView `json:"system"`
+	Auth              apiAuthView   `json:"auth"`
+}
+
+// apiSystemView lists the system settings GET /api/config exposes, with the
+// same JSON names as config.json. It is an allowlist so that a secret added to
+// config.SystemConfig is not published by default; the probe webhook URL,
+// which may carry a token, is reduced to whether it is set.
+type apiSystemView struct {
+	BindAddress      string `json:"bind_address"`
+	CheckInterval    int    `json:"check_interval"`
+	MaxHistoryPoints int    `json:"max_history_points"`
+	DumpInterval     int    `json:"dump_interval"`
+	SessionTTL       int    `json:"session_ttl"`
+	LogLevel         string `json:"log_level"`
+	LogFormat        string `json:"log_format"`
+	LogFile          string `json:"log_file,omitempty"`
+	MaxMonitors      int    `json:"max_monitors"`
+	Timezone         string `json:"timezone,omitempty"`
+	Region           string `json:"region,omitempty"`
+	ProbeSourceIP    string `json:"probe_source_ip,omitempty"`
+	DNSResolver      string `json:"dns_resolver,omitempty"`
+	CABundlePath     string `json:"ca_bundle_path,omitempty"`
+
+	BasePath       string `json:"base_path,omitempty"`
+	CookieSameSite string `json:"cookie_samesite,omitempty"`
+	CookieSecure   bool   `json:"cookie_secure,omitempty"`
+	CookieDomain   string `json:"cookie_domain,omitempty"`
+
+	MinPasswordLength int `json:"min_password_length"`
+	BackupCount       int `json:"backup_count"`
+	DefaultTimeout    int `json:"default_timeout"`
+	MaxTimeout        int `json:"max_timeout"`
+	MinInterval       int `json:"min_interval"`
+	InitialBackoffMax int `json:"initial_backoff_max"`
+
+	RestartStalledMonitors bool `json:"restart_stalled_monitors,omitempty"`
+	ProbeCoalesceWindow    int  `json:"probe_coalesce_window,omitempty"`
+	HistoryRetentionHours  int  `json:"history_retention_hours,omitempty"`
+	IncidentMaxOpenHours   int  `json:"incident_max_open_hours,omitempty"`
+
+	ReasonRules []config.ReasonRule `json:"reason_rules,omitempty"`
+
+	NotifyTimeoutSeconds   int    `json:"notify_timeout"`
+	ShutdownTimeoutSeconds int    `json:"shutdown_timeout"`
+	TelegramTemplate       string `json:"telegram_template,omitempty"`
+
+	DefaultHeartbeatPoints  int `json:"default_heartbeat_points"`
+	DashboardRefreshSeconds int `json:"dashboard_refresh"`
+
+	MetricsBuckets []float64 `json:"metrics_buckets,omitempty"`
+
+	ProbeEvents  bool `json:"probe_events,omitempty"`
+	ProbeWebhook bool `json:"probe_webhook"` // probe_webhook_url is set
+}
+
+func newAPISystemView(s config.SystemConfig) apiSystemView {
+	return apiSystemView{
+		BindAddress:             s.BindAddress,
+		CheckInterval:           s.CheckInterval,
+		MaxHistoryPoints:        s.MaxHistoryPoints,
+		DumpInterval:            s.DumpInterval,
+		SessionTTL:              s.SessionTTL,
+		LogLevel:                s.LogLevel,
+		LogFormat:               s.LogFormat,
+		LogFile:                 s.LogFile,
+		MaxMonitors:             s.MaxMonitors,
+		Timezone:                s.Timezone,
+		Region:                  s.Region,
+		ProbeSourceIP:           s.ProbeSourceIP,
+		DNSResolver:             s.DNSResolver,
+		CABundlePath:            s.CABundlePath,
+		BasePath:                s.BasePath,
+		CookieSameSite:          s.CookieSameSite,
+		CookieSecure:            s.CookieSecure,
+		CookieDomain:            s.CookieDomain,
+		MinPasswordLength:       s.MinPasswordLength,
+		BackupCount:             s.BackupCount,
+		DefaultTimeout:          s.DefaultTimeout,
+		MaxTimeout:              s.MaxTimeout,
+		MinInterval:             s.MinInterval,
+		InitialBackoffMax:       s.InitialBackoffMax,
+		RestartStalledMonitors:  s.RestartStalledMonitors,
+		ProbeCoalesceWindow:     s.ProbeCoalesceWindow,
+		HistoryRetentionHours:   s.HistoryRetentionHours,
+		IncidentMaxOpenHours:    s.IncidentMaxOpenHours,
+		ReasonRules:             s.ReasonRules,
+		NotifyTimeoutSeconds:    s.NotifyTimeoutSeconds,
+		ShutdownTimeoutSeconds:  s.ShutdownTimeoutSeconds,
+		TelegramTemplate:        s.TelegramTemplate,
+		DefaultHeartbeatPoints:  s.DefaultHeartbeatPoints,
+		DashboardRefreshSeconds: s.DashboardRefreshSeconds,
+		MetricsBuckets:          s.MetricsBuckets,
+		ProbeEvents:             s.ProbeEvents,
+		ProbeWebhook:            s.ProbeWebhookURL != "",
+	}
+}
+
+type apiAuthView struct {
+	Username         string `json:"username"`
+	MaxLoginAttempts int    `json:"max_login_attempts"`
+	LockoutDuration  int    `json:"lockout_duration"`
+	SSOEnabled       bool   `json:"sso_enabled"`
+}
+
+// APIConfig returns the effective settings for automation, a stable
+// alternative to reading the settings page.
+func (h *Handlers) APIConfig(w http.ResponseWriter, r *http.Request) {
+	cfg := h.cfgMgr.Get()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(apiConfigView{
+		Version:           version,
+		Commit:            buildinfo.Commit,
+		BuildDate:         buildinfo.Date,
+		MonitoringEnabled: cfg.System.IsMonitoringEnabled(),
+		System:            newAPISystemView(cfg.System),
+		Auth: apiAuthView{
+			Username:         cfg.Auth.Username,
+			MaxLoginAttempts: cfg.Auth.MaxLoginAttempts,
+			LockoutDuration:  cfg.Auth.LockoutDuration,
+			SSOEnabled:       cfg.Auth.SSO.Enabled,
+		},
+	})
+}
+
+// APISummary returns a rollup of all monitors: counts by state, the longest-open
+// incident, and overall 24h uptime across every probe of enabled monitors.
+//
+// States: paused (disabled), pending (no probe yet), maintenance (within a window
+// announced by the target), down, degraded (still up but the latest probe failed,
+// i.e. within the retry window), and up.
+func (h *Handlers) APISummary(w http.ResponseWriter, r *http.Request) {
+	cfg := h.cfgMgr.Get()
+	histories := h.histMgr.GetAll()
+	now := time.Now().Unix()
+	cutoff := now - 24*3600
+
+	counts := map[string]int{"up": 0, "down": 0, "degraded": 0, "paused": 0, "pending": 0, "maintenance": 0}
+	var worst *apiWorstIncident
+	var worstMon *apiWorstMonitor
+	var totalPts, upPts int
+
+	for _, m := range cfg.Monitors {
+		if !m.IsEnabled() {
+			counts["paused"]++
+			continue
+		}
+		hist, ok := histories[m.ID]
+		// A maintenance window can start before the first heartbeat.
+		if ok && maintenanceUntil(hist, now) > 0 {
+			counts["maintenance"]++
+		} else if !ok || len(hist.LatencyHistory) == 0 {
+			counts["pending"]++
+			continue
+		} else {
+			switch last := hist.LatencyHistory[len(hist.LatencyHistory)-1]; {
+			case !hist.IsUp:
+				counts["down"]++
+			case !last.Up:
+				counts["degraded"]++
+			default:
+				counts["up"]++
+			}
+		}
+
+		// Ties keep the first monitor in config order.
+		if up := roundUptime(hist.Uptime24h); up < 100 && (worstMon == nil || up < worstMon.Uptime24h) {
+			worstMon = &apiWorstMonitor{MonitorID: m.ID, MonitorName: m.Name, Uptime24h: up}
+		}
+
+		for _, p := range hist.LatencyHistory {
+			if p.Time >= cutoff {
+				totalPts++
+				if p.Up {
+					upPts++
+				}
+			}
+		}
+
+		for _, inc := range hist.Incidents {
+			if inc.ResolvedAt == nil && (worst == nil || inc.StartedAt < worst.StartedAt) {
+				worst = &apiWorstIncident{
+					MonitorID:   m.ID,
+					MonitorName: m.Name,
+					StartedAt:   inc.StartedAt,
+					Duration:    now - inc.StartedAt,
+					Reason:      inc.Reason,
+				}
+			}
+		}
+	}
+
+	uptime := 100.0
+	if totalPts > 0 {
+		uptime = roundUptime(float64(upPts) / float64(totalPts) * 100)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"total":              len(cfg.Monitors),
+		"counts":             counts,
+		"all_operational":    counts["down"] == 0 && counts["degraded"] == 0,
+		"monitoring_enabled": cfg.System.IsMonitoringEnabled(),
+		"worst_incident":     worst,
+		"worst":              worstMon,
+		"uptime_24h":         uptime,
+		"generated_at":       now,
+	})
+}
+
 // MonitorForm renders the add monitor form.
 func (h *Handlers) MonitorForm(w http.ResponseWriter, r *http.Request) {
 	cfg := h.cfgMgr.Get()
 	lang := getLang(r)
 	data := map[string]interface{}{
-		"Groups":       buildOrderedGroups(cfg),
-		"IsEdit":       false,
-		"Lang":         lang,
-		"Theme":        getTheme(r),
-		"Version":      version,
-		"AllNotifiers": flattenNotifiers(cfg),
-		"SelectedNIDs": map[string]bool{},
+		"Groups":           buildOrderedGroups(cfg),
+		"IsEdit":           false,
+		"Lang":             lang,
+		"Theme":            getTheme(r),
+		"Version":          version,
+		"AllNotifiers":     flattenNotifiers(cfg),
+		"SelectedNIDs":     map[string]bool{},
+		"DefaultUserAgent": buildinfo.UserAgent,
+		"HTTPMethods":      httpMethodOptions,
+		"DefaultTimeout":   cfg.System.DefaultTimeout,
+		"MaxTimeout":       cfg.System.MaxTimeout,
+		"MinInterval":      cfg.System.MinInterval,
+		"MaxFailureWindow": config.MaxFailureWindow,
+		"SystemTimezone":   cfg.System.Timezone,
+		"SystemSourceIP":   cfg.System.ProbeSourceIP,
 	}
 	h.tmpl.Render(w, "monitor_form.html", data)
 }
@@ -266,11 +859,35 @@ type notifierInfo struct {
 	ID       string
 	Type     string
 	Label    string
+	Detail   string // type-specific summary shown next to the type badge
 	Remark   string
 	BotToken string
 	ChatID   string
 	URL      string
 	Method   string
+	Delivery string
+	Encoding string
+	Events   map[string]bool // event types delivered; all true when unfiltered
+
+	DeviceKey string
+	Token     string
+	UserKey   string
+	Priority  int
+	Sound     string
+	APIKey    string
+	Region    string
+
+	AccountSID string
+	AuthToken  string
+	From       string
+	To         string
+
+	ConfigKey   string
+	AppriseURLs string
+
+	MessageTemplate string
+
+	MinIntervalSeconds int
 }
 
 // EditMonitorForm renders the edit monitor form pre-filled with data.
@@ -298,14 +915,22 @@ func (h *Handlers) EditMonitorForm(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := map[string]interface{}{
-		"Groups":       buildOrderedGroups(cfg),
-		"IsEdit":       true,
-		"Monitor":      *found,
-		"Lang":         lang,
-		"Theme":        getTheme(r),
-		"Version":      version,
-		"AllNotifiers": flattenNotifiers(cfg),
-		"SelectedNIDs": selectedNIDs,
+		"Groups":           buildOrderedGroups(cfg),
+		"IsEdit":           true,
+		"Monitor":          *found,
+		"Lang":             lang,
+		"Theme":            getTheme(r),
+		"Version":          version,
+		"AllNotifiers":     flattenNotifiers(cfg),
+		"SelectedNIDs":     selectedNIDs,
+		"DefaultUserAgent": buildinfo.UserAgent,
+		"HTTPMethods":      httpMethodOptions,
+		"DefaultTimeout":   cfg.System.DefaultTimeout,
+		"MaxTimeout":       cfg.System.MaxTimeout,
+		"MinInterval":      cfg.System.MinInterval,
+		"MaxFailureWindow": config.MaxFailureWindow,
+		"SystemTimezone":   cfg.System.Timezone,
+		"SystemSourceIP":   cfg.System.ProbeSourceIP,
 	}
 	h.tmpl.Render(w, "monitor_form.html", data)
 }
@@ -338,15 +963,23 @@ func (h *Handlers) CloneMonitorForm(w http.ResponseWriter, r *http.Request) {
 	clone.Name = found.Name + " (Copy)"
 
 	data := map[string]interface{}{
-		"Groups":       buildOrderedGroups(cfg),
-		"IsEdit":       true,
-		"IsClone":      true,
-		"Monitor":      clone,
-		"Lang":         lang,
-		"Theme":        getTheme(r),
-		"Version":      version,
-		"AllNotifiers": flattenNotifiers(cfg),
-		"SelectedNIDs": selectedNIDs,
+		"Groups":           buildOrderedGroups(cfg),
+		"IsEdit":           true,
+		"IsClone":          true,
+		"Monitor":          clone,
+		"Lang":             lang,
+		"Theme":            getTheme(r),
+		"Version":          version,
+		"AllNotifiers":     flattenNotifiers(cfg),
+		"SelectedNIDs":     selectedNIDs,
+		"DefaultUserAgent": buildinfo.UserAgent,
+		"HTTPMethods":      httpMethodOptions,
+		"DefaultTimeout":   cfg.System.DefaultTimeout,
+		"MaxTimeout":       cfg.System.MaxTimeout,
+		"MinInterval":      cfg.System.MinInterval,
+		"MaxFailureWindow": config.MaxFailureWindow,
+		"SystemTimezone":   cfg.System.Timezone,
+		"SystemSourceIP":   cfg.System.ProbeSourceIP,
 	}
 	h.tmpl.Render(w, "monitor_form.html", data)
 }
@@ -378,30 +1011,101 @@ func (h *Handlers) CreateMonitor(w http.ResponseWriter, r *http.Request) {
 	}
 
 	m := config.Monitor{
-		ID:               generateToken()[:8],
-		Name:             r.FormValue("name"),
-		Type:             r.FormValue("type"),
-		Target:           r.FormValue("target"),
-		GroupID:          r.FormValue("group_id"),
-		Interval:         formInt(r, "interval", cfg.System.CheckInterval),
-		Timeout:          formInt(r, "timeout", 5),
-		MaxRetries:       formInt(r, "max_retries", 3),
-		RetryInterval:    formInt(r, "retry_interval", 0),
-		ReminderInterval: formInt(r, "reminder_interval", 0),
-		IgnoreTLS:        r.FormValue("ignore_tls") == "on",
-		NotifierIDs:      r.Form["notifier_ids"],
-	}
-
-	cfg.Monitors = append(cfg.Monitors, m)
-
-	if err := h.cfgMgr.Save(cfg); err != nil {
+		ID:                generateToken()[:8],
+		Name:              r.FormValue("name"),
+		Type:              r.FormValue("type"),
+		Target:            config.NormalizeTarget(r.FormValue("type"), r.FormValue("target")),
+		GroupID:           r.FormValue("group_id"),
+		Interval:          formInt(r, "interval", cfg.System.CheckInterval),
+		Timeout:           formInt(r, "timeout", cfg.System.DefaultTimeout),
+		MaxRetries:        formInt(r, "max_retries", 3),
+		RetryInterval:     formInt(r, "retry_interval", 0),
+		ReminderInterval:  formInt(r, "reminder_interval", 0),
+		RecoveryThreshold: formInt(r, "recovery_threshold", 1),
+		RetryHold:         formInt(r, "retry_hold", 0),
+		IgnoreTLS:         r.FormValue("ignore_tls") == "on",
+		Public:            r.FormValue("public") == "on",
+		SourceIP:          strings.TrimSpace(r.FormValue("source_ip")),
+		UserAgent:         strings.TrimSpace(r.FormValue("user_agent")),
+		HostHeader:        strings.TrimSpace(r.FormValue("host_header")),
+		Timezone:          strings.TrimSpace(r.FormValue("timezone")),
+		SendData:          r.FormValue("send_data"),
+		ExpectData:        r.FormValue("expect_data"),
+		ExpectRegex:       r.FormValue("expect_regex") == "on",
+		SMTPStartTLS:      r.FormValue("smtp_starttls") == "on",
+		WSPing:            r.FormValue("ws_ping") == "on",
+		AnomalyK:          formFloat(r, "anomaly_k", 0),
+		AnomalyCount:      formInt(r, "anomaly_count", 0),
+		SlowThresholdMs:   formInt(r, "slow_threshold_ms", 0),
+		SlowCount:         formInt(r, "slow_count", 0),
+		NotifierIDs:       r.Form["notifier_ids"],
+
+		LatencyCountsAsDown: formLatencyCountsAsDown(r),
+		HonorRetryAfter:     formHonorRetryAfter(r),
+		LatencyCapMs:        formInt(r, "latency_cap_ms", 0),
+
+		RecoveryGraceSeconds: formInt(r, "recovery_grace_seconds", 0),
+		SLATarget:            formFloat(r, "sla_target", 0),
+
+		TraceTiming: formTraceTiming(r),
+		ProbeEvents: r.FormValue("probe_events") == "on",
+	}
+	m.FailureMode, m.WindowSize, m.WindowThresholdPct = formFailureMode(r)
+	m.JSONPath, m.JSONExpected = formJSONAssertion(r)
+	m.MinBytes, m.MaxBytes = formBodySize(r)
+	m.ExpectContentType = formContentType(r)
+	m.MinTLSVersion, m.RequireHTTP2 = formProtocol(r)
+	m.Targets, m.UpPolicy = formTargets(r)
+	m.PingCount, m.PingLossThreshold = formPing(r)
+	m.Method = formMethod(r)
+
+	if !validTimezone(m.Timezone) {
+		respondError(w, r, translate(lang, "form.error_invalid_timezone"), http.StatusBadRequest)
+		return
+	}
+	if !validSourceIP(m.SourceIP) {
+		respondError(w, r, translate(lang, "form.error_source_ip"), http.StatusBadRequest)
+		return
+	}
+	if msg := targetError(lang, m); msg != "" {
+		respondError(w, r, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := methodError(lang, m); msg != "" {
+		respondError(w, r, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := intervalError(lang, m, cfg.System); msg != "" {
+		respondError(w, r, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := timeoutError(lang, m, cfg.System); msg != "" {
+		respondError(w, r, msg, http.StatusBadRequest)
+		return
+	}
+
+	m.CreatedAt = time.Now().Unix()
+	m.UpdatedAt = m.CreatedAt
+
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		// Checked again: monitors may have been added since the form was read.
+		if len(cfg.Monitors) >= cfg.System.MaxMonitors {
+			return badRequest(translate(lang, "form.error_max_monitors"))
+		}
+		cfg.Monitors = append(cfg.Monitors, m)
+		return nil
+	})
+	if status, msg, ok := asRequestError(err, ""); ok {
+		respondError(w, r, msg, status)
+		return
+	} else if err != nil {
 		slog.Error("failed to save config", "error", err)
 		respondError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	slog.Info("monitor created", "id", m.ID, "name", m.Name)
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	seeOther(w, r, "/")
 }
 
 // UpdateMonitor handles the form submission for editing an existing monitor.
@@ -413,41 +1117,96 @@ func (h *Handlers) UpdateMonitor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cfg := h.cfgMgr.Get()
+	var name string
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		idx := -1
+		for i := range cfg.Monitors {
+			if cfg.Monitors[i].ID == id {
+				idx = i
+				break
+			}
+		}
 
-	idx := -1
-	for i := range cfg.Monitors {
-		if cfg.Monitors[i].ID == id {
-			idx = i
-			break
+		if idx == -1 {
+			return errNotFound
 		}
-	}
 
-	if idx == -1 {
-		respondError(w, r, translate(lang, "settings.error_not_found"), http.StatusNotFound)
+		cfg.Monitors[idx].Name = r.FormValue("name")
+		cfg.Monitors[idx].Type = r.FormValue("type")
+		cfg.Monitors[idx].Target = config.NormalizeTarget(cfg.Monitors[idx].Type, r.FormValue("target"))
+		cfg.Monitors[idx].GroupID = r.FormValue("group_id")
+		cfg.Monitors[idx].Interval = formInt(r, "interval", cfg.System.CheckInterval)
+		cfg.Monitors[idx].Timeout = formInt(r, "timeout", cfg.System.DefaultTimeout)
+		cfg.Monitors[idx].MaxRetries = formInt(r, "max_retries", 3)
+		cfg.Monitors[idx].RetryInterval = formInt(r, "retry_interval", 0)
+		cfg.Monitors[idx].ReminderInterval = formInt(r, "reminder_interval", 0)
+		cfg.Monitors[idx].RecoveryThreshold = formInt(r, "recovery_threshold", 1)
+		cfg.Monitors[idx].RecoveryGraceSeconds = formInt(r, "recovery_grace_seconds", 0)
+		cfg.Monitors[idx].FailureMode, cfg.Monitors[idx].WindowSize, cfg.Monitors[idx].WindowThresholdPct = formFailureMode(r)
+		cfg.Monitors[idx].SLATarget = formFloat(r, "sla_target", 0)
+		cfg.Monitors[idx].TraceTiming = formTraceTiming(r)
+		cfg.Monitors[idx].ProbeEvents = r.FormValue("probe_events") == "on"
+		cfg.Monitors[idx].RetryHold = formInt(r, "retry_hold", 0)
+		cfg.Monitors[idx].IgnoreTLS = r.FormValue("ignore_tls") == "on"
+		cfg.Monitors[idx].Public = r.FormValue("public") == "on"
+		cfg.Monitors[idx].SourceIP = strings.TrimSpace(r.FormValue("source_ip"))
+		cfg.Monitors[idx].UserAgent = strings.TrimSpace(r.FormValue("user_agent"))
+		cfg.Monitors[idx].HostHeader = strings.TrimSpace(r.FormValue("host_header"))
+		cfg.Monitors[idx].Timezone = strings.TrimSpace(r.FormValue("timezone"))
+		cfg.Monitors[idx].SendData = r.FormValue("send_data")
+		cfg.Monitors[idx].ExpectData = r.FormValue("expect_data")
+		cfg.Monitors[idx].ExpectRegex = r.FormValue("expect_regex") == "on"
+		cfg.Monitors[idx].SMTPStartTLS = r.FormValue("smtp_starttls") == "on"
+		cfg.Monitors[idx].WSPing = r.FormValue("ws_ping") == "on"
+		cfg.Monitors[idx].AnomalyK = formFloat(r, "anomaly_k", 0)
+		cfg.Monitors[idx].AnomalyCount = formInt(r, "anomaly_count", 0)
+		cfg.Monitors[idx].SlowThresholdMs = formInt(r, "slow_threshold_ms", 0)
+		cfg.Monitors[idx].SlowCount = formInt(r, "slow_count", 0)
+		cfg.Monitors[idx].LatencyCountsAsDown = formLatencyCountsAsDown(r)
+		cfg.Monitors[idx].HonorRetryAfter = formHonorRetryAfter(r)
+		cfg.Monitors[idx].LatencyCapMs = formInt(r, "latency_cap_ms", 0)
+		cfg.Monitors[idx].NotifierIDs = r.Form["notifier_ids"]
+		cfg.Monitors[idx].JSONPath, cfg.Monitors[idx].JSONExpected = formJSONAssertion(r)
+		cfg.Monitors[idx].MinBytes, cfg.Monitors[idx].MaxBytes = formBodySize(r)
+		cfg.Monitors[idx].ExpectContentType = formContentType(r)
+		cfg.Monitors[idx].MinTLSVersion, cfg.Monitors[idx].RequireHTTP2 = formProtocol(r)
+		cfg.Monitors[idx].Targets, cfg.Monitors[idx].UpPolicy = formTargets(r)
+		cfg.Monitors[idx].PingCount, cfg.Monitors[idx].PingLossThreshold = formPing(r)
+		cfg.Monitors[idx].Method = formMethod(r)
+
+		if !validTimezone(cfg.Monitors[idx].Timezone) {
+			return badRequest(translate(lang, "form.error_invalid_timezone"))
+		}
+		if !validSourceIP(cfg.Monitors[idx].SourceIP) {
+			return badRequest(translate(lang, "form.error_source_ip"))
+		}
+		if msg := targetError(lang, cfg.Monitors[idx]); msg != "" {
+			return badRequest(msg)
+		}
+		if msg := methodError(lang, cfg.Monitors[idx]); msg != "" {
+			return badRequest(msg)
+		}
+		if msg := intervalError(lang, cfg.Monitors[idx], cfg.System); msg != "" {
+			return badRequest(msg)
+		}
+		if msg := timeoutError(lang, cfg.Monitors[idx], cfg.System); msg != "" {
+			return badRequest(msg)
+		}
+		cfg.Monitors[idx].UpdatedAt = time.Now().Unix()
+		name = cfg.Monitors[idx].Name
+		return nil
+	})
+	if status, msg, ok := asRequestError(err, translate(lang, "settings.error_not_found")); ok {
+		respondError(w, r, msg, status)
 		return
-	}
-
-	cfg.Monitors[idx].Name = r.FormValue("name")
-	cfg.Monitors[idx].Type = r.FormValue("type")
-	cfg.Monitors[idx].Target = r.FormValue("target")
-	cfg.Monitors[idx].GroupID = r.FormValue("group_id")
-	cfg.Monitors[idx].Interval = formInt(r, "interval", cfg.System.CheckInterval)
-	cfg.Monitors[idx].Timeout = formInt(r, "timeout", 5)
-	cfg.Monitors[idx].MaxRetries = formInt(r, "max_retries", 3)
-	cfg.Monitors[idx].RetryInterval = formInt(r, "retry_interval", 0)
-	cfg.Monitors[idx].ReminderInterval = formInt(r, "reminder_interval", 0)
-	cfg.Monitors[idx].IgnoreTLS = r.FormValue("ignore_tls") == "on"
-	cfg.Monitors[idx].NotifierIDs = r.Form["notifier_ids"]
-
-	if err := h.cfgMgr.Save(cfg); err != nil {
+	} else if err != nil {
 		slog.Error("failed to save config", "error", err)
 		respondError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	slog.Info("monitor updated", "id", id, "name", cfg.Monitors[idx].Name)
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	slog.Info("monitor updated", "id", id, "name", name)
+	seeOther(w, r, "/")
 }
 
 // DeleteMonitor handles monitor deletion.
@@ -458,37 +1217,38 @@ func (h *Handlers) DeleteMonitor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cfg := h.cfgMgr.Get()
-	filtered := make([]config.Monitor, 0, len(cfg.Monitors))
-	found := false
-	for _, m := range cfg.Monitors {
-		if m.ID == id {
-			found = true
-			continue
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		filtered := make([]config.Monitor, 0, len(cfg.Monitors))
+		found := false
+		for _, m := range cfg.Monitors {
+			if m.ID == id {
+				found = true
+				continue
+			}
+			filtered = append(filtered, m)
 		}
-		filtered = append(filtered, m)
-	}
-
-	if !found {
+		if !found {
+			return errNotFound
+		}
+		cfg.Monitors = filtered
+		return nil
+	})
+	if errors.Is(err, errNotFound) {
 		http.Error(w, "Monitor not found", http.StatusNotFound)
 		return
-	}
-
-	cfg.Monitors = filtered
-	if err := h.cfgMgr.Save(cfg); err != nil {
+	} else if err != nil {
 		slog.Error("failed to save config", "error", err)
 		http.Error(w, "Failed to save", http.StatusInternalServerError)
 		return
 	}
 
-	h.histMgr.RemoveMonitor(id)
+	h.scheduler.RemoveMonitor(id)
 	slog.Info("monitor deleted", "id", id)
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	seeOther(w, r, "/")
 }
 
 // SettingsPage renders the settings page.
 func (h *Handlers) SettingsPage(w http.ResponseWriter, r *http.Request) {
-	cfg := h.cfgMgr.Get()
 	lang := getLang(r)
 
 	flash := ""
@@ -498,19 +1258,27 @@ func (h *Handlers) SettingsPage(w http.ResponseWriter, r *http.Request) {
 		flashType = "success"
 	}
 
-	data := map[string]interface{}{
-		"System":       cfg.System,
-		"Auth":         cfg.Auth,
-		"Groups":       cfg.ContactGroups,
-		"Lang":         lang,
-		"Theme":        getTheme(r),
-		"Version":      version,
-		"Flash":        flash,
-		"FlashType":    flashType,
-		"AllNotifiers": flattenNotifiers(cfg),
-		"I18nStrings":  buildJSI18n(lang),
+	h.tmpl.Render(w, "settings.html", h.settingsData(r, flash, flashType))
+}
+
+// settingsData is the template data of the settings page.
+func (h *Handlers) settingsData(r *http.Request, flash, flashType string) map[string]interface{} {
+	cfg := h.cfgMgr.Get()
+	lang := getLang(r)
+	return map[string]interface{}{
+		"System":            cfg.System,
+		"Auth":              cfg.Auth,
+		"Groups":            cfg.ContactGroups,
+		"Lang":              lang,
+		"Theme":             getTheme(r),
+		"Version":           version,
+		"Flash":             flash,
+		"FlashType":         flashType,
+		"AllNotifiers":      flattenNotifiers(cfg),
+		"ShareLinks":        flattenShareLinks(cfg),
+		"DefaultBarkServer": notify.DefaultBarkServer,
+		"I18nStrings":       buildJSI18n(lang),
 	}
-	h.tmpl.Render(w, "settings.html", data)
 }
 
 // renderSettingsWithError returns an error to the settings page.
@@ -522,122 +1290,495 @@ func (h *Handlers) renderSettingsWithError(w http.ResponseWriter, r *http.Reques
 		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
 		return
 	}
-	cfg := h.cfgMgr.Get()
-	lang := getLang(r)
-	data := map[string]interface{}{
-		"System":       cfg.System,
-		"Auth":         cfg.Auth,
-		"Groups":       cfg.ContactGroups,
-		"Lang":         lang,
-		"Theme":        getTheme(r),
-		"Version":      version,
-		"Flash":        msg,
-		"FlashType":    "error",
-		"AllNotifiers": flattenNotifiers(cfg),
-		"I18nStrings":  buildJSI18n(lang),
-	}
-	h.tmpl.Render(w, "settings.html", data)
+	h.renderSettingsFlash(w, r, msg, "error")
 }
 
-// SaveSystem handles saving system settings.
-func (h *Handlers) SaveSystem(w http.ResponseWriter, r *http.Request) {
+// renderSettingsFlash re-renders the settings page with a flash message of the given type.
+func (h *Handlers) renderSettingsFlash(w http.ResponseWriter, r *http.Request, msg, flashType string) {
+	h.tmpl.Render(w, "settings.html", h.settingsData(r, msg, flashType))
+}
+
+// maxImportSize caps the size of an uploaded Uptime Kuma export.
+const maxImportSize = 64 << 20
+
+// ImportKuma imports monitors and heartbeat history from an Uptime Kuma JSON backup.
+// Unsupported monitors are skipped and listed in the result flash.
+func (h *Handlers) ImportKuma(w http.ResponseWriter, r *http.Request) {
 	lang := getLang(r)
-	if err := r.ParseForm(); err != nil {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	if err := r.ParseMultipartForm(8 << 20); err != nil {
 		h.renderSettingsWithError(w, r, translate(lang, "settings.error_invalid_form"))
 		return
 	}
-
-	cfg := h.cfgMgr.Get()
-
-	bindHost := r.FormValue("bind_host")
-	bindPort := r.FormValue("bind_port")
-	if bindHost == "" {
-		cfg.System.BindAddress = ":" + bindPort
-	} else {
-		cfg.System.BindAddress = bindHost + ":" + bindPort
-	}
-	cfg.System.CheckInterval = formInt(r, "check_interval", 60)
-	cfg.System.MaxHistoryPoints = formInt(r, "max_history_points", 1440)
-	cfg.System.DumpInterval = formInt(r, "dump_interval", 300)
-	cfg.System.SessionTTL = formInt(r, "session_ttl", 86400)
-	cfg.System.LogLevel = r.FormValue("log_level")
-	cfg.System.MaxMonitors = formInt(r, "max_monitors", 500)
-	cfg.System.Timezone = r.FormValue("timezone")
-
-	if err := h.cfgMgr.Save(cfg); err != nil {
-		slog.Error("failed to save system settings", "error", err)
-		h.renderSettingsWithError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error())
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		h.renderSettingsWithError(w, r, translate(lang, "settings.import_kuma_no_file"))
 		return
 	}
-
-	slog.Info("system settings saved")
-	http.Redirect(w, r, "/settings?saved=1", http.StatusSeeOther)
-}
-
-// SaveAuth handles saving authentication settings.
-func (h *Handlers) SaveAuth(w http.ResponseWriter, r *http.Request) {
-	lang := getLang(r)
-	if err := r.ParseForm(); err != nil {
+	defer file.Close()
+	raw, err := io.ReadAll(file)
+	if err != nil {
 		h.renderSettingsWithError(w, r, translate(lang, "settings.error_invalid_form"))
 		return
 	}
 
 	cfg := h.cfgMgr.Get()
-
-	newUsername := r.FormValue("username")
-	newPassword := r.FormValue("new_password")
-	confirmPassword := r.FormValue("confirm_password")
-
-	if newUsername != "" {
-		cfg.Auth.Username = newUsername
+	res, err := importer.ParseKuma(raw, cfg.System.CheckInterval)
+	if err != nil {
+		h.renderSettingsWithError(w, r, translate(lang, "settings.import_kuma_invalid")+": "+err.Error())
+		return
 	}
-
-	if newPassword != "" {
-		if newPassword != confirmPassword {
-			h.renderSettingsWithError(w, r, translate(lang, "settings.password_mismatch"))
-			return
+	ids := make([]string, len(res.Monitors))
+	_, err = h.cfgMgr.Update(func(cfg *config.Config) error {
+		if len(cfg.Monitors)+len(res.Monitors) > cfg.System.MaxMonitors {
+			return badRequest(translate(lang, "form.error_max_monitors"))
 		}
-
-		hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
-		if err != nil {
-			slog.Error("failed to hash password", "error", err)
-			h.renderSettingsWithError(w, r, translate(lang, "settings.error_internal")+": "+err.Error())
-			return
+		for i, km := range res.Monitors {
+			km.Monitor.ID = generateToken()[:8]
+			// Fit Kuma's looser limits into ours rather than rejecting the whole import.
+			if km.Monitor.Interval < cfg.System.MinInterval {
+				km.Monitor.Interval = cfg.System.MinInterval
+			}
+			if km.Monitor.RetryInterval > km.Monitor.Interval {
+				km.Monitor.RetryInterval = km.Monitor.Interval
+			} else if km.Monitor.RetryInterval > 0 && km.Monitor.RetryInterval < cfg.System.MinInterval {
+				km.Monitor.RetryInterval = cfg.System.MinInterval
+			}
+			if km.Monitor.Timeout > cfg.System.MaxTimeout {
+				km.Monitor.Timeout = cfg.System.MaxTimeout
+			}
+			if km.Monitor.Timeout >= km.Monitor.Interval {
+				km.Monitor.Timeout = km.Monitor.Interval - 1
+			}
+			ids[i] = km.Monitor.ID
+			cfg.Monitors = append(cfg.Monitors, km.Monitor)
 		}
-		cfg.Auth.PasswordHash = string(hash)
-	}
-
-	if err := h.cfgMgr.Save(cfg); err != nil {
-		slog.Error("failed to save auth settings", "error", err)
+		return nil
+	})
+	if _, msg, ok := asRequestError(err, ""); ok {
+		h.renderSettingsWithError(w, r, msg)
+		return
+	} else if err != nil {
+		slog.Error("failed to save imported monitors", "error", err)
 		h.renderSettingsWithError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error())
 		return
 	}
 
-	slog.Info("auth settings saved", "username", cfg.Auth.Username)
-	http.Redirect(w, r, "/settings?saved=1", http.StatusSeeOther)
+	points := 0
+	for i, km := range res.Monitors {
+		h.histMgr.ImportHistory(ids[i], km.History)
+		points += len(km.History)
+	}
+	if err := h.histMgr.Dump(); err != nil {
+		slog.Error("failed to dump imported history", "error", err)
+	}
+
+	slog.Info("imported Uptime Kuma export", "monitors", len(res.Monitors), "skipped", len(res.Skipped), "heartbeats", points)
+	msg := fmt.Sprintf(translate(lang, "settings.import_kuma_done"), len(res.Monitors), points)
+	if len(res.Skipped) > 0 {
+		skipped := make([]string, len(res.Skipped))
+		for i, s := range res.Skipped {
+			skipped[i] = fmt.Sprintf("%s (%s: %s)", s.Name, s.Type, s.Reason)
+		}
+		msg += " " + translate(lang, "settings.import_kuma_skipped") + " " + strings.Join(skipped, ", ")
+	}
+	h.renderSettingsFlash(w, r, msg, "success")
 }
 
-// SaveSSO handles saving SSO settings.
-func (h *Handlers) SaveSSO(w http.ResponseWriter, r *http.Request) {
+// monitorImportItem reports the outcome of one entry of a bulk monitor import.
+type monitorImportItem struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ImportMonitors adds the monitors of a bulk JSON file (see importer.ParseMonitors)
+// in one config save. Each entry is validated on its own; invalid ones are
+// reported and skipped without aborting the rest. The file comes either as the
+// "file" field of a form upload, answered with a settings page flash, or as an
+// application/json request body, answered with JSON.
+func (h *Handlers) ImportMonitors(w http.ResponseWriter, r *http.Request) {
 	lang := getLang(r)
-	if err := r.ParseForm(); err != nil {
+	asJSON := strings.HasPrefix(r.Header.Get("Content-Type"), "application/json")
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var raw []byte
+	var err error
+	if asJSON {
+		raw, err = io.ReadAll(r.Body)
+	} else if err = r.ParseMultipartForm(8 << 20); err == nil {
+		file, _, ferr := r.FormFile("file")
+		if ferr != nil {
+			h.renderSettingsWithError(w, r, translate(lang, "settings.import_kuma_no_file"))
+			return
+		}
+		defer file.Close()
+		raw, err = io.ReadAll(file)
+	}
+	if err != nil {
+		if asJSON {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
 		h.renderSettingsWithError(w, r, translate(lang, "settings.error_invalid_form"))
 		return
 	}
 
 	cfg := h.cfgMgr.Get()
-
-	cfg.Auth.SSO.Enabled = r.FormValue("sso_enabled") == "on"
-
-	if err := h.cfgMgr.Save(cfg); err != nil {
-		slog.Error("failed to save SSO settings", "error", err)
-		h.renderSettingsWithError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error())
+	entries, err := importer.ParseMonitors(raw, cfg.System)
+	if err != nil {
+		if asJSON {
+			writeJSONError(w, http.StatusBadRequest, "invalid monitor file: "+err.Error())
+			return
+		}
+		h.renderSettingsWithError(w, r, translate(lang, "settings.import_monitors_invalid")+": "+err.Error())
 		return
 	}
 
-	slog.Info("SSO settings saved", "enabled", cfg.Auth.SSO.Enabled)
-	http.Redirect(w, r, "/settings?saved=1", http.StatusSeeOther)
-}
+	var imported, rejected []monitorImportItem
+	_, err = h.cfgMgr.Update(func(cfg *config.Config) error {
+		monitors := cfg.Monitors
+		for i, e := range entries {
+			item := monitorImportItem{Index: i, Name: e.Monitor.Name}
+			switch {
+			case e.Err != nil:
+				item.Error = e.Err.Error()
+			case len(monitors) >= cfg.System.MaxMonitors:
+				item.Error = fmt.Sprintf("max_monitors (%d) reached", cfg.System.MaxMonitors)
+			default:
+				e.Monitor.ID = generateToken()[:8]
+				if err := validateNewMonitor(*cfg, monitors, e.Monitor); err != nil {
+					item.Error = err.Error()
+				} else {
+					item.ID = e.Monitor.ID
+					monitors = append(monitors, e.Monitor)
+				}
+			}
+			if item.Error != "" {
+				rejected = append(rejected, item)
+			} else {
+				imported = append(imported, item)
+			}
+		}
+		if len(imported) == 0 {
+			return config.ErrUnchanged
+		}
+		cfg.Monitors = monitors
+		return nil
+	})
+	if err != nil {
+		slog.Error("failed to save imported monitors", "error", err)
+		if asJSON {
+			writeJSONError(w, http.StatusInternalServerError, "save failed: "+err.Error())
+			return
+		}
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error())
+		return
+	}
+	slog.Info("imported monitors", "imported", len(imported), "rejected", len(rejected))
+
+	if asJSON {
+		if imported == nil {
+			imported = []monitorImportItem{}
+		}
+		if rejected == nil {
+			rejected = []monitorImportItem{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"imported": imported, "errors": rejected})
+		return
+	}
+
+	msg := fmt.Sprintf(translate(lang, "settings.import_monitors_done"), len(imported))
+	if len(rejected) > 0 {
+		parts := make([]string, len(rejected))
+		for i, item := range rejected {
+			name := item.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", item.Index+1)
+			}
+			parts[i] = fmt.Sprintf("%s (%s)", name, item.Error)
+		}
+		msg += " " + translate(lang, "settings.import_kuma_skipped") + " " + strings.Join(parts, "; ")
+	}
+	if len(imported) == 0 {
+		h.renderSettingsWithError(w, r, msg)
+		return
+	}
+	h.renderSettingsFlash(w, r, msg, "success")
+}
+
+// validateNewMonitor checks m as if it were appended to monitors, returning
+// only the problems config.Validate reports for m itself, without its
+// "monitors[N]." prefix.
+func validateNewMonitor(cfg config.Config, monitors []config.Monitor, m config.Monitor) error {
+	cfg.Monitors = append(monitors[:len(monitors):len(monitors)], m)
+	err := cfg.Validate()
+	if err == nil {
+		return nil
+	}
+	prefix := fmt.Sprintf("monitors[%d].", len(monitors))
+	var problems []string
+	for _, line := range strings.Split(err.Error(), "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, prefix) {
+			problems = append(problems, strings.TrimPrefix(line, prefix))
+		}
+	}
+	if len(problems) == 0 {
+		return err
+	}
+	return errors.New(strings.Join(problems, "; "))
+}
+
+// SaveSystem handles saving system settings.
+func (h *Handlers) SaveSystem(w http.ResponseWriter, r *http.Request) {
+	lang := getLang(r)
+	if err := r.ParseForm(); err != nil {
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_invalid_form"))
+		return
+	}
+
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+
+		bindHost := r.FormValue("bind_host")
+		bindPort := r.FormValue("bind_port")
+		if bindHost == "" {
+			cfg.System.BindAddress = ":" + bindPort
+		} else {
+			cfg.System.BindAddress = bindHost + ":" + bindPort
+		}
+		cfg.System.CheckInterval = formInt(r, "check_interval", 60)
+		cfg.System.MaxHistoryPoints = formInt(r, "max_history_points", 1440)
+		cfg.System.HistoryRetentionHours = 0
+		if r.FormValue("history_retention") == "time" {
+			cfg.System.HistoryRetentionHours = formInt(r, "history_retention_hours", 0)
+			if cfg.System.HistoryRetentionHours <= 0 || cfg.System.HistoryRetentionHours > config.MaxHistoryRetentionHours {
+				return badRequest(fmt.Sprintf(translate(lang, "settings.error_history_retention"), config.MaxHistoryRetentionHours))
+			}
+		}
+		cfg.System.DumpInterval = formInt(r, "dump_interval", 300)
+		cfg.System.SessionTTL = formInt(r, "session_ttl", 86400)
+		cfg.System.LogLevel = r.FormValue("log_level")
+		cfg.System.LogFormat = r.FormValue("log_format")
+		cfg.System.LogFile = strings.TrimSpace(r.FormValue("log_file"))
+		cfg.System.MaxMonitors = formInt(r, "max_monitors", 500)
+		cfg.System.Timezone = r.FormValue("timezone")
+		cfg.System.Region = strings.TrimSpace(r.FormValue("region"))
+		cfg.System.ProbeSourceIP = strings.TrimSpace(r.FormValue("probe_source_ip"))
+		cfg.System.DNSResolver = strings.TrimSpace(r.FormValue("dns_resolver"))
+		cfg.System.CABundlePath = strings.TrimSpace(r.FormValue("ca_bundle_path"))
+		if path := cfg.System.CABundlePath; path != "" {
+			if _, err := monitor.LoadCABundle(path); err != nil {
+				return badRequest(fmt.Sprintf(translate(lang, "settings.error_ca_bundle"), err))
+			}
+		}
+		cfg.System.MinPasswordLength = formInt(r, "min_password_length", 8)
+		cfg.System.DefaultTimeout = formInt(r, "default_timeout", 5)
+		cfg.System.MaxTimeout = formInt(r, "max_timeout", 120)
+		cfg.System.MinInterval = formInt(r, "min_interval", 5)
+		cfg.System.InitialBackoffMax = formInt(r, "initial_backoff_max", 0)
+		cfg.System.ProbeCoalesceWindow = formInt(r, "probe_coalesce_window", 0)
+		cfg.System.IncidentMaxOpenHours = formInt(r, "incident_max_open_hours", 0)
+		cfg.System.NotifyTimeoutSeconds = formInt(r, "notify_timeout", 10)
+		cfg.System.ShutdownTimeoutSeconds = formInt(r, "shutdown_timeout", 8)
+		cfg.System.DefaultHeartbeatPoints = formInt(r, "default_heartbeat_points", 90)
+		cfg.System.DashboardRefreshSeconds = formInt(r, "dashboard_refresh", 10)
+		cfg.System.TelegramTemplate = strings.TrimSpace(r.FormValue("telegram_template"))
+
+		if !validSourceIP(cfg.System.ProbeSourceIP) {
+			return badRequest(translate(lang, "form.error_source_ip"))
+		}
+		if msg := templateError(lang, cfg.System.TelegramTemplate); msg != "" {
+			return badRequest(msg)
+		}
+		return nil
+	})
+	if _, msg, ok := asRequestError(err, ""); ok {
+		h.renderSettingsWithError(w, r, msg)
+		return
+	} else if err != nil {
+		slog.Error("failed to save system settings", "error", err)
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error())
+		return
+	}
+
+	slog.Info("system settings saved")
+	seeOther(w, r, "/settings?saved=1")
+}
+
+// SaveAuth handles saving authentication settings.
+func (h *Handlers) SaveAuth(w http.ResponseWriter, r *http.Request) {
+	lang := getLang(r)
+	if err := r.ParseForm(); err != nil {
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_invalid_form"))
+		return
+	}
+
+	newUsername := r.FormValue("username")
+	newPassword := r.FormValue("new_password")
+	confirmPassword := r.FormValue("confirm_password")
+
+	cfg, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		if newUsername != "" {
+			cfg.Auth.Username = newUsername
+		}
+		if newPassword != "" {
+			hash, msg := newPasswordHash(lang, newPassword, confirmPassword, cfg.System.MinPasswordLength)
+			if msg != "" {
+				return badRequest(msg)
+			}
+			cfg.Auth.PasswordHash = hash
+		}
+		return nil
+	})
+	if _, msg, ok := asRequestError(err, ""); ok {
+		h.renderSettingsWithError(w, r, msg)
+		return
+	} else if err != nil {
+		slog.Error("failed to save auth settings", "error", err)
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error())
+		return
+	}
+
+	slog.Info("auth settings saved", "username", cfg.Auth.Username)
+	seeOther(w, r, "/settings?saved=1")
+}
+
+// SaveSSO handles saving SSO settings.
+func (h *Handlers) SaveSSO(w http.ResponseWriter, r *http.Request) {
+	lang := getLang(r)
+	if err := r.ParseForm(); err != nil {
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_invalid_form"))
+		return
+	}
+
+	enabled := r.FormValue("sso_enabled") == "on"
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		cfg.Auth.SSO.Enabled = enabled
+		return nil
+	})
+	if err != nil {
+		slog.Error("failed to save SSO settings", "error", err)
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error())
+		return
+	}
+
+	slog.Info("SSO settings saved", "enabled", enabled)
+	seeOther(w, r, "/settings?saved=1")
+}
+
+// shareLinkInfo is a share link as listed on the settings page.
+type shareLinkInfo struct {
+	ID      string
+	Name    string
+	Created string
+	Expires string // "" when the link never expires
+	Expired bool
+}
+
+func flattenShareLinks(cfg config.Config) []shareLinkInfo {
+	loc, err := time.LoadLocation(cfg.System.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	now := time.Now().Unix()
+	result := make([]shareLinkInfo, 0, len(cfg.Auth.ShareLinks))
+	for _, l := range cfg.Auth.ShareLinks {
+		info := shareLinkInfo{
+			ID:      l.ID,
+			Name:    l.Name,
+			Created: time.Unix(l.CreatedAt, 0).In(loc).Format("2006-01-02 15:04"),
+			Expired: l.Expired(now),
+		}
+		if l.ExpiresAt > 0 {
+			info.Expires = time.Unix(l.ExpiresAt, 0).In(loc).Format("2006-01-02 15:04")
+		}
+		result = append(result, info)
+	}
+	return result
+}
+
+// maxShareLinkDays caps the lifetime of a share link set in the settings form.
+const maxShareLinkDays = 3650
+
+// CreateShareLink adds a read-only dashboard link. The token is shown once on
+// the re-rendered settings page; only its hash is saved.
+func (h *Handlers) CreateShareLink(w http.ResponseWriter, r *http.Request) {
+	lang := getLang(r)
+	if err := r.ParseForm(); err != nil {
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_invalid_form"))
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	days := formInt(r, "expires_days", 0)
+	if name == "" {
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_missing_fields"))
+		return
+	}
+	if days < 0 || days > maxShareLinkDays {
+		h.renderSettingsWithError(w, r, fmt.Sprintf(translate(lang, "settings.error_share_expiry"), maxShareLinkDays))
+		return
+	}
+
+	token := generateToken()
+	now := time.Now()
+	link := config.ShareLink{
+		ID:        generateToken()[:8],
+		Name:      name,
+		TokenHash: config.HashShareToken(token),
+		CreatedAt: now.Unix(),
+	}
+	if days > 0 {
+		link.ExpiresAt = now.AddDate(0, 0, days).Unix()
+	}
+
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		cfg.Auth.ShareLinks = append(cfg.Auth.ShareLinks, link)
+		return nil
+	})
+	if err != nil {
+		slog.Error("failed to add share link", "error", err)
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error())
+		return
+	}
+
+	slog.Info("share link added", "id", link.ID, "name", link.Name)
+	data := h.settingsData(r, translate(lang, "settings.share_created"), "success")
+	data["NewShareURL"] = basePath(r) + "/?share=" + token
+	h.tmpl.Render(w, "settings.html", data)
+}
+
+// DeleteShareLink revokes a share link.
+func (h *Handlers) DeleteShareLink(w http.ResponseWriter, r *http.Request) {
+	lang := getLang(r)
+	if err := r.ParseForm(); err != nil {
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_invalid_form"))
+		return
+	}
+
+	id := r.FormValue("id")
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		idx := slices.IndexFunc(cfg.Auth.ShareLinks, func(l config.ShareLink) bool { return l.ID == id })
+		if idx == -1 {
+			return errNotFound
+		}
+		cfg.Auth.ShareLinks = slices.Delete(cfg.Auth.ShareLinks, idx, idx+1)
+		return nil
+	})
+	if errors.Is(err, errNotFound) {
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_not_found"))
+		return
+	} else if err != nil {
+		slog.Error("failed to delete share link", "error", err)
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error())
+		return
+	}
+
+	slog.Info("share link revoked", "id", id)
+	seeOther(w, r, "/settings?saved=1")
+}
 
 // GroupsPage renders the groups management page.
 func (h *Handlers) GroupsPage(w http.ResponseWriter, r *http.Request) {
@@ -671,29 +1812,29 @@ func (h *Handlers) CreateGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cfg := h.cfgMgr.Get()
-
 	name := r.FormValue("group_name")
 	if name == "" {
-		http.Redirect(w, r, "/groups", http.StatusSeeOther)
+		seeOther(w, r, "/groups")
 		return
 	}
 
 	id := generateToken()[:8]
-	cfg.ContactGroups[id] = config.ContactGroup{
-		ID:   id,
-		Name: name,
-	}
-	cfg.GroupOrder = append(cfg.GroupOrder, id)
-
-	if err := h.cfgMgr.Save(cfg); err != nil {
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		cfg.ContactGroups[id] = config.ContactGroup{
+			ID:   id,
+			Name: name,
+		}
+		cfg.GroupOrder = append(cfg.GroupOrder, id)
+		return nil
+	})
+	if err != nil {
 		slog.Error("failed to save contact group", "error", err)
 		respondError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	slog.Info("contact group created", "id", id, "name", name)
-	http.Redirect(w, r, "/groups?saved=1", http.StatusSeeOther)
+	seeOther(w, r, "/groups?saved=1")
 }
 
 // DeleteGroup handles deleting a contact group.
@@ -705,39 +1846,41 @@ func (h *Handlers) DeleteGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cfg := h.cfgMgr.Get()
-
-	if _, ok := cfg.ContactGroups[id]; !ok {
-		respondError(w, r, translate(lang, "settings.error_not_found"), http.StatusNotFound)
-		return
-	}
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		if _, ok := cfg.ContactGroups[id]; !ok {
+			return errNotFound
+		}
 
-	// Clear group_id references from monitors
-	for i := range cfg.Monitors {
-		if cfg.Monitors[i].GroupID == id {
-			cfg.Monitors[i].GroupID = ""
+		// Clear group_id references from monitors
+		for i := range cfg.Monitors {
+			if cfg.Monitors[i].GroupID == id {
+				cfg.Monitors[i].GroupID = ""
+			}
 		}
-	}
 
-	delete(cfg.ContactGroups, id)
+		delete(cfg.ContactGroups, id)
 
-	// Remove from GroupOrder
-	newOrder := make([]string, 0, len(cfg.GroupOrder))
-	for _, gid := range cfg.GroupOrder {
-		if gid != id {
-			newOrder = append(newOrder, gid)
+		// Remove from GroupOrder
+		newOrder := make([]string, 0, len(cfg.GroupOrder))
+		for _, gid := range cfg.GroupOrder {
+			if gid != id {
+				newOrder = append(newOrder, gid)
+			}
 		}
-	}
-	cfg.GroupOrder = newOrder
-
-	if err := h.cfgMgr.Save(cfg); err != nil {
+		cfg.GroupOrder = newOrder
+		return nil
+	})
+	if errors.Is(err, errNotFound) {
+		respondError(w, r, translate(lang, "settings.error_not_found"), http.StatusNotFound)
+		return
+	} else if err != nil {
 		slog.Error("failed to delete contact group", "error", err)
 		respondError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	slog.Info("contact group deleted", "id", id)
-	http.Redirect(w, r, "/groups?saved=1", http.StatusSeeOther)
+	seeOther(w, r, "/groups?saved=1")
 }
 
 // RenameGroup handles renaming a contact group.
@@ -755,25 +1898,58 @@ func (h *Handlers) RenameGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cfg := h.cfgMgr.Get()
-
-	group, ok := cfg.ContactGroups[id]
-	if !ok {
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		group, ok := cfg.ContactGroups[id]
+		if !ok {
+			return errNotFound
+		}
+		group.Name = name
+		cfg.ContactGroups[id] = group
+		return nil
+	})
+	if errors.Is(err, errNotFound) {
 		respondError(w, r, translate(lang, "settings.error_not_found"), http.StatusNotFound)
 		return
+	} else if err != nil {
+		slog.Error("failed to rename contact group", "error", err)
+		respondError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	group.Name = name
-	cfg.ContactGroups[id] = group
+	slog.Info("contact group renamed", "id", id, "name", name)
+	seeOther(w, r, "/groups?saved=1")
+}
 
-	if err := h.cfgMgr.Save(cfg); err != nil {
-		slog.Error("failed to rename contact group", "error", err)
+// MuteGroup mutes or unmutes notifications for every monitor in a contact group.
+func (h *Handlers) MuteGroup(w http.ResponseWriter, r *http.Request) {
+	lang := getLang(r)
+	if err := r.ParseForm(); err != nil {
+		respondError(w, r, translate(lang, "settings.error_invalid_form"), http.StatusBadRequest)
+		return
+	}
+
+	id := r.FormValue("group_id")
+	muted := r.FormValue("muted") == "1"
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		group, ok := cfg.ContactGroups[id]
+		if !ok {
+			return errNotFound
+		}
+		group.Muted = muted
+		cfg.ContactGroups[id] = group
+		return nil
+	})
+	if errors.Is(err, errNotFound) {
+		respondError(w, r, translate(lang, "settings.error_not_found"), http.StatusNotFound)
+		return
+	} else if err != nil {
+		slog.Error("failed to mute contact group", "error", err)
 		respondError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	slog.Info("contact group renamed", "id", id, "name", name)
-	http.Redirect(w, r, "/groups?saved=1", http.StatusSeeOther)
+	slog.Info("contact group mute changed", "id", id, "muted", muted)
+	seeOther(w, r, "/groups?saved=1")
 }
 
 // AddNotifierFlat adds a notifier to the top-level notifier list.
@@ -787,53 +1963,37 @@ func (h *Handlers) AddNotifierFlat(w http.ResponseWriter, r *http.Request) {
 	nType := r.FormValue("type")
 	cfg := h.cfgMgr.Get()
 
-	nID := generateToken()[:8]
-	remark := r.FormValue("remark")
-	var nc config.NotifierConfig
-	switch nType {
-	case "telegram":
-		nc = config.NotifierConfig{
-			ID:       nID,
-			Type:     "telegram",
-			Remark:   remark,
-			BotToken: r.FormValue("bot_token"),
-			ChatID:   r.FormValue("chat_id"),
-		}
-		if nc.BotToken == "" || nc.ChatID == "" {
-			h.renderSettingsWithError(w, r, translate(lang, "settings.error_missing_fields"))
-			return
-		}
-	case "webhook":
-		method := r.FormValue("webhook_method")
-		if method == "" {
-			method = "POST"
-		}
-		nc = config.NotifierConfig{
-			ID:     nID,
-			Type:   "webhook",
-			Remark: remark,
-			URL:    r.FormValue("webhook_url"),
-			Method: method,
-		}
-		if nc.URL == "" {
-			h.renderSettingsWithError(w, r, translate(lang, "settings.error_missing_fields"))
-			return
-		}
-	default:
-		h.renderSettingsWithError(w, r, translate(lang, "settings.error_invalid_type"))
+	nc, errKey := notifierFromForm(r, nType)
+	if errKey != "" {
+		h.renderSettingsWithError(w, r, translate(lang, errKey))
+		return
+	}
+	if msg := validateNotifier(lang, nc, cfg.System); msg != "" {
+		h.renderSettingsWithError(w, r, msg)
 		return
 	}
+	nID := generateToken()[:8]
+	nc.ID = nID
 
-	cfg.Notifiers = append(cfg.Notifiers, nc)
+	events, ok := formNotifierEvents(r)
+	if !ok {
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_no_events"))
+		return
+	}
+	nc.Events = events
 
-	if err := h.cfgMgr.Save(cfg); err != nil {
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		cfg.Notifiers = append(cfg.Notifiers, nc)
+		return nil
+	})
+	if err != nil {
 		slog.Error("failed to add notifier", "error", err)
 		h.renderSettingsWithError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error())
 		return
 	}
 
 	slog.Info("notifier added", "id", nID, "type", nType)
-	http.Redirect(w, r, "/settings?saved=1", http.StatusSeeOther)
+	seeOther(w, r, "/settings?saved=1")
 }
 
 // DeleteNotifierByID removes a notifier by its ID from any contact group.
@@ -850,102 +2010,515 @@ func (h *Handlers) DeleteNotifierByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cfg := h.cfgMgr.Get()
-	found := false
-	for i, nc := range cfg.Notifiers {
-		if nc.ID == nID {
-			cfg.Notifiers = append(cfg.Notifiers[:i], cfg.Notifiers[i+1:]...)
-			found = true
-			break
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		found := false
+		for i, nc := range cfg.Notifiers {
+			if nc.ID == nID {
+				cfg.Notifiers = append(cfg.Notifiers[:i], cfg.Notifiers[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errNotFound
 		}
-	}
-
-	if !found {
-		h.renderSettingsWithError(w, r, translate(lang, "settings.error_not_found"))
-		return
-	}
 
-	// Also remove from any monitor's notifier_ids
-	for i := range cfg.Monitors {
-		filtered := make([]string, 0, len(cfg.Monitors[i].NotifierIDs))
-		for _, id := range cfg.Monitors[i].NotifierIDs {
-			if id != nID {
-				filtered = append(filtered, id)
+		// Also remove from any monitor's notifier_ids
+		for i := range cfg.Monitors {
+			filtered := make([]string, 0, len(cfg.Monitors[i].NotifierIDs))
+			for _, id := range cfg.Monitors[i].NotifierIDs {
+				if id != nID {
+					filtered = append(filtered, id)
+				}
 			}
+			cfg.Monitors[i].NotifierIDs = filtered
 		}
-		cfg.Monitors[i].NotifierIDs = filtered
-	}
-
-	if err := h.cfgMgr.Save(cfg); err != nil {
+		return nil
+	})
+	if errors.Is(err, errNotFound) {
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_not_found"))
+		return
+	} else if err != nil {
 		slog.Error("failed to delete notifier", "error", err)
 		h.renderSettingsWithError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error())
 		return
 	}
 
 	slog.Info("notifier deleted", "id", nID)
-	http.Redirect(w, r, "/settings?saved=1", http.StatusSeeOther)
+	seeOther(w, r, "/settings?saved=1")
 }
 
 // ToggleMonitor toggles a monitor's enabled state.
 func (h *Handlers) ToggleMonitor(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	cfg := h.cfgMgr.Get()
 
-	idx := -1
-	for i := range cfg.Monitors {
-		if cfg.Monitors[i].ID == id {
-			idx = i
-			break
+	var newState bool
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		idx := -1
+		for i := range cfg.Monitors {
+			if cfg.Monitors[i].ID == id {
+				idx = i
+				break
+			}
 		}
-	}
-
-	if idx == -1 {
+		if idx == -1 {
+			return errNotFound
+		}
+		newState = !cfg.Monitors[idx].IsEnabled()
+		cfg.Monitors[idx].Enabled = &newState
+		return nil
+	})
+	if errors.Is(err, errNotFound) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
 		return
+	} else if err != nil {
+		slog.Error("failed to toggle monitor", "error", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "failed to save"})
+		return
 	}
 
-	newState := !cfg.Monitors[idx].IsEnabled()
-	cfg.Monitors[idx].Enabled = &newState
+	slog.Info("monitor toggled", "id", id, "enabled", newState)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"enabled": newState})
+}
 
-	if err := h.cfgMgr.Save(cfg); err != nil {
-		slog.Error("failed to toggle monitor", "error", err)
+// ToggleMonitoring flips the global monitoring switch. Pausing stops every probe
+// goroutine; resuming restarts them with their persisted state.
+func (h *Handlers) ToggleMonitoring(w http.ResponseWriter, r *http.Request) {
+	var newState bool
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		newState = !cfg.System.IsMonitoringEnabled()
+		cfg.System.MonitoringEnabled = &newState
+		return nil
+	})
+	if err != nil {
+		slog.Error("failed to toggle monitoring", "error", err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": "failed to save"})
 		return
 	}
 
-	slog.Info("monitor toggled", "id", id, "enabled", newState)
+	slog.Warn("global monitoring toggled", "enabled", newState)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]bool{"enabled": newState})
 }
 
+// templateError returns a translated message, including the parser's detail,
+// when a message template fails to parse or execute, or "" if it is acceptable.
+func templateError(lang, text string) string {
+	if err := notify.ValidateMessageTemplate(text); err != nil {
+		return translate(lang, "settings.error_template") + ": " + err.Error()
+	}
+	return ""
+}
+
+// notifierFromForm builds a notifier of type nType from the settings form. Only the
+// fields of that type are set; ID and Events are left to the caller. A non-empty
+// errKey is the i18n key of the validation error; field checks are left to
+// validateNotifier.
+func notifierFromForm(r *http.Request, nType string) (nc config.NotifierConfig, errKey string) {
+	nc = config.NotifierConfig{
+		Type:               nType,
+		Remark:             r.FormValue("remark"),
+		MinIntervalSeconds: formInt(r, "min_interval_seconds", 0),
+	}
+	switch nType {
+	case "telegram":
+		nc.BotToken = r.FormValue("bot_token")
+		nc.ChatID = r.FormValue("chat_id")
+		nc.MessageTemplate = strings.TrimSpace(r.FormValue("message_template"))
+	case "webhook":
+		nc.URL = r.FormValue("webhook_url")
+		nc.URL = strings.Join(nc.WebhookURLs(), "\n")
+		nc.Method = r.FormValue("webhook_method")
+		if nc.Method == "" {
+			nc.Method = "POST"
+		}
+		if d := r.FormValue("webhook_delivery"); d == "all" {
+			nc.Delivery = d
+		}
+		if e := r.FormValue("webhook_encoding"); e != "json" {
+			nc.Encoding = e
+		}
+	case "bark":
+		nc.URL = strings.TrimSpace(r.FormValue("bark_server"))
+		nc.DeviceKey = strings.TrimSpace(r.FormValue("device_key"))
+		nc.Sound = strings.TrimSpace(r.FormValue("bark_sound"))
+	case "pushover":
+		nc.Token = strings.TrimSpace(r.FormValue("pushover_token"))
+		nc.UserKey = strings.TrimSpace(r.FormValue("user_key"))
+		nc.Sound = strings.TrimSpace(r.FormValue("pushover_sound"))
+		priority := formInt(r, "pushover_priority", 1)
+		nc.Priority = &priority
+	case "opsgenie":
+		nc.APIKey = strings.TrimSpace(r.FormValue("opsgenie_api_key"))
+		if region := r.FormValue("opsgenie_region"); region == "eu" {
+			nc.Region = region
+		}
+	case "googlechat", "mattermost":
+		nc.URL = strings.TrimSpace(r.FormValue(nType + "_url"))
+	case "twilio":
+		nc.AccountSID = strings.TrimSpace(r.FormValue("twilio_account_sid"))
+		nc.AuthToken = strings.TrimSpace(r.FormValue("twilio_auth_token"))
+		nc.From = strings.TrimSpace(r.FormValue("twilio_from"))
+		nc.To = r.FormValue("twilio_to")
+		nc.To = strings.Join(nc.Recipients(), "\n")
+	case "apprise":
+		nc.URL = strings.TrimSpace(r.FormValue("apprise_server"))
+		nc.ConfigKey = strings.TrimSpace(r.FormValue("apprise_config_key"))
+		nc.AppriseURLs = strings.TrimSpace(r.FormValue("apprise_urls"))
+	default:
+		return nc, "settings.error_invalid_type"
+	}
+	return nc, ""
+}
+
+// validateNotifier checks nc with the Validate method of the notifier it builds,
+// so every type, including ones added later, is checked by its own rules. It
+// returns a translated error message, or "" when nc is valid.
+func validateNotifier(lang string, nc config.NotifierConfig, sys config.SystemConfig) string {
+	n := notify.BuildNotifier(nc, sys)
+	if n == nil {
+		return translate(lang, "settings.error_invalid_type")
+	}
+	if err := n.Validate(); err != nil {
+		return translate(lang, "settings.error_invalid_notifier") + ": " + err.Error()
+	}
+	return templateError(lang, nc.MessageTemplate)
+}
+
+// barkServer returns the configured Bark server, or the public one when empty.
+func barkServer(server string) string {
+	if server == "" {
+		return notify.DefaultBarkServer
+	}
+	return server
+}
+
+// webhookHost returns the host of an incoming webhook URL, whose path and
+// query carry the secret and so are not shown in the notifier list.
+func webhookHost(raw string) string {
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return ""
+}
+
 func flattenNotifiers(cfg config.Config) []notifierInfo {
 	result := make([]notifierInfo, 0, len(cfg.Notifiers))
 	for _, nc := range cfg.Notifiers {
-		label := nc.Type
+		label, detail := nc.Type, ""
 		switch nc.Type {
 		case "telegram":
-			label = "Telegram: " + nc.ChatID
+			label, detail = "Telegram", nc.ChatID
 		case "webhook":
-			label = "Webhook: " + nc.URL
+			urls := nc.WebhookURLs()
+			if len(urls) > 0 {
+				detail = urls[0]
+			}
+			if len(urls) > 1 {
+				detail += fmt.Sprintf(" (+%d)", len(urls)-1)
+			}
+			label = "Webhook"
+		case "bark":
+			label, detail = "Bark", barkServer(nc.URL)
+		case "pushover":
+			label, detail = "Pushover", nc.UserKey
+		case "opsgenie":
+			label, detail = "Opsgenie", "US"
+			if nc.Region == "eu" {
+				detail = "EU"
+			}
+		case "googlechat":
+			label, detail = "Google Chat", webhookHost(nc.URL)
+		case "mattermost":
+			label, detail = "Mattermost", webhookHost(nc.URL)
+		case "twilio":
+			to := nc.Recipients()
+			if len(to) > 0 {
+				detail = to[0]
+			}
+			if len(to) > 1 {
+				detail += fmt.Sprintf(" (+%d)", len(to)-1)
+			}
+			label = "Twilio SMS"
+		case "apprise":
+			// Service URLs carry credentials, so only the server is shown.
+			label, detail = "Apprise", webhookHost(nc.URL)
+			if nc.ConfigKey != "" {
+				detail += "/" + nc.ConfigKey
+			}
+		}
+		if detail != "" {
+			label += ": " + detail
 		}
 		result = append(result, notifierInfo{
 			ID:       nc.ID,
 			Type:     nc.Type,
 			Label:    label,
+			Detail:   detail,
 			Remark:   nc.Remark,
 			BotToken: nc.BotToken,
 			ChatID:   nc.ChatID,
 			URL:      nc.URL,
 			Method:   nc.Method,
+			Delivery: nc.Delivery,
+			Encoding: nc.Encoding,
+
+			DeviceKey: nc.DeviceKey,
+			Token:     nc.Token,
+			UserKey:   nc.UserKey,
+			Priority:  nc.DownPriority(),
+			Sound:     nc.Sound,
+			APIKey:    nc.APIKey,
+			Region:    nc.Region,
+
+			AccountSID: nc.AccountSID,
+			AuthToken:  nc.AuthToken,
+			From:       nc.From,
+			To:         nc.To,
+
+			ConfigKey:   nc.ConfigKey,
+			AppriseURLs: nc.AppriseURLs,
+
+			MessageTemplate: nc.MessageTemplate,
+
+			MinIntervalSeconds: nc.MinIntervalSeconds,
+
+			Events: map[string]bool{
+				"down":    nc.WantsEvent("down"),
+				"up":      nc.WantsEvent("up"),
+				"anomaly": nc.WantsEvent("anomaly"),
+				"slow":    nc.WantsEvent("slow"),
+			},
 		})
 	}
 	return result
 }
 
+// validTimezone reports whether tz is empty (use the system default) or a loadable IANA zone.
+func validTimezone(tz string) bool {
+	if tz == "" {
+		return true
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
+}
+
+// validSourceIP reports whether ip is empty or a local address probes can bind to.
+func validSourceIP(ip string) bool {
+	if ip == "" {
+		return true
+	}
+	return net.ParseIP(ip) != nil && monitor.CheckSourceIP(ip) == nil
+}
+
+// httpMethodOptions are the methods offered by the monitor form, in display order.
+var httpMethodOptions = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// notifierEventTypes are the event types offered by the notifier "events" checkboxes.
+var notifierEventTypes = []string{"down", "up", "anomaly", "slow"}
+
+// formJSONAssertion reads the JSON path assertion, which only applies to HTTP
+// monitors; values left in the hidden inputs of other types are dropped.
+func formJSONAssertion(r *http.Request) (path, expected string) {
+	if r.FormValue("type") != "http" {
+		return "", ""
+	}
+	path = strings.TrimSpace(r.FormValue("json_path"))
+	if path == "" {
+		return "", ""
+	}
+	return path, r.FormValue("json_expected")
+}
+
+// formBodySize reads the response size bounds, which only apply to HTTP monitors.
+func formBodySize(r *http.Request) (minBytes, maxBytes int) {
+	if r.FormValue("type") != "http" {
+		return 0, 0
+	}
+	return formInt(r, "min_bytes", 0), formInt(r, "max_bytes", 0)
+}
+
+// formContentType reads the expected response media type, which only applies
+// to HTTP monitors.
+func formContentType(r *http.Request) string {
+	if r.FormValue("type") != "http" {
+		return ""
+	}
+	return strings.TrimSpace(r.FormValue("expect_content_type"))
+}
+
+// formProtocol reads the minimum TLS version and whether HTTP/2 is required,
+// which only apply to HTTP monitors.
+func formProtocol(r *http.Request) (minTLSVersion string, requireHTTP2 bool) {
+	if r.FormValue("type") != "http" {
+		return "", false
+	}
+	return r.FormValue("min_tls_version"), r.FormValue("require_http2") == "on"
+}
+
+// formLatencyCountsAsDown reads whether a slow response fails the check, which
+// only applies to HTTP monitors.
+func formLatencyCountsAsDown(r *http.Request) bool {
+	return r.FormValue("type") == "http" && r.FormValue("latency_counts_as_down") == "on"
+}
+
+// formFailureMode reads the failure policy; the window parameters are only
+// kept in window mode.
+func formFailureMode(r *http.Request) (mode string, size, pct int) {
+	if r.FormValue("failure_mode") != "window" {
+		return "", 0, 0
+	}
+	return "window", formInt(r, "window_size", 0), formInt(r, "window_threshold_pct", 0)
+}
+
+// formTraceTiming reads whether probes record a timing breakdown, which only
+// applies to HTTP monitors.
+func formTraceTiming(r *http.Request) bool {
+	return r.FormValue("type") == "http" && r.FormValue("trace_timing") == "on"
+}
+
+// formHonorRetryAfter reads whether a 503 with Retry-After starts a maintenance
+// window, which only applies to HTTP monitors.
+func formHonorRetryAfter(r *http.Request) bool {
+	return r.FormValue("type") == "http" && r.FormValue("honor_retry_after") == "on"
+}
+
+// formPing reads the packet count and loss threshold, which only apply to
+// ping monitors.
+func formPing(r *http.Request) (count, lossThreshold int) {
+	if r.FormValue("type") != "ping" {
+		return 0, 0
+	}
+	return formInt(r, "ping_count", 0), formInt(r, "ping_loss_threshold", 0)
+}
+
+// formMethod reads the HTTP request method, which only applies to HTTP
+// monitors. GET is stored as empty, the default.
+func formMethod(r *http.Request) string {
+	method := strings.ToUpper(strings.TrimSpace(r.FormValue("method")))
+	if r.FormValue("type") != "http" || method == http.MethodGet {
+		return ""
+	}
+	return method
+}
+
+// formNotifierEvents reads the "events" checkboxes. Selecting exactly the default
+// types (every type but the opt-in "slow") is stored as an empty filter; selecting
+// none is rejected (ok == false).
+func formNotifierEvents(r *http.Request) (events []string, ok bool) {
+	var selected []string
+	for _, t := range notifierEventTypes {
+		for _, e := range r.Form["events"] {
+			if e == t {
+				selected = append(selected, t)
+				break
+			}
+		}
+	}
+	switch {
+	case len(selected) == 0:
+		return nil, false
+	case slices.Equal(selected, config.DefaultEventTypes):
+		return nil, true
+	default:
+		return selected, true
+	}
+}
+
+// targetError returns a translated message when a tcp or ping target is
+// malformed, or "" if it is acceptable.
+func targetError(lang string, m config.Monitor) string {
+	if m.Target != "" && len(m.Targets) > 0 {
+		return translate(lang, "form.error_target_and_targets")
+	}
+	if m.Target == "" && len(m.Targets) == 0 {
+		return translate(lang, "form.error_no_target")
+	}
+	for _, target := range m.ProbeTargets() {
+		if config.ValidateTarget(m.Type, target) != nil {
+			return translate(lang, "form.error_"+m.Type+"_target")
+		}
+	}
+	return ""
+}
+
+// formTargets reads the monitor's additional targets, one per line, and the
+// policy combining them ("" for the default, all).
+func formTargets(r *http.Request) (targets []string, upPolicy string) {
+	for _, line := range strings.Split(r.FormValue("targets"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			targets = append(targets, config.NormalizeTarget(r.FormValue("type"), line))
+		}
+	}
+	if len(targets) > 0 && r.FormValue("up_policy") == "any" {
+		upPolicy = "any"
+	}
+	return targets, upPolicy
+}
+
+// methodError returns a translated message when an http monitor's method is
+// unknown, or is HEAD while a body assertion is set, or "" if it is acceptable.
+func methodError(lang string, m config.Monitor) string {
+	switch {
+	case m.Method == "":
+		return ""
+	case !config.HTTPMethods[m.Method]:
+		return translate(lang, "form.error_method")
+	case m.Method == http.MethodHead && m.NeedsBody():
+		return translate(lang, "form.error_head_body")
+	}
+	return ""
+}
+
+// intervalError returns a translated message when a monitor's interval or retry
+// interval is below the system floor, or the retry interval exceeds the interval.
+func intervalError(lang string, m config.Monitor, sys config.SystemConfig) string {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = sys.CheckInterval
+	}
+	switch {
+	case interval < sys.MinInterval:
+		return fmt.Sprintf(translate(lang, "form.error_interval_min"), sys.MinInterval)
+	case m.RetryInterval > 0 && m.RetryInterval < sys.MinInterval:
+		return fmt.Sprintf(translate(lang, "form.error_retry_interval_min"), sys.MinInterval)
+	case m.RetryInterval > interval:
+		return translate(lang, "form.error_retry_interval_max")
+	}
+	return ""
+}
+
+// timeoutError returns a translated message when a monitor's timeout exceeds the
+// system maximum or doesn't fit inside its check interval, or "" if it is acceptable.
+func timeoutError(lang string, m config.Monitor, sys config.SystemConfig) string {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = sys.CheckInterval
+	}
+	switch {
+	case m.Timeout > sys.MaxTimeout:
+		return fmt.Sprintf(translate(lang, "form.error_timeout_max"), sys.MaxTimeout)
+	case m.Timeout >= interval:
+		return translate(lang, "form.error_timeout_interval")
+	}
+	return ""
+}
+
+func formFloat(r *http.Request, key string, defaultVal float64) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(r.FormValue(key)), 64)
+	if err != nil {
+		return defaultVal
+	}
+	return f
+}
+
 func formInt(r *http.Request, key string, defaultVal int) int {
 	val := r.FormValue(key)
 	if val == "" {
@@ -974,46 +2547,43 @@ func (h *Handlers) UpdateNotifier(w http.ResponseWriter, r *http.Request) {
 	}
 
 	cfg := h.cfgMgr.Get()
-	idx := -1
-	for i, nc := range cfg.Notifiers {
-		if nc.ID == nID {
-			idx = i
-			break
-		}
+	events, ok := formNotifierEvents(r)
+	if !ok {
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_no_events"))
+		return
 	}
 
-	if idx == -1 {
-		h.renderSettingsWithError(w, r, translate(lang, "settings.error_not_found"))
+	nc, errKey := notifierFromForm(r, nType)
+	if errKey != "" {
+		h.renderSettingsWithError(w, r, translate(lang, errKey))
 		return
 	}
-
-	cfg.Notifiers[idx].Type = nType
-	cfg.Notifiers[idx].Remark = r.FormValue("remark")
-	switch nType {
-	case "telegram":
-		cfg.Notifiers[idx].BotToken = r.FormValue("bot_token")
-		cfg.Notifiers[idx].ChatID = r.FormValue("chat_id")
-		cfg.Notifiers[idx].URL = ""
-		cfg.Notifiers[idx].Method = ""
-	case "webhook":
-		method := r.FormValue("webhook_method")
-		if method == "" {
-			method = "POST"
-		}
-		cfg.Notifiers[idx].URL = r.FormValue("webhook_url")
-		cfg.Notifiers[idx].Method = method
-		cfg.Notifiers[idx].BotToken = ""
-		cfg.Notifiers[idx].ChatID = ""
+	if msg := validateNotifier(lang, nc, cfg.System); msg != "" {
+		h.renderSettingsWithError(w, r, msg)
+		return
 	}
+	nc.ID = nID
+	nc.Events = events
 
-	if err := h.cfgMgr.Save(cfg); err != nil {
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		idx := slices.IndexFunc(cfg.Notifiers, func(n config.NotifierConfig) bool { return n.ID == nID })
+		if idx == -1 {
+			return errNotFound
+		}
+		cfg.Notifiers[idx] = nc
+		return nil
+	})
+	if errors.Is(err, errNotFound) {
+		h.renderSettingsWithError(w, r, translate(lang, "settings.error_not_found"))
+		return
+	} else if err != nil {
 		slog.Error("failed to update notifier", "error", err)
 		h.renderSettingsWithError(w, r, translate(lang, "settings.error_save_failed")+": "+err.Error())
 		return
 	}
 
 	slog.Info("notifier updated", "id", nID, "type", nType)
-	http.Redirect(w, r, "/settings?saved=1", http.StatusSeeOther)
+	seeOther(w, r, "/settings?saved=1")
 }
 
 // TestNotifier sends a test notification via the specified notifier.
@@ -1036,41 +2606,90 @@ func (h *Handlers) TestNotifier(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	notifier := notify.BuildNotifier(*nc)
+	notifier := notify.BuildNotifier(*nc, cfg.System)
 	if notifier == nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "unknown notifier type"})
 		return
 	}
-
-	event := notify.AlertEvent{
-		MonitorName: "Test",
-		Type:        "up",
-		Target:      "https://example.com",
-		Reason:      "This is a test notification from Wink",
-		Timestamp:   time.Now().Unix(),
-		Timezone:    cfg.System.Timezone,
+	if err := notifier.Validate(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": err.Error()})
+		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), cfg.System.NotifyTimeout())
 	defer cancel()
+	results, err := sendTestNotification(ctx, notifier, cfg.System)
 
-	if err := notifier.Send(ctx, event); err != nil {
+	resp := map[string]interface{}{"ok": err == nil}
+	if err != nil {
 		slog.Error("test notification failed", "notifier_id", nID, "error", err)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": err.Error()})
-		return
+		resp["error"] = err.Error()
+		if key := notifierErrorHint(err); key != "" {
+			resp["hint"] = translate(getLang(r), key)
+		}
+	}
+	if results != nil {
+		resp["results"] = results
 	}
-
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+	json.NewEncoder(w).Encode(resp)
+}
+
+// notifierErrorHint returns the i18n key of an explanation for a failed test
+// send that points at the setting to fix, or "" when there is none.
+func notifierErrorHint(err error) string {
+	var tgErr *notify.TelegramError
+	var twErr *notify.TwilioError
+	var apErr *notify.AppriseError
+	switch {
+	case errors.As(err, &tgErr):
+		switch {
+		case tgErr.RateLimited():
+			return "settings.telegram_rate_limited"
+		case tgErr.Unauthorized():
+			return "settings.telegram_bad_token"
+		case tgErr.ChatUnavailable():
+			return "settings.telegram_bad_chat"
+		}
+	case errors.As(err, &twErr):
+		switch {
+		case twErr.Unauthorized():
+			return "settings.twilio_bad_auth"
+		case twErr.BadSender():
+			return "settings.twilio_bad_from"
+		case twErr.BadRecipient():
+			return "settings.twilio_bad_to"
+		case twErr.Unverified():
+			return "settings.twilio_unverified"
+		}
+	case errors.As(err, &apErr):
+		switch {
+		case apErr.NoConfig():
+			return "settings.apprise_no_config"
+		case apErr.DeliveryFailed():
+			return "settings.apprise_delivery_failed"
+		case apErr.BadRequest():
+			return "settings.apprise_bad_request"
+		}
+	}
+	return ""
 }
 
+// telegramUpdatesPageSize is the maximum number of updates Telegram returns per getUpdates call.
+const telegramUpdatesPageSize = 100
+
 // TelegramGetUpdates fetches recent chats from the Telegram getUpdates API.
+// The client may pass "offset" (the next_offset from a previous response) to page
+// through older buffered updates, and "limit" to control how many chats are returned.
 func (h *Handlers) TelegramGetUpdates(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		BotToken string `json:"bot_token"`
+		Offset   int64  `json:"offset"`
+		Limit    int    `json:"limit"`
 	}
 	if err := json.NewDecoder(io.LimitReader(r.Body, 4096)).Decode(&req); err != nil || req.BotToken == "" {
 		w.Header().Set("Content-Type", "application/json")
@@ -1078,8 +2697,16 @@ func (h *Handlers) TelegramGetUpdates(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{"error": "bot_token required"})
 		return
 	}
+	if req.Limit <= 0 {
+		req.Limit = 5
+	} else if req.Limit > telegramUpdatesPageSize {
+		req.Limit = telegramUpdatesPageSize
+	}
 
-	apiURL := "https://api.telegram.org/bot" + req.BotToken + "/getUpdates"
+	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates?limit=%d", req.BotToken, telegramUpdatesPageSize)
+	if req.Offset != 0 {
+		apiURL += fmt.Sprintf("&offset=%d", req.Offset)
+	}
 	client := &http.Client{Timeout: 5 * time.Second}
 	resp, err := client.Get(apiURL)
 	if err != nil {
@@ -1089,20 +2716,29 @@ func (h *Handlers) TelegramGetUpdates(w http.ResponseWriter, r *http.Request) {
 	}
 	defer resp.Body.Close()
 
+	type tgChat struct {
+		ID        int64  `json:"id"`
+		Title     string `json:"title"`
+		Type      string `json:"type"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Username  string `json:"username"`
+	}
+	type tgMessage struct {
+		Chat            tgChat `json:"chat"`
+		Text            string `json:"text"`
+		MigrateToChatID int64  `json:"migrate_to_chat_id"`
+	}
 	var tgResp struct {
 		OK     bool `json:"ok"`
 		Result []struct {
-			Message *struct {
-				Chat struct {
-					ID        int64  `json:"id"`
-					Title     string `json:"title"`
-					Type      string `json:"type"`
-					FirstName string `json:"first_name"`
-					LastName  string `json:"last_name"`
-					Username  string `json:"username"`
-				} `json:"chat"`
-				Text string `json:"text"`
-			} `json:"message"`
+			UpdateID      int64      `json:"update_id"`
+			Message       *tgMessage `json:"message"`
+			EditedMessage *tgMessage `json:"edited_message"`
+			ChannelPost   *tgMessage `json:"channel_post"`
+			MyChatMember  *struct {
+				Chat tgChat `json:"chat"`
+			} `json:"my_chat_member"`
 		} `json:"result"`
 	}
 
@@ -1119,59 +2755,91 @@ func (h *Handlers) TelegramGetUpdates(w http.ResponseWriter, r *http.Request) {
 		Message string `json:"message"`
 	}
 
+	var lastUpdateID int64
 	seen := make(map[int64]bool)
 	var chats []chatInfo
 	// Iterate in reverse so newest messages come first
 	for i := len(tgResp.Result) - 1; i >= 0; i-- {
 		u := tgResp.Result[i]
-		if u.Message == nil {
+		if u.UpdateID > lastUpdateID {
+			lastUpdateID = u.UpdateID
+		}
+
+		var msg *tgMessage
+		switch {
+		case u.Message != nil:
+			msg = u.Message
+		case u.ChannelPost != nil:
+			msg = u.ChannelPost
+		case u.EditedMessage != nil:
+			msg = u.EditedMessage
+		case u.MyChatMember != nil:
+			msg = &tgMessage{Chat: u.MyChatMember.Chat}
+		default:
+			continue
+		}
+
+		cid := msg.Chat.ID
+		if msg.MigrateToChatID != 0 {
+			// Group was upgraded to a supergroup: the old ID is dead, only offer the new one.
+			seen[cid] = true
 			continue
 		}
-		cid := u.Message.Chat.ID
 		if seen[cid] {
 			continue
 		}
 		seen[cid] = true
-		title := u.Message.Chat.Title
+		title := msg.Chat.Title
 		if title == "" {
-			name := u.Message.Chat.FirstName
-			if u.Message.Chat.LastName != "" {
-				name += " " + u.Message.Chat.LastName
+			name := msg.Chat.FirstName
+			if msg.Chat.LastName != "" {
+				name += " " + msg.Chat.LastName
 			}
 			if name != "" {
 				title = name
-			} else if u.Message.Chat.Username != "" {
-				title = "@" + u.Message.Chat.Username
+			} else if msg.Chat.Username != "" {
+				title = "@" + msg.Chat.Username
 			} else {
 				title = fmt.Sprintf("Chat %d", cid)
 			}
 		}
-		msg := u.Message.Text
-		if len(msg) > 30 {
-			msg = msg[:30] + "..."
+		text := []rune(msg.Text)
+		preview := string(text)
+		if len(text) > 30 {
+			preview = string(text[:30]) + "..."
 		}
 		chats = append(chats, chatInfo{
-			ID:      fmt.Sprintf("%d", cid),
+			ID:      strconv.FormatInt(cid, 10),
 			Title:   title,
-			Type:    u.Message.Chat.Type,
-			Message: msg,
+			Type:    msg.Chat.Type,
+			Message: preview,
 		})
 	}
 
-	// Limit to 5 most recent chats
-	if len(chats) > 5 {
-		chats = chats[:5]
+	if len(chats) > req.Limit {
+		chats = chats[:req.Limit]
 	}
 
 	if chats == nil {
 		chats = []chatInfo{}
 	}
 
+	result := map[string]interface{}{
+		"chats":          chats,
+		"last_update_id": lastUpdateID,
+		"has_more":       len(tgResp.Result) >= telegramUpdatesPageSize,
+	}
+	if lastUpdateID > 0 {
+		result["next_offset"] = lastUpdateID + 1
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{"chats": chats})
+	json.NewEncoder(w).Encode(result)
 }
 
 // CheckUpdate checks GitHub for the latest release and caches the result for 1 hour.
+// has_update is set only when the latest release is a strictly greater semantic
+// version than the running one.
 var (
 	updateCache     map[string]interface{}
 	updateCacheTime time.Time
@@ -1189,31 +2857,49 @@ func (h *Handlers) CheckUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 	updateCacheMu.Unlock()
 
+	// Failures are not cached so the next page load retries.
+	fail := func(msg string) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"current":    version,
+			"commit":     buildinfo.Commit,
+			"has_update": false,
+			"error":      msg,
+		})
+	}
+
 	client := &http.Client{Timeout: 5 * time.Second}
 	resp, err := client.Get("https://api.github.com/repos/makt28/wink/releases/latest")
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{"current": version})
+		fail("github unreachable: " + err.Error())
 		return
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		fail(fmt.Sprintf("github returned HTTP %d", resp.StatusCode))
+		return
+	}
 
 	var gh struct {
 		TagName string `json:"tag_name"`
 	}
 	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&gh); err != nil || gh.TagName == "" {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{"current": version})
+		fail("github returned no release tag")
 		return
 	}
 
 	latest := strings.TrimPrefix(gh.TagName, "v")
-	hasUpdate := latest != version
-
 	result := map[string]interface{}{
 		"current":    version,
+		"commit":     buildinfo.Commit,
 		"latest":     latest,
-		"has_update": hasUpdate,
+		"has_update": false,
+	}
+	// Only a strictly newer release counts; an unparseable version never does.
+	if cmp, err := semver.Compare(latest, version); err != nil {
+		result["error"] = "compare versions: " + err.Error()
+	} else {
+		result["has_update"] = cmp > 0
 	}
 
 	updateCacheMu.Lock()
@@ -1237,35 +2923,31 @@ func (h *Handlers) ReorderGroups(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cfg := h.cfgMgr.Get()
-
-	// Validate: all IDs must exist and match exactly
-	if len(req.IDs) != len(cfg.ContactGroups) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "ID count mismatch"})
-		return
-	}
-	seen := make(map[string]bool, len(req.IDs))
-	for _, id := range req.IDs {
-		if _, ok := cfg.ContactGroups[id]; !ok {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "unknown group ID: " + id})
-			return
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		// Validate: all IDs must exist and match exactly
+		if len(req.IDs) != len(cfg.ContactGroups) {
+			return badRequest("ID count mismatch")
 		}
-		if seen[id] {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "duplicate group ID: " + id})
-			return
+		seen := make(map[string]bool, len(req.IDs))
+		for _, id := range req.IDs {
+			if _, ok := cfg.ContactGroups[id]; !ok {
+				return badRequest("unknown group ID: " + id)
+			}
+			if seen[id] {
+				return badRequest("duplicate group ID: " + id)
+			}
+			seen[id] = true
 		}
-		seen[id] = true
-	}
-
-	cfg.GroupOrder = req.IDs
 
-	if err := h.cfgMgr.Save(cfg); err != nil {
+		cfg.GroupOrder = req.IDs
+		return nil
+	})
+	if status, msg, ok := asRequestError(err, ""); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return
+	} else if err != nil {
 		slog.Error("failed to reorder groups", "error", err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -1277,56 +2959,48 @@ func (h *Handlers) ReorderGroups(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
 }
 
-// ReorderMonitors updates the display order of monitors by rearranging the slice.
+// ReorderMonitors updates the persisted display order of monitors (cfg.MonitorOrder).
 func (h *Handlers) ReorderMonitors(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		IDs []string `json:"ids"`
 	}
-	if err := json.NewDecoder(io.LimitReader(r.Body, 4096)).Decode(&req); err != nil {
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&req); err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "invalid request"})
 		return
 	}
 
-	cfg := h.cfgMgr.Get()
-
-	if len(req.IDs) != len(cfg.Monitors) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "ID count mismatch"})
-		return
-	}
-
-	// Build index for fast lookup
-	byID := make(map[string]config.Monitor, len(cfg.Monitors))
-	for _, m := range cfg.Monitors {
-		byID[m.ID] = m
-	}
-
-	reordered := make([]config.Monitor, 0, len(req.IDs))
-	seen := make(map[string]bool, len(req.IDs))
-	for _, id := range req.IDs {
-		m, ok := byID[id]
-		if !ok {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "unknown monitor ID: " + id})
-			return
+	_, err := h.cfgMgr.Update(func(cfg *config.Config) error {
+		if len(req.IDs) != len(cfg.Monitors) {
+			return badRequest("ID count mismatch")
 		}
-		if seen[id] {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "duplicate monitor ID: " + id})
-			return
+
+		exists := make(map[string]bool, len(cfg.Monitors))
+		for _, m := range cfg.Monitors {
+			exists[m.ID] = true
 		}
-		seen[id] = true
-		reordered = append(reordered, m)
-	}
 
-	cfg.Monitors = reordered
+		seen := make(map[string]bool, len(req.IDs))
+		for _, id := range req.IDs {
+			if !exists[id] {
+				return badRequest("unknown monitor ID: " + id)
+			}
+			if seen[id] {
+				return badRequest("duplicate monitor ID: " + id)
+			}
+			seen[id] = true
+		}
 
-	if err := h.cfgMgr.Save(cfg); err != nil {
+		cfg.MonitorOrder = req.IDs
+		return nil
+	})
+	if status, msg, ok := asRequestError(err, ""); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return
+	} else if err != nil {
 		slog.Error("failed to reorder monitors", "error", err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -5,20 +5,38 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/makt28/wink/internal/buildinfo"
 	"github.com/makt28/wink/internal/config"
+	"github.com/makt28/wink/internal/monitor"
+	"github.com/makt28/wink/internal/storage"
 )
 
 var startTime = time.Now()
 
-const version = "0.1.4"
+var version = buildinfo.Version
+
+// maxDumpFailures is the number of consecutive failed history dumps after
+// which the verbose health check reports the instance as unhealthy.
+const maxDumpFailures = 3
+
+// healthDumpView is the history dump status of /healthz?verbose=1. The
+// endpoint is public, so the error text, which names filesystem paths, is
+// only logged.
+type healthDumpView struct {
+	LastSuccess         int64 `json:"last_success"`
+	LastFailed          bool  `json:"last_failed"`
+	ConsecutiveFailures int   `json:"consecutive_failures"`
+}
 
 // HealthHandler serves the /healthz endpoint.
 type HealthHandler struct {
-	cfgMgr *config.Manager
+	cfgMgr    *config.Manager
+	histMgr   *storage.HistoryManager
+	scheduler *monitor.Scheduler
 }
 
-func NewHealthHandler(cfgMgr *config.Manager) *HealthHandler {
-	return &HealthHandler{cfgMgr: cfgMgr}
+func NewHealthHandler(cfgMgr *config.Manager, histMgr *storage.HistoryManager, scheduler *monitor.Scheduler) *HealthHandler {
+	return &HealthHandler{cfgMgr: cfgMgr, histMgr: histMgr, scheduler: scheduler}
 }
 
 func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -26,10 +44,38 @@ func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	resp := map[string]interface{}{
 		"status":         "ok",
 		"version":        version,
+		"commit":         buildinfo.Commit,
+		"build_date":     buildinfo.Date,
 		"uptime_seconds": int(time.Since(startTime).Seconds()),
 		"monitor_count":  len(cfg.Monitors),
 	}
+	if cfg.System.Region != "" {
+		resp["region"] = cfg.System.Region
+	}
+	// The instance is up, but started without its probe history.
+	// The reason and quarantine path are only logged: the endpoint is public.
+	if h.histMgr.LoadFailed() {
+		resp["history_load_failed"] = true
+		resp["warnings"] = []string{"history.json could not be loaded, started with empty history"}
+	}
+
+	status := http.StatusOK
+	if r.URL.Query().Get("verbose") == "1" {
+		dump := h.histMgr.DumpStatus()
+		resp["running_monitors"] = h.scheduler.RunningCount()
+		resp["stalled_monitors"] = h.scheduler.StalledCount()
+		resp["history_dump"] = healthDumpView{
+			LastSuccess:         dump.LastSuccess,
+			LastFailed:          dump.ConsecutiveFailures > 0,
+			ConsecutiveFailures: dump.ConsecutiveFailures,
+		}
+		if dump.ConsecutiveFailures >= maxDumpFailures {
+			resp["status"] = "unhealthy"
+			status = http.StatusServiceUnavailable
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(resp)
 }
@@ -0,0 +1,161 @@
+package web
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/makt28/wink/internal/config"
+	"github.com/makt28/wink/internal/storage"
+)
+
+// MetricsHandler serves /metrics in the Prometheus text format. It is off
+// unless auth.metrics_token is set, and then requires that bearer token.
+type MetricsHandler struct {
+	cfgMgr  *config.Manager
+	histMgr *storage.HistoryManager
+
+	mu    sync.Mutex
+	cache map[string]latencyHistogram // by monitor ID
+}
+
+// latencyHistogram is the latency distribution of a monitor's retained
+// successful probes, along with what it was computed from so it is only
+// recomputed when the history or the buckets change.
+type latencyHistogram struct {
+	lastCheck int64
+	first     int64 // time of the oldest retained point, which moves on trimming
+	points    int
+	bounds    []float64
+
+	counts []uint64 // cumulative, one per bound
+	count  uint64
+	sum    float64 // seconds
+}
+
+func NewMetricsHandler(cfgMgr *config.Manager, histMgr *storage.HistoryManager) *MetricsHandler {
+	return &MetricsHandler{cfgMgr: cfgMgr, histMgr: histMgr, cache: make(map[string]latencyHistogram)}
+}
+
+func (mh *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cfg := mh.cfgMgr.Get()
+	token := cfg.Auth.MetricsToken
+	if token == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="wink"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	histories := mh.histMgr.GetAll()
+	bounds := cfg.System.LatencyBuckets()
+
+	type series struct {
+		labels string
+		up     bool
+		hist   latencyHistogram
+	}
+	var all []series
+	mh.mu.Lock()
+	seen := make(map[string]bool, len(cfg.Monitors))
+	for _, m := range cfg.Monitors {
+		hist, ok := histories[m.ID]
+		if !m.IsEnabled() || !ok || len(hist.LatencyHistory) == 0 {
+			continue
+		}
+		seen[m.ID] = true
+		labels := fmt.Sprintf(`monitor_id="%s",monitor_name="%s",type="%s"`, escapeLabel(m.ID), escapeLabel(m.Name), escapeLabel(m.Type))
+		all = append(all, series{labels: labels, up: hist.IsUp, hist: mh.histogram(m.ID, hist, bounds)})
+	}
+	for id := range mh.cache {
+		if !seen[id] {
+			delete(mh.cache, id)
+		}
+	}
+	mh.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	fmt.Fprintln(bw, "# HELP wink_monitor_up Whether the monitor is up (1) or down (0).")
+	fmt.Fprintln(bw, "# TYPE wink_monitor_up gauge")
+	for _, s := range all {
+		up := 0
+		if s.up {
+			up = 1
+		}
+		fmt.Fprintf(bw, "wink_monitor_up{%s} %d\n", s.labels, up)
+	}
+
+	fmt.Fprintln(bw, "# HELP wink_probe_duration_seconds Latency of the successful probes in the monitor's retained history.")
+	fmt.Fprintln(bw, "# TYPE wink_probe_duration_seconds histogram")
+	for _, s := range all {
+		for i, b := range bounds {
+			fmt.Fprintf(bw, "wink_probe_duration_seconds_bucket{%s,le=\"%s\"} %d\n", s.labels, formatFloat(b), s.hist.counts[i])
+		}
+		fmt.Fprintf(bw, "wink_probe_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", s.labels, s.hist.count)
+		fmt.Fprintf(bw, "wink_probe_duration_seconds_sum{%s} %s\n", s.labels, formatFloat(s.hist.sum))
+		fmt.Fprintf(bw, "wink_probe_duration_seconds_count{%s} %d\n", s.labels, s.hist.count)
+	}
+}
+
+// histogram returns the monitor's latency histogram, from the cache if its
+// history hasn't changed since the last scrape. Callers hold mh.mu.
+func (mh *MetricsHandler) histogram(id string, hist storage.MonitorHistory, bounds []float64) latencyHistogram {
+	points := hist.LatencyHistory
+	cached, ok := mh.cache[id]
+	if ok && cached.lastCheck == hist.LastCheckTime && cached.first == points[0].Time &&
+		cached.points == len(points) && slices.Equal(cached.bounds, bounds) {
+		return cached
+	}
+
+	h := latencyHistogram{
+		lastCheck: hist.LastCheckTime,
+		first:     points[0].Time,
+		points:    len(points),
+		bounds:    bounds,
+		counts:    make([]uint64, len(bounds)),
+	}
+	for _, p := range points {
+		if !p.Up {
+			continue
+		}
+		ms := p.Latency
+		if p.Raw > 0 {
+			ms = p.Raw // the measured value, not the latency_cap_ms clamp
+		}
+		v := float64(ms) / 1000
+		h.count++
+		h.sum += v
+		// Buckets are cumulative; count the point in the first one it fits
+		// and accumulate below.
+		if i, _ := slices.BinarySearch(bounds, v); i < len(bounds) {
+			h.counts[i]++
+		}
+	}
+	for i := 1; i < len(h.counts); i++ {
+		h.counts[i] += h.counts[i-1]
+	}
+	mh.cache[id] = h
+	return h
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a Prometheus label value.
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
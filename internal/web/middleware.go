@@ -1,7 +1,10 @@
 package web
 
 import (
+	"context"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/makt28/wink/internal/config"
 )
@@ -21,21 +24,18 @@ func AuthMiddleware(sessions *SessionStore, cfgMgr *config.Manager) func(http.Ha
 
 			cookie, err := r.Cookie("wink_session")
 			if err != nil {
-				http.Redirect(w, r, "/login", http.StatusSeeOther)
+				seeOther(w, r, "/login")
 				return
 			}
 
 			session := sessions.Get(cookie.Value)
 			if session == nil {
 				// Expired or invalid session, clear cookie
-				http.SetCookie(w, &http.Cookie{
-					Name:     "wink_session",
-					Value:    "",
-					Path:     "/",
-					MaxAge:   -1,
-					HttpOnly: true,
-				})
-				http.Redirect(w, r, "/login", http.StatusSeeOther)
+				expired := newCookie(r, cfg.System, "wink_session", "")
+				expired.MaxAge = -1
+				expired.HttpOnly = true
+				http.SetCookie(w, expired)
+				seeOther(w, r, "/login")
 				return
 			}
 
@@ -43,3 +43,86 @@ func AuthMiddleware(sessions *SessionStore, cfgMgr *config.Manager) func(http.Ha
 		})
 	}
 }
+
+// PasswordChangeMiddleware sends logged-in users to the change-password page
+// while the admin password is the shipped default, so an instance exposed
+// before its first login can't be used with the well-known password. API
+// requests are refused instead of redirected.
+func PasswordChangeMiddleware(cfgMgr *config.Manager) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := cfgMgr.Get()
+			if !cfg.Auth.UsesDefaultPassword() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if strings.HasPrefix(r.URL.Path, "/api/") {
+				writeJSONError(w, http.StatusForbidden, "password change required")
+				return
+			}
+			seeOther(w, r, "/password")
+		})
+	}
+}
+
+type viewerKey struct{}
+
+// ShareMiddleware lets a share link token stand in for a login on the read-only
+// routes it wraps. The token arrives as ?share= and is then kept in a cookie so
+// the dashboard's API polling carries it. Requests without a token, or from a
+// logged-in user, go through AuthMiddleware; an expired or revoked token is
+// cleared and redirected to the login page.
+func ShareMiddleware(sessions *SessionStore, cfgMgr *config.Manager) func(http.Handler) http.Handler {
+	requireAuth := AuthMiddleware(sessions, cfgMgr)
+	requireChange := PasswordChangeMiddleware(cfgMgr)
+	return func(next http.Handler) http.Handler {
+		authed := requireAuth(requireChange(next))
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := r.URL.Query().Get("share")
+			fromQuery := token != ""
+			if c, err := r.Cookie("wink_share"); err == nil && !fromQuery {
+				token = c.Value
+			}
+			cfg := cfgMgr.Get()
+			if token == "" || hasSession(r, sessions, cfg) {
+				authed.ServeHTTP(w, r)
+				return
+			}
+
+			now := time.Now().Unix()
+			link := cfg.Auth.ActiveShareLink(token, now)
+			if link == nil {
+				expired := newCookie(r, cfg.System, "wink_share", "")
+				expired.MaxAge = -1
+				expired.HttpOnly = true
+				http.SetCookie(w, expired)
+				seeOther(w, r, "/login")
+				return
+			}
+			if fromQuery {
+				c := newCookie(r, cfg.System, "wink_share", token)
+				c.HttpOnly = true
+				if link.ExpiresAt > 0 {
+					c.MaxAge = int(link.ExpiresAt - now)
+				}
+				http.SetCookie(w, c)
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), viewerKey{}, true)))
+		})
+	}
+}
+
+// hasSession reports whether r is from a logged-in user, by SSO or session cookie.
+func hasSession(r *http.Request, sessions *SessionStore, cfg config.Config) bool {
+	if cfg.Auth.SSO.Enabled && r.Header.Get("Remote-User") != "" {
+		return true
+	}
+	cookie, err := r.Cookie("wink_session")
+	return err == nil && sessions.Get(cookie.Value) != nil
+}
+
+// isViewer reports whether r was let in by a share link rather than a login.
+func isViewer(r *http.Request) bool {
+	viewer, _ := r.Context().Value(viewerKey{}).(bool)
+	return viewer
+}
@@ -0,0 +1,125 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/makt28/wink/internal/config"
+	"github.com/makt28/wink/internal/notify"
+)
+
+// testAllCooldown limits how often every notifier can be tested at once.
+const testAllCooldown = 30 * time.Second
+
+// sendTestNotification sends the test event through notifier. Webhooks with
+// several URLs report the outcome of each delivery.
+func sendTestNotification(ctx context.Context, notifier notify.Notifier, sys config.SystemConfig) ([]notify.DeliveryResult, error) {
+	event := notify.AlertEvent{
+		MonitorName: "Test",
+		Type:        "up",
+		Target:      "https://example.com",
+		Reason:      "This is a test notification from Wink",
+		Timestamp:   time.Now().Unix(),
+		Timezone:    sys.Timezone,
+		Region:      sys.Region,
+	}
+	if wn, ok := notifier.(*notify.WebhookNotifier); ok && len(wn.URLs) > 1 {
+		return wn.Deliver(ctx, event)
+	}
+	return nil, notifier.Send(ctx, event)
+}
+
+// notifierLabels returns the channel name of each notifier by ID, without the
+// detail, which can be a webhook URL carrying a token.
+func notifierLabels(cfg config.Config) map[string]string {
+	labels := make(map[string]string, len(cfg.Notifiers))
+	for _, n := range flattenNotifiers(cfg) {
+		labels[n.ID] = strings.TrimSuffix(n.Label, ": "+n.Detail)
+	}
+	return labels
+}
+
+// notifierTestResult is the outcome of one notifier in a test-all run.
+type notifierTestResult struct {
+	ID      string                  `json:"id"`
+	Type    string                  `json:"type"`
+	Label   string                  `json:"label,omitempty"`
+	Remark  string                  `json:"remark,omitempty"`
+	OK      bool                    `json:"ok"`
+	Error   string                  `json:"error,omitempty"`
+	Hint    string                  `json:"hint,omitempty"`
+	Results []notify.DeliveryResult `json:"results,omitempty"` // per URL, for webhooks with several
+}
+
+// TestAllNotifiers sends the test notification through every configured
+// notifier concurrently and reports each outcome. All sends share one
+// system.notify_timeout deadline, and a run is allowed once per
+// testAllCooldown.
+func (h *Handlers) TestAllNotifiers(w http.ResponseWriter, r *http.Request) {
+	if wait := h.notifyTests.allow("all", testAllCooldown); wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		writeJSONError(w, http.StatusTooManyRequests, "tested too recently")
+		return
+	}
+
+	cfg := h.cfgMgr.Get()
+	lang := getLang(r)
+	labels := notifierLabels(cfg)
+
+	ctx, cancel := context.WithTimeout(r.Context(), cfg.System.NotifyTimeout())
+	defer cancel()
+
+	results := make([]notifierTestResult, len(cfg.Notifiers))
+	var wg sync.WaitGroup
+	for i, nc := range cfg.Notifiers {
+		res := &results[i]
+		*res = notifierTestResult{ID: nc.ID, Type: nc.Type, Label: labels[nc.ID], Remark: nc.Remark}
+		notifier := notify.BuildNotifier(nc, cfg.System)
+		if notifier == nil {
+			res.Error = "unknown notifier type"
+			continue
+		}
+		if err := notifier.Validate(); err != nil {
+			res.Error = err.Error()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			res.Results, err = sendTestNotification(ctx, notifier, cfg.System)
+			if err != nil {
+				res.Error = err.Error()
+				if key := notifierErrorHint(err); key != "" {
+					res.Hint = translate(lang, key)
+				}
+				return
+			}
+			res.OK = true
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, res := range results {
+		if !res.OK {
+			failed++
+			slog.Error("test notification failed", "notifier_id", res.ID, "error", res.Error)
+		}
+	}
+	slog.Info("tested all notifiers", "total", len(results), "failed", failed)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":        failed == 0,
+		"failed":    failed,
+		"notifiers": results,
+	})
+}
@@ -0,0 +1,74 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/makt28/wink/internal/notify"
+)
+
+// previewEventTypes are the event types a notify preview can be asked for.
+var previewEventTypes = map[string]bool{"down": true, "up": true, "anomaly": true, "slow": true, "fast": true}
+
+// notifyPreviewEntry is one of a monitor's notifier_ids in a notify preview.
+type notifyPreviewEntry struct {
+	ID     string `json:"id"`
+	Type   string `json:"type,omitempty"`
+	Label  string `json:"label,omitempty"`
+	Remark string `json:"remark,omitempty"`
+	Send   bool   `json:"send"`
+	Skip   string `json:"skip,omitempty"` // why the event would not be sent, see notify.Plan
+}
+
+// APINotifyPreview reports which notifiers an event of the monitor would be
+// sent to, applying group mutes and event filters, without sending anything.
+//
+// Query parameters (optional): event, one of down (default), up, anomaly,
+// slow or fast.
+func (h *Handlers) APINotifyPreview(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	cfg := h.cfgMgr.Get()
+
+	eventType := r.URL.Query().Get("event")
+	if eventType == "" {
+		eventType = "down"
+	}
+	if !previewEventTypes[eventType] {
+		writeJSONError(w, http.StatusBadRequest, "invalid event, expected down, up, anomaly, slow or fast")
+		return
+	}
+
+	groupID, found := "", false
+	for _, m := range cfg.Monitors {
+		if m.ID == id {
+			groupID, found = m.GroupID, true
+			break
+		}
+	}
+	if !found {
+		writeJSONError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	labels := notifierLabels(cfg)
+	entries := []notifyPreviewEntry{}
+	for _, d := range notify.Plan(cfg, id, eventType) {
+		entries = append(entries, notifyPreviewEntry{
+			ID:     d.NotifierID,
+			Type:   d.Notifier.Type,
+			Label:  labels[d.NotifierID],
+			Remark: d.Notifier.Remark,
+			Send:   d.Skip == "",
+			Skip:   d.Skip,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"monitor_id":  id,
+		"event":       eventType,
+		"group_muted": cfg.ContactGroups[groupID].Muted,
+		"notifiers":   entries,
+	})
+}
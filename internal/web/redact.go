@@ -0,0 +1,22 @@
+package web
+
+import "github.com/makt28/wink/internal/config"
+
+// redactMonitor returns a copy of m that is safe to show over the API or to
+// log: credentials in the target URL are masked and the raw TCP payload, which
+// often carries an AUTH command, is hidden. The edit form and config export
+// read the config directly and are not affected.
+func redactMonitor(m config.Monitor) config.Monitor {
+	m.Target = config.RedactTarget(m.Target)
+	if len(m.Targets) > 0 {
+		targets := make([]string, len(m.Targets))
+		for i, t := range m.Targets {
+			targets[i] = config.RedactTarget(t)
+		}
+		m.Targets = targets
+	}
+	if m.SendData != "" {
+		m.SendData = config.Redacted
+	}
+	return m
+}
@@ -1,14 +1,18 @@
 package web
 
 import (
+	"bytes"
 	"encoding/json"
 	"html/template"
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/makt28/wink/internal/buildinfo"
 	"github.com/makt28/wink/internal/config"
+	"github.com/makt28/wink/internal/monitor"
 	"github.com/makt28/wink/internal/storage"
 	webassets "github.com/makt28/wink/web"
 )
@@ -56,10 +60,11 @@ var jsI18nKeys = []string{
 	"dash.incidents", "dash.select_monitor", "dash.back",
 	"dash.edit", "dash.clone", "dash.delete", "dash.delete_confirm",
 	"dash.type", "dash.interval",
-	"dash.pause", "dash.resume", "dash.status_paused",
-	"dash.ungrouped", "dash.sort",
+	"dash.pause", "dash.resume", "dash.status_paused", "dash.status_maintenance", "dash.maintenance_until",
+	"dash.check_now", "dash.checking", "dash.check_failed",
+	"dash.ungrouped", "dash.sort", "dash.up_for", "dash.down_for",
 	"settings.test_success", "settings.test_failed",
-	"settings.no_chats_found",
+	"settings.no_chats_found", "settings.load_more_chats",
 	"groups.move_up", "groups.move_down", "groups.monitor_order",
 }
 
@@ -72,12 +77,17 @@ func buildJSI18n(lang string) map[string]string {
 	return m
 }
 
+// standalonePages are templates rendered on their own, without layout.html.
+var standalonePages = map[string]bool{"login.html": true, "password.html": true, "status.html": true, "error.html": true}
+
 // TemplateRenderer parses each page template paired with layout.html.
 type TemplateRenderer struct {
 	templates map[string]*template.Template
 }
 
-func NewTemplateRenderer() *TemplateRenderer {
+// NewTemplateRenderer parses the page templates. base is system.base_path;
+// templates build links with the "url" and "asset" functions so they carry it.
+func NewTemplateRenderer(base string) *TemplateRenderer {
 	tmplFS, err := fs.Sub(webassets.TemplatesFS, "templates")
 	if err != nil {
 		slog.Error("failed to access templates", "error", err)
@@ -88,6 +98,16 @@ func NewTemplateRenderer() *TemplateRenderer {
 		"t": func(lang, key string) string {
 			return translate(lang, key)
 		},
+		"buildSummary": buildinfo.Summary,
+		"asset": func(name string) string {
+			return base + assets.url(name)
+		},
+		"url": func(path string) string {
+			return base + path
+		},
+		"basePath": func() string {
+			return base
+		},
 		"toJSON": func(v interface{}) template.JS {
 			b, _ := json.Marshal(v)
 			return template.JS(b)
@@ -102,14 +122,23 @@ func NewTemplateRenderer() *TemplateRenderer {
 		templates[page] = tmpl
 	}
 
-	// login.html is standalone
-	templates["login.html"] = template.Must(template.New("").Funcs(funcMap).ParseFS(tmplFS, "login.html"))
+	// login.html, status.html and error.html are standalone
+	for page := range standalonePages {
+		templates[page] = template.Must(template.New("").Funcs(funcMap).ParseFS(tmplFS, page))
+	}
 
 	return &TemplateRenderer{templates: templates}
 }
 
+// Render executes the named page into a buffer and only writes it out once it
+// has rendered completely, so a failing template yields a clean 500 instead of
+// a truncated page.
 func (tr *TemplateRenderer) Render(w http.ResponseWriter, name string, data interface{}) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tr.RenderStatus(w, name, http.StatusOK, data)
+}
+
+// RenderStatus is Render with an explicit HTTP status code.
+func (tr *TemplateRenderer) RenderStatus(w http.ResponseWriter, name string, status int, data interface{}) {
 	tmpl, ok := tr.templates[name]
 	if !ok {
 		slog.Error("template not found", "template", name)
@@ -117,14 +146,46 @@ func (tr *TemplateRenderer) Render(w http.ResponseWriter, name string, data inte
 		return
 	}
 
-	execName := name
-	if name != "login.html" {
-		execName = "layout"
+	execName := "layout"
+	if standalonePages[name] {
+		execName = name
 	}
 
-	if err := tmpl.ExecuteTemplate(w, execName, data); err != nil {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, execName, data); err != nil {
 		slog.Error("template render error", "template", name, "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
+
+// wantsJSON reports whether an error response should be JSON rather than a page:
+// API paths, and clients that accept JSON but not HTML.
+func wantsJSON(r *http.Request) bool {
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		return true
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+// errorHandler answers unmatched routes with status: a JSON error for API
+// clients, or the translated error page for browsers.
+func errorHandler(tmpl *TemplateRenderer, status int, key string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lang := getLang(r)
+		if wantsJSON(r) {
+			writeJSONError(w, status, strings.ToLower(http.StatusText(status)))
+			return
+		}
+		tmpl.RenderStatus(w, "error.html", status, map[string]interface{}{
+			"Lang":    lang,
+			"Code":    status,
+			"Message": translate(lang, key),
+		})
 	}
 }
 
@@ -147,23 +208,23 @@ func getTheme(r *http.Request) string {
 }
 
 // NewRouter sets up all routes and returns the http.Handler.
-func NewRouter(cfgMgr *config.Manager, histMgr *storage.HistoryManager, stopCh <-chan struct{}) http.Handler {
+func NewRouter(cfgMgr *config.Manager, histMgr *storage.HistoryManager, scheduler *monitor.Scheduler, stopCh <-chan struct{}) http.Handler {
 	cfg := cfgMgr.Get()
 	r := chi.NewRouter()
 
-	tmpl := NewTemplateRenderer()
+	tmpl := NewTemplateRenderer(cfg.System.BasePath)
 
 	sessions := NewSessionStore(cfg.System.SessionTTL, stopCh)
 	limiter := NewLoginRateLimiter(cfg.Auth.MaxLoginAttempts, cfg.Auth.LockoutDuration, stopCh)
 
 	auth := NewAuthHandler(cfgMgr, sessions, limiter, tmpl)
-	handlers := NewHandlers(cfgMgr, histMgr, tmpl)
-	health := NewHealthHandler(cfgMgr)
+	handlers := NewHandlers(cfgMgr, histMgr, scheduler, tmpl)
+	health := NewHealthHandler(cfgMgr, histMgr, scheduler)
+	metrics := NewMetricsHandler(cfgMgr, histMgr)
+	events := NewEventsHandler(scheduler, stopCh)
 
-	staticSub, err := fs.Sub(webassets.StaticFS, "static")
-	if err != nil {
-		panic(err)
-	}
+	r.NotFound(errorHandler(tmpl, http.StatusNotFound, "error.not_found"))
+	r.MethodNotAllowed(errorHandler(tmpl, http.StatusMethodNotAllowed, "error.method_not_allowed"))
 
 	// Language switch
 	r.Get("/lang", func(w http.ResponseWriter, r *http.Request) {
@@ -171,18 +232,15 @@ func NewRouter(cfgMgr *config.Manager, histMgr *storage.HistoryManager, stopCh <
 		if lang != "zh" {
 			lang = "en"
 		}
-		http.SetCookie(w, &http.Cookie{
-			Name:     "wink_lang",
-			Value:    lang,
-			Path:     "/",
-			HttpOnly: true,
-			MaxAge:   365 * 24 * 3600,
-		})
-		ref := r.Header.Get("Referer")
-		if ref == "" {
-			ref = "/"
+		cookie := newCookie(r, cfgMgr.Get().System, "wink_lang", lang)
+		cookie.HttpOnly = true
+		cookie.MaxAge = 365 * 24 * 3600
+		http.SetCookie(w, cookie)
+		if ref := r.Header.Get("Referer"); ref != "" {
+			http.Redirect(w, r, ref, http.StatusSeeOther)
+			return
 		}
-		http.Redirect(w, r, ref, http.StatusSeeOther)
+		seeOther(w, r, "/")
 	})
 
 	// Theme switch
@@ -191,12 +249,9 @@ func NewRouter(cfgMgr *config.Manager, histMgr *storage.HistoryManager, stopCh <
 		if theme != "dark" {
 			theme = "light"
 		}
-		http.SetCookie(w, &http.Cookie{
-			Name:   "wink_theme",
-			Value:  theme,
-			Path:   "/",
-			MaxAge: 365 * 24 * 3600,
-		})
+		cookie := newCookie(r, cfgMgr.Get().System, "wink_theme", theme)
+		cookie.MaxAge = 365 * 24 * 3600
+		http.SetCookie(w, cookie)
 		w.WriteHeader(http.StatusNoContent)
 	})
 
@@ -204,44 +259,78 @@ func NewRouter(cfgMgr *config.Manager, histMgr *storage.HistoryManager, stopCh <
 	r.Get("/login", auth.LoginPage)
 	r.Post("/login", auth.Login)
 	r.Get("/healthz", health.ServeHTTP)
-	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.FS(staticSub))))
+	r.Get("/metrics", metrics.ServeHTTP) // bearer token, see auth.metrics_token
+	r.Get("/status", handlers.StatusPage)
+	r.Get("/api/status", handlers.APIStatus)
+	r.Handle("/static/*", assets.handler())
+	r.Get("/favicon.ico", Favicon)
 
-	// Protected routes
+	// Read-only routes, also open to share link holders
 	r.Group(func(r chi.Router) {
-		r.Use(AuthMiddleware(sessions, cfgMgr))
+		r.Use(ShareMiddleware(sessions, cfgMgr))
 
 		r.Get("/", handlers.Dashboard)
-		r.Get("/monitors/new", handlers.MonitorForm)
-		r.Post("/monitors", handlers.CreateMonitor)
-		r.Get("/monitors/{id}/edit", handlers.EditMonitorForm)
-		r.Get("/monitors/{id}/clone", handlers.CloneMonitorForm)
-		r.Post("/monitors/{id}", handlers.UpdateMonitor)
-		r.Post("/monitors/delete", handlers.DeleteMonitor)
-
-		// JSON API endpoints
+		r.Get("/favicon.svg", handlers.StatusFavicon)
 		r.Get("/api/monitors", handlers.APIMonitors)
 		r.Get("/api/monitors/{id}", handlers.APIMonitorDetail)
-		r.Post("/api/monitors/{id}/toggle", handlers.ToggleMonitor)
-
-		r.Get("/groups", handlers.GroupsPage)
-		r.Get("/settings", handlers.SettingsPage)
-		r.Post("/settings/system", handlers.SaveSystem)
-		r.Post("/settings/auth", handlers.SaveAuth)
-		r.Post("/settings/sso", handlers.SaveSSO)
-		r.Post("/settings/groups", handlers.CreateGroup)
-		r.Post("/settings/groups/delete", handlers.DeleteGroup)
-		r.Post("/settings/groups/rename", handlers.RenameGroup)
-		r.Post("/settings/notifiers", handlers.AddNotifierFlat)
-		r.Post("/settings/notifiers/update", handlers.UpdateNotifier)
-		r.Post("/settings/notifiers/delete", handlers.DeleteNotifierByID)
-		r.Post("/api/notifiers/{id}/test", handlers.TestNotifier)
-		r.Post("/api/telegram/get-updates", handlers.TelegramGetUpdates)
-		r.Get("/api/check-update", handlers.CheckUpdate)
-		r.Post("/api/groups/reorder", handlers.ReorderGroups)
-		r.Post("/api/monitors/reorder", handlers.ReorderMonitors)
+	})
+
+	// Protected routes
+	r.Group(func(r chi.Router) {
+		r.Use(AuthMiddleware(sessions, cfgMgr))
 
+		// Reachable while the default password must still be changed
+		r.Get("/password", auth.ChangePasswordPage)
+		r.Post("/password", auth.ChangePassword)
 		r.Post("/logout", auth.Logout)
+
+		r.Group(func(r chi.Router) {
+			r.Use(PasswordChangeMiddleware(cfgMgr))
+
+			r.Get("/monitors/new", handlers.MonitorForm)
+			r.Post("/monitors", handlers.CreateMonitor)
+			r.Get("/monitors/{id}/edit", handlers.EditMonitorForm)
+			r.Get("/monitors/{id}/clone", handlers.CloneMonitorForm)
+			r.Post("/monitors/{id}", handlers.UpdateMonitor)
+			r.Post("/monitors/delete", handlers.DeleteMonitor)
+
+			// JSON API endpoints
+			r.Get("/api/summary", handlers.APISummary)
+			r.Get("/api/config", handlers.APIConfig)
+			r.Get("/api/monitors/{id}/history.json", handlers.APIMonitorHistory)
+			r.Get("/api/monitors/{id}/timeline", handlers.APIMonitorTimeline)
+			r.Get("/api/monitors/{id}/sla", handlers.APIMonitorSLA)
+			r.Get("/api/monitors/{id}/notify-preview", handlers.APINotifyPreview)
+			r.Get("/api/sla", handlers.APISLA)
+			r.Get("/api/events", events.ServeHTTP) // probe results of monitors with probe_events
+			r.Post("/api/monitors/{id}/toggle", handlers.ToggleMonitor)
+			r.Post("/api/monitors/{id}/check", handlers.CheckMonitor)
+			r.Post("/api/monitoring/toggle", handlers.ToggleMonitoring)
+
+			r.Get("/groups", handlers.GroupsPage)
+			r.Get("/settings", handlers.SettingsPage)
+			r.Post("/settings/system", handlers.SaveSystem)
+			r.Post("/settings/auth", handlers.SaveAuth)
+			r.Post("/settings/sso", handlers.SaveSSO)
+			r.Post("/settings/share-links", handlers.CreateShareLink)
+			r.Post("/settings/share-links/delete", handlers.DeleteShareLink)
+			r.Post("/settings/import/kuma", handlers.ImportKuma)
+			r.Post("/settings/monitors/import", handlers.ImportMonitors)
+			r.Post("/settings/groups", handlers.CreateGroup)
+			r.Post("/settings/groups/delete", handlers.DeleteGroup)
+			r.Post("/settings/groups/rename", handlers.RenameGroup)
+			r.Post("/settings/groups/mute", handlers.MuteGroup)
+			r.Post("/settings/notifiers", handlers.AddNotifierFlat)
+			r.Post("/settings/notifiers/update", handlers.UpdateNotifier)
+			r.Post("/settings/notifiers/delete", handlers.DeleteNotifierByID)
+			r.Post("/api/notifiers/test-all", handlers.TestAllNotifiers)
+			r.Post("/api/notifiers/{id}/test", handlers.TestNotifier)
+			r.Post("/api/telegram/get-updates", handlers.TelegramGetUpdates)
+			r.Get("/api/check-update", handlers.CheckUpdate)
+			r.Post("/api/groups/reorder", handlers.ReorderGroups)
+			r.Post("/api/monitors/reorder", handlers.ReorderMonitors)
+		})
 	})
 
-	return r
+	return mountBasePath(cfg.System.BasePath, r)
 }
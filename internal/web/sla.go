@@ -0,0 +1,159 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/makt28/wink/internal/config"
+	"github.com/makt28/wink/internal/storage"
+)
+
+// slaReport is the uptime of one monitor over a calendar month.
+type slaReport struct {
+	MonitorID string `json:"monitor_id"`
+	Name      string `json:"name"`
+	Month     string `json:"month"`
+	// From and To bound the measured part of the month: it starts no earlier
+	// than the monitor's first recorded probe or incident and ends at now.
+	From             int64    `json:"from"`
+	To               int64    `json:"to"`
+	MonitoredSeconds int64    `json:"monitored_seconds"`
+	DowntimeSeconds  int64    `json:"downtime_seconds"`
+	UptimePercent    *float64 `json:"uptime_percent"` // nil when nothing was monitored in the month
+	Incidents        int      `json:"incidents"`      // incidents overlapping the month
+	SLATarget        float64  `json:"sla_target,omitempty"`
+	SLAMet           *bool    `json:"sla_met,omitempty"` // nil without a target or data
+}
+
+// slaMonth resolves the month query value ("YYYY-MM", default the current
+// month) to its bounds in the system timezone.
+func slaMonth(cfg config.Config, month string) (string, int64, int64, bool) {
+	loc, err := time.LoadLocation(cfg.System.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	var start time.Time
+	if month == "" {
+		now := time.Now().In(loc)
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	} else if start, err = time.ParseInLocation("2006-01", month, loc); err != nil {
+		return "", 0, 0, false
+	}
+	return start.Format("2006-01"), start.Unix(), start.AddDate(0, 1, 0).Unix(), true
+}
+
+// buildSLAReport computes the monitor's downtime in [from, to) from its
+// incidents; open incidents count as down until now.
+func buildSLAReport(m config.Monitor, hist *storage.MonitorHistory, month string, from, to int64) slaReport {
+	now := time.Now().Unix()
+	if to > now {
+		to = now
+	}
+	rep := slaReport{MonitorID: m.ID, Name: m.Name, Month: month, From: from, To: to, SLATarget: m.SLATarget}
+
+	first := int64(-1)
+	var incidents []storage.Incident
+	if hist != nil {
+		incidents = hist.Incidents
+		if len(hist.LatencyHistory) > 0 {
+			first = hist.LatencyHistory[0].Time
+		}
+	}
+	for _, inc := range incidents {
+		if first < 0 || inc.StartedAt < first {
+			first = inc.StartedAt
+		}
+	}
+	if first < 0 || first >= to {
+		rep.From = to
+		return rep
+	}
+	if first > rep.From {
+		rep.From = first
+	}
+
+	for _, inc := range incidents {
+		start, end := inc.StartedAt, now
+		if inc.ResolvedAt != nil {
+			end = *inc.ResolvedAt
+		}
+		if start >= to || end <= rep.From {
+			continue
+		}
+		rep.Incidents++
+		if start < rep.From {
+			start = rep.From
+		}
+		if end > to {
+			end = to
+		}
+		if end > start {
+			rep.DowntimeSeconds += end - start
+		}
+	}
+
+	rep.MonitoredSeconds = to - rep.From
+	if rep.MonitoredSeconds <= 0 {
+		return rep
+	}
+	if rep.DowntimeSeconds > rep.MonitoredSeconds {
+		rep.DowntimeSeconds = rep.MonitoredSeconds
+	}
+	uptime := roundUptime(float64(rep.MonitoredSeconds-rep.DowntimeSeconds) / float64(rep.MonitoredSeconds) * 100)
+	rep.UptimePercent = &uptime
+	if m.SLATarget > 0 {
+		met := uptime >= m.SLATarget
+		rep.SLAMet = &met
+	}
+	return rep
+}
+
+// APIMonitorSLA returns the monitor's uptime report for a calendar month.
+//
+// Query parameters (optional): month as YYYY-MM in system.timezone, default
+// the current month.
+func (h *Handlers) APIMonitorSLA(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	cfg := h.cfgMgr.Get()
+	month, from, to, ok := slaMonth(cfg, r.URL.Query().Get("month"))
+	if !ok {
+		writeJSONError(w, http.StatusBadRequest, "invalid month, expected YYYY-MM")
+		return
+	}
+	for _, m := range cfg.Monitors {
+		if m.ID == id {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(buildSLAReport(m, h.histMgr.GetMonitor(id), month, from, to))
+			return
+		}
+	}
+	writeJSONError(w, http.StatusNotFound, "not found")
+}
+
+// APISLA returns the monthly uptime report of every monitor, in config order.
+func (h *Handlers) APISLA(w http.ResponseWriter, r *http.Request) {
+	cfg := h.cfgMgr.Get()
+	month, from, to, ok := slaMonth(cfg, r.URL.Query().Get("month"))
+	if !ok {
+		writeJSONError(w, http.StatusBadRequest, "invalid month, expected YYYY-MM")
+		return
+	}
+	all := h.histMgr.GetAll()
+	reports := make([]slaReport, 0, len(cfg.Monitors))
+	for _, m := range cfg.Monitors {
+		var hist *storage.MonitorHistory
+		if mh, ok := all[m.ID]; ok {
+			hist = &mh
+		}
+		reports = append(reports, buildSLAReport(m, hist, month, from, to))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"month":    month,
+		"timezone": cfg.System.Timezone,
+		"monitors": reports,
+	})
+}
@@ -0,0 +1,122 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/makt28/wink/internal/config"
+	"github.com/makt28/wink/internal/storage"
+	webassets "github.com/makt28/wink/web"
+)
+
+// staticAssets serves the embedded /static files with validators and cache
+// headers. Embedded files have no modification time, so http.FileServer alone
+// sends neither Last-Modified nor ETag and browsers re-download every asset.
+type staticAssets struct {
+	fsys   fs.FS
+	hashes map[string]string // file name -> hex sha256 of its content
+}
+
+// assets is built once from the embedded files; templates use it through the
+// "asset" function.
+var assets = mustLoadStaticAssets()
+
+func mustLoadStaticAssets() *staticAssets {
+	sub, err := fs.Sub(webassets.StaticFS, "static")
+	if err != nil {
+		panic(err)
+	}
+	a := &staticAssets{fsys: sub, hashes: make(map[string]string)}
+	err = fs.WalkDir(sub, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(sub, path)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		a.hashes[path] = hex.EncodeToString(sum[:])
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// url returns the versioned URL of a static file, e.g. /static/app.js?v=1a2b3c4d5e6f.
+// The version changes with the content, so versioned URLs can be cached forever.
+func (a *staticAssets) url(name string) string {
+	if h, ok := a.hashes[name]; ok {
+		return "/static/" + name + "?v=" + h[:12]
+	}
+	return "/static/" + name
+}
+
+// handler serves the files under /static/. Requests carrying the current
+// version are immutable for a year; anything else must be revalidated, which
+// costs a 304 thanks to the ETag.
+func (a *staticAssets) handler() http.Handler {
+	files := http.FileServer(http.FS(a.fsys))
+	return http.StripPrefix("/static/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h, ok := a.hashes[r.URL.Path]; ok {
+			w.Header().Set("ETag", `"`+h[:32]+`"`)
+			if v := r.URL.Query().Get("v"); v != "" && strings.HasPrefix(h, v) {
+				w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+			} else {
+				w.Header().Set("Cache-Control", "no-cache")
+			}
+		}
+		files.ServeHTTP(w, r)
+	}))
+}
+
+// faviconSVG is a filled circle; the colour reflects overall status.
+const faviconSVG = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 32 32"><circle cx="16" cy="16" r="14" fill="%s"/></svg>`
+
+// Favicon colours: neutral for anonymous visitors, green when every enabled
+// monitor is up, red when any is down.
+const (
+	faviconNeutral = "#3b82f6"
+	faviconUp      = "#22c55e"
+	faviconDown    = "#ef4444"
+)
+
+// Favicon serves a neutral icon for /favicon.ico. It is public, so it never
+// reveals monitor status.
+func Favicon(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	fmt.Fprintf(w, faviconSVG, faviconNeutral)
+}
+
+// StatusFavicon serves an icon that is red while any enabled monitor is down
+// and green otherwise. The dashboard refreshes it along with the monitor list.
+func (h *Handlers) StatusFavicon(w http.ResponseWriter, r *http.Request) {
+	colour := faviconUp
+	if anyDown(h.cfgMgr.Get(), h.histMgr) {
+		colour = faviconDown
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "no-store")
+	fmt.Fprintf(w, faviconSVG, colour)
+}
+
+// anyDown reports whether an enabled monitor is currently down.
+func anyDown(cfg config.Config, histMgr *storage.HistoryManager) bool {
+	histories := histMgr.GetAll()
+	for _, m := range cfg.Monitors {
+		if !m.IsEnabled() {
+			continue
+		}
+		if hist, ok := histories[m.ID]; ok && !hist.IsUp {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/makt28/wink/internal/config"
+	"github.com/makt28/wink/internal/storage"
+)
+
+// statusHeartbeats is the number of heartbeats shown per monitor on the status page.
+const statusHeartbeats = 60
+
+// statusMonitor is the public view of a monitor. It carries no target, ID or
+// other configuration so nothing beyond the name leaks to anonymous visitors.
+type statusMonitor struct {
+	Name       string                 `json:"name"`
+	Status     string                 `json:"status"` // up, degraded, down, maintenance, paused or pending (as in /api/summary)
+	Uptime24h  float64                `json:"uptime_24h"`
+	Uptime30d  float64                `json:"uptime_30d"`
+	Heartbeats []storage.LatencyPoint `json:"heartbeats"`
+}
+
+// statusSection is one contact group on the status page. The trailing
+// ungrouped section has an empty GroupID.
+type statusSection struct {
+	GroupID  string          `json:"group_id"`
+	Name     string          `json:"name"`
+	Status   string          `json:"status"`
+	Monitors []statusMonitor `json:"monitors"`
+}
+
+// buildStatusSections groups public monitors by contact group in GroupOrder,
+// followed by an ungrouped section. Sections without public monitors are left out.
+func buildStatusSections(cfg config.Config, histories map[string]storage.MonitorHistory) []statusSection {
+	byGroup := make(map[string][]statusMonitor)
+	for _, m := range cfg.OrderedMonitors() {
+		if !m.Public {
+			continue
+		}
+		groupID := m.GroupID
+		if _, ok := cfg.ContactGroups[groupID]; !ok {
+			groupID = ""
+		}
+		hist, ok := histories[m.ID]
+		byGroup[groupID] = append(byGroup[groupID], newStatusMonitor(m, hist, ok))
+	}
+
+	sections := make([]statusSection, 0, len(byGroup))
+	add := func(id, name string) {
+		if monitors := byGroup[id]; len(monitors) > 0 {
+			sections = append(sections, statusSection{
+				GroupID:  id,
+				Name:     name,
+				Status:   sectionStatus(monitors),
+				Monitors: monitors,
+			})
+		}
+	}
+	for _, g := range buildOrderedGroups(cfg) {
+		add(g.ID, g.Name)
+	}
+	add("", "")
+	return sections
+}
+
+func newStatusMonitor(m config.Monitor, hist storage.MonitorHistory, hasHistory bool) statusMonitor {
+	sm := statusMonitor{Name: m.Name, Status: "pending", Heartbeats: []storage.LatencyPoint{}}
+	switch {
+	case !m.IsEnabled():
+		sm.Status = "paused"
+	case hasHistory && maintenanceUntil(hist, time.Now().Unix()) > 0:
+		// A window can start before the first heartbeat.
+		sm.Status = "maintenance"
+	}
+	if !hasHistory || len(hist.LatencyHistory) == 0 {
+		return sm
+	}
+
+	sm.Uptime24h = roundUptime(hist.Uptime24h)
+	sm.Uptime30d = roundUptime(hist.Uptime30d)
+	sm.Heartbeats = tailPoints(hist.LatencyHistory, statusHeartbeats)
+	if sm.Status == "pending" {
+		switch last := hist.LatencyHistory[len(hist.LatencyHistory)-1]; {
+		case !hist.IsUp:
+			sm.Status = "down"
+		case !last.Up:
+			sm.Status = "degraded"
+		default:
+			sm.Status = "up"
+		}
+	}
+	return sm
+}
+
+// sectionStatus aggregates monitor states: down when every active monitor is
+// down, degraded when only some are down or degraded, up otherwise. Monitors in
+// maintenance don't count unless all active ones are; sections with only paused
+// or pending monitors report pending.
+func sectionStatus(monitors []statusMonitor) string {
+	var active, down, degraded, maintenance int
+	for _, m := range monitors {
+		switch m.Status {
+		case "down":
+			down++
+		case "degraded":
+			degraded++
+		case "maintenance":
+			maintenance++
+			continue
+		case "paused", "pending":
+			continue
+		}
+		active++
+	}
+	switch {
+	case active == 0 && maintenance > 0:
+		return "maintenance"
+	case active == 0:
+		return "pending"
+	case down == active:
+		return "down"
+	case down > 0 || degraded > 0:
+		return "degraded"
+	}
+	return "up"
+}
+
+// overallStatus folds section states into one headline state.
+func overallStatus(sections []statusSection) string {
+	monitors := make([]statusMonitor, 0, len(sections))
+	for _, s := range sections {
+		monitors = append(monitors, statusMonitor{Status: s.Status})
+	}
+	return sectionStatus(monitors)
+}
+
+// StatusPage renders the public status page (no login required).
+func (h *Handlers) StatusPage(w http.ResponseWriter, r *http.Request) {
+	cfg := h.cfgMgr.Get()
+	sections := buildStatusSections(cfg, h.histMgr.GetAll())
+	data := map[string]interface{}{
+		"Lang":        getLang(r),
+		"Theme":       getTheme(r),
+		"Sections":    sections,
+		"Status":      overallStatus(sections),
+		"Refresh":     cfg.System.DashboardRefreshSeconds,
+		"GeneratedAt": time.Now().UTC().Format("2006-01-02 15:04:05 UTC"),
+	}
+	h.tmpl.Render(w, "status.html", data)
+}
+
+// APIStatus returns the public status page data as JSON (no login required).
+func (h *Handlers) APIStatus(w http.ResponseWriter, r *http.Request) {
+	sections := buildStatusSections(h.cfgMgr.Get(), h.histMgr.GetAll())
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       overallStatus(sections),
+		"sections":     sections,
+		"generated_at": time.Now().Unix(),
+	})
+}
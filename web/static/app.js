@@ -15,7 +15,9 @@
   var monitors = [];
   var listPollTimer = null;
   var detailPollTimer = null;
-  var POLL_INTERVAL = 10000;
+  var POLL_INTERVAL = window.POLL_INTERVAL || 10000; // system.dashboard_refresh
+  var BASE = window.BASE_PATH || ''; // system.base_path, prefixed to every URL below
+  var VIEWER = !!window.VIEWER; // opened through a read-only share link
   var isPageVisible = true;
   var collapsedGroups = {}; // track collapsed group IDs
   var sortMode = false;
@@ -39,7 +41,7 @@
     var current = getThemeCookie();
     var next = current === 'dark' ? 'light' : 'dark';
     // Set cookie via server to keep it consistent
-    fetch('/theme?t=' + next, { credentials: 'same-origin' }).then(function () {
+    fetch(BASE + '/theme?t=' + next, { credentials: 'same-origin' }).then(function () {
       applyTheme(next);
     });
   }
@@ -58,9 +60,12 @@
   function formatDuration(seconds) {
     if (seconds < 60) return seconds + 's';
     if (seconds < 3600) return Math.floor(seconds / 60) + 'm ' + (seconds % 60) + 's';
-    var h = Math.floor(seconds / 3600);
-    var m = Math.floor((seconds % 3600) / 60);
-    return h + 'h ' + m + 'm';
+    if (seconds < 86400) {
+      var h = Math.floor(seconds / 3600);
+      var m = Math.floor((seconds % 3600) / 60);
+      return h + 'h ' + m + 'm';
+    }
+    return Math.floor(seconds / 86400) + 'd ' + Math.floor((seconds % 86400) / 3600) + 'h';
   }
 
   function uptimeClass(val) {
@@ -112,7 +117,7 @@
     fetch(url, { credentials: 'same-origin' })
       .then(function (res) {
         if (res.status === 401) {
-          window.location.href = '/login';
+          window.location.href = BASE + '/login';
           return;
         }
         return res.json();
@@ -121,6 +126,22 @@
       .catch(function (err) { cb(err, null); });
   }
 
+  // --- Favicon ---
+  // The icon turns red while any enabled monitor is down (outside maintenance);
+  // it is only reloaded when that changes.
+  var faviconDown = null;
+  function updateFavicon(list) {
+    var link = document.getElementById('favicon');
+    if (!link) return;
+    var down = false;
+    for (var i = 0; i < list.length; i++) {
+      if (list[i].enabled && list[i].has_history && !list[i].is_up && !list[i].maintenance_until) { down = true; break; }
+    }
+    if (down === faviconDown) return;
+    faviconDown = down;
+    link.href = BASE + '/favicon.svg?s=' + (down ? 'down' : 'up');
+  }
+
   // --- Monitor List ---
   function refreshList() {
     var listContainer = document.getElementById('monitor-list');
@@ -129,11 +150,12 @@
     // Calculate points based on list container width
     var barCount = calcBarCount(listContainer);
 
-    fetchJSON('/api/monitors?points=' + barCount, function (err, data) {
+    fetchJSON(BASE + '/api/monitors?points=' + barCount, function (err, data) {
       if (err || !data) return;
 
       monitors = data.monitors || [];
       lastGroupOrder = data.group_order || [];
+      updateFavicon(monitors);
 
       listContainer.innerHTML = '';
 
@@ -142,7 +164,7 @@
         var empty = document.createElement('div');
         empty.className = 'flex flex-col items-center justify-center py-16 text-gray-400';
         empty.innerHTML = '<p class="text-lg mb-2">' + t('dash.no_monitors') + '</p>' +
-          '<a href="/monitors/new" class="text-blue-500 hover:text-blue-400">' + t('dash.add_first') + '</a>';
+          (VIEWER ? '' : '<a href="' + BASE + '/monitors/new" class="text-blue-500 hover:text-blue-400">' + t('dash.add_first') + '</a>');
         listContainer.appendChild(empty);
         return;
       }
@@ -163,7 +185,7 @@
         refreshList();
       });
       sortBar.appendChild(sortBtn);
-      listContainer.appendChild(sortBar);
+      if (!VIEWER) listContainer.appendChild(sortBar);
 
       var frag = document.createDocumentFragment();
 
@@ -270,6 +292,8 @@
     if (!m.enabled) {
       dotColor = 'bg-gray-400';
       dotClass = '';
+    } else if (m.maintenance_until) {
+      dotColor = 'bg-blue-500';
     } else if (m.has_history) {
       dotColor = m.is_up ? 'bg-green-500' : 'bg-red-500';
       dotClass = m.is_up ? '' : ' status-dot--down';
@@ -294,6 +318,7 @@
         '<span class="text-xs text-gray-400 dark:text-gray-500 flex-shrink-0">' + m.type.toUpperCase() + '</span>' +
         (sortMode && m.group_name ? '<span class="text-xs px-1.5 py-0.5 rounded bg-blue-100 dark:bg-blue-900/40 text-blue-600 dark:text-blue-400 flex-shrink-0">' + escapeHtml(m.group_name) + '</span>' : '') +
         (!m.enabled ? '<span class="text-xs px-1.5 py-0.5 rounded bg-gray-200 dark:bg-gray-700 text-gray-500 dark:text-gray-400 flex-shrink-0">' + t('dash.status_paused') + '</span>' : '') +
+        (m.enabled && m.maintenance_until ? '<span class="text-xs px-1.5 py-0.5 rounded bg-blue-100 dark:bg-blue-900/40 text-blue-600 dark:text-blue-400 flex-shrink-0" title="' + t('dash.maintenance_until') + ' ' + new Date(m.maintenance_until * 1000).toLocaleString() + '">' + t('dash.status_maintenance') + '</span>' : '') +
       '</div>' +
       '<div class="flex items-center gap-3 text-xs flex-shrink-0">';
 
@@ -347,7 +372,7 @@
     var tmp = ids[idx];
     ids[idx] = ids[newIdx];
     ids[newIdx] = tmp;
-    fetch('/api/monitors/reorder', {
+    fetch(BASE + '/api/monitors/reorder', {
       method: 'POST',
       headers: {'Content-Type': 'application/json'},
       body: JSON.stringify({ids: ids}),
@@ -366,7 +391,7 @@
     var tmp = ids[idx];
     ids[idx] = ids[newIdx];
     ids[newIdx] = tmp;
-    fetch('/api/groups/reorder', {
+    fetch(BASE + '/api/groups/reorder', {
       method: 'POST',
       headers: {'Content-Type': 'application/json'},
       body: JSON.stringify({ids: ids}),
@@ -416,7 +441,7 @@
     var detailHeartbeat = document.getElementById('detail-heartbeat');
     var barCount = detailHeartbeat ? calcBarCount(detailHeartbeat) : 60;
 
-    fetchJSON('/api/monitors/' + selectedMonitorId + '?points=' + barCount, function (err, data) {
+    fetchJSON(BASE + '/api/monitors/' + selectedMonitorId + '?points=' + barCount, function (err, data) {
       if (err || !data) return;
 
       // Status dot
@@ -424,6 +449,8 @@
       dotEl.className = 'w-3 h-3 rounded-full';
       if (!data.enabled) {
         dotEl.classList.add('bg-gray-400');
+      } else if (data.maintenance_until) {
+        dotEl.classList.add('bg-blue-500');
       } else if (data.has_history) {
         dotEl.classList.add(data.is_up ? 'bg-green-500' : 'bg-red-500');
         if (!data.is_up) dotEl.classList.add('status-dot--down');
@@ -439,7 +466,7 @@
       var toggleBtn = document.getElementById('detail-toggle');
       toggleBtn.textContent = data.enabled ? t('dash.pause') : t('dash.resume');
       toggleBtn.onclick = function () {
-        fetch('/api/monitors/' + data.id + '/toggle', { method: 'POST', credentials: 'same-origin' })
+        fetch(BASE + '/api/monitors/' + data.id + '/toggle', { method: 'POST', credentials: 'same-origin' })
           .then(function (res) { return res.json(); })
           .then(function () {
             refreshList();
@@ -447,9 +474,28 @@
           });
       };
 
+      // Check now (disabled while paused)
+      var checkBtn = document.getElementById('detail-check');
+      checkBtn.disabled = !data.enabled;
+      checkBtn.onclick = function () {
+        checkBtn.disabled = true;
+        checkBtn.textContent = t('dash.checking');
+        fetch(BASE + '/api/monitors/' + data.id + '/check', { method: 'POST', credentials: 'same-origin' })
+          .then(function (res) { return res.json(); })
+          .then(function (res) {
+            if (res.error && res.up === undefined) alert(t('dash.check_failed') + ': ' + res.error);
+            refreshList();
+            refreshDetail();
+          })
+          .finally(function () {
+            checkBtn.disabled = false;
+            checkBtn.textContent = t('dash.check_now');
+          });
+      };
+
       // Edit, clone & delete
-      document.getElementById('detail-edit').href = '/monitors/' + data.id + '/edit';
-      document.getElementById('detail-clone').href = '/monitors/' + data.id + '/clone';
+      document.getElementById('detail-edit').href = BASE + '/monitors/' + data.id + '/edit';
+      document.getElementById('detail-clone').href = BASE + '/monitors/' + data.id + '/clone';
       document.getElementById('detail-delete-id').value = data.id;
       document.getElementById('detail-delete-form').onsubmit = function () {
         return confirm(t('dash.delete_confirm'));
@@ -467,6 +513,30 @@
       // Last check
       document.getElementById('detail-last-check').textContent = timeAgo(data.last_check);
 
+      // Last seen up, shown while down
+      var lastUp = document.getElementById('detail-last-up');
+      if (!data.is_up && data.last_up && data.enabled) {
+        lastUp.textContent = timeAgo(data.last_up);
+        lastUp.parentElement.classList.remove('hidden');
+      } else {
+        lastUp.parentElement.classList.add('hidden');
+      }
+
+      // Current state duration ("Up for 3d 4h")
+      var stateSince = document.getElementById('detail-state-since');
+      if (data.maintenance_until && data.enabled) {
+        document.getElementById('label-state-since').textContent = t('dash.maintenance_until');
+        stateSince.textContent = new Date(data.maintenance_until * 1000).toLocaleString();
+        stateSince.parentElement.classList.remove('hidden');
+      } else if (data.state_since && data.enabled) {
+        var held = Math.max(0, Math.floor(Date.now() / 1000) - data.state_since);
+        document.getElementById('label-state-since').textContent = t(data.is_up ? 'dash.up_for' : 'dash.down_for');
+        stateSince.textContent = formatDuration(held);
+        stateSince.parentElement.classList.remove('hidden');
+      } else {
+        stateSince.parentElement.classList.add('hidden');
+      }
+
       // Type & interval
       document.getElementById('detail-type').textContent = data.type.toUpperCase();
       document.getElementById('detail-interval').textContent = data.interval + 's';
@@ -512,7 +582,11 @@
       html += '<span class="text-xs">' + new Date(inc.started_at * 1000).toLocaleString() + '</span>';
       html += '</div>';
       if (inc.reason) {
-        html += '<div class="text-xs mt-1 opacity-75">' + escapeHtml(inc.reason) + '</div>';
+        html += '<div class="text-xs mt-1 opacity-75">';
+        if (inc.category) {
+          html += '<span class="inline-block px-1.5 mr-1 rounded bg-gray-200 dark:bg-gray-700 font-mono">' + escapeHtml(inc.category) + '</span>';
+        }
+        html += escapeHtml(inc.reason) + '</div>';
       }
       if (!isOpen && inc.duration) {
         html += '<div class="text-xs mt-1">' + t('dash.duration') + ' ' + formatDuration(inc.duration) + '</div>';
@@ -595,6 +669,15 @@
       themeBtn.addEventListener('click', toggleTheme);
     }
 
+    // Global monitoring pause/resume (dashboard banner, settings)
+    document.querySelectorAll('.monitoring-toggle').forEach(function (btn) {
+      btn.addEventListener('click', function () {
+        btn.disabled = true;
+        fetch(BASE + '/api/monitoring/toggle', { method: 'POST', credentials: 'same-origin' })
+          .then(function () { window.location.reload(); });
+      });
+    });
+
     // Back button (mobile detail -> list)
     var backBtn = document.getElementById('detail-back');
     if (backBtn) {